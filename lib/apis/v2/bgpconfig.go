@@ -46,6 +46,48 @@ type BGPConfigurationSpec struct {
 	NodeToNodeMeshEnabled *bool `json:"nodeToNodeMeshEnabled,omitempty" validate:"omitempty" confignamev1:"node_mesh"`
 	// ASNumber is the default AS number used by a node. [Default: 64512]
 	ASNumber *numorstring.ASNumber `json:"asNumber,omitempty" validate:"omitempty" confignamev1:"as_num"`
+	// ServiceClusterIPs are the CIDR blocks from which Kubernetes Service cluster IPs are
+	// allocated.  If specified, these are advertised over BGP.  Only valid on the global
+	// default BGPConfiguration.
+	ServiceClusterIPs []ServiceClusterIPBlock `json:"serviceClusterIPs,omitempty" validate:"omitempty,dive" confignamev1:"svc_cluster_ips"`
+	// ServiceExternalIPs are the CIDR blocks for Kubernetes Service external IPs.  If
+	// specified, these are advertised over BGP.  Only valid on the global default
+	// BGPConfiguration.
+	ServiceExternalIPs []ServiceExternalIPBlock `json:"serviceExternalIPs,omitempty" validate:"omitempty,dive" confignamev1:"svc_external_ips"`
+	// Communities is a list of named BGP communities that may be referenced by name in the
+	// PrefixAdvertisements.
+	Communities []Community `json:"communities,omitempty" validate:"omitempty,dive" confignamev1:"communities"`
+	// PrefixAdvertisements contains the communities to attach to the advertised pod, IP pool
+	// and Service prefixes that are within each CIDR.
+	PrefixAdvertisements []PrefixAdvertisement `json:"prefixAdvertisements,omitempty" validate:"omitempty,dive" confignamev1:"prefix_advertisements"`
+}
+
+// Community contains a named BGP community value.
+type Community struct {
+	// The name of the community.
+	Name string `json:"name,omitempty" validate:"required,name"`
+	// The value of the community, either a standard community in the format "aa:nn" or a
+	// large community in the format "aa:nn:mm".
+	Value string `json:"value,omitempty" validate:"required,bgpcommunity"`
+}
+
+// PrefixAdvertisement contains the communities to attach to the routes within a CIDR.
+type PrefixAdvertisement struct {
+	// The CIDR of the routes to attach the communities to.
+	CIDR string `json:"cidr,omitempty" validate:"required,cidr"`
+	// The communities to attach.  Each entry is either the name of a community in
+	// Communities, or a standard or large community value.
+	Communities []string `json:"communities,omitempty" validate:"required"`
+}
+
+// ServiceClusterIPBlock represents a single allowed ClusterIP CIDR block.
+type ServiceClusterIPBlock struct {
+	CIDR string `json:"cidr,omitempty" validate:"required,cidr"`
+}
+
+// ServiceExternalIPBlock represents a single allowed External IP CIDR block.
+type ServiceExternalIPBlock struct {
+	CIDR string `json:"cidr,omitempty" validate:"required,cidr"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindBGPFilter     = "BGPFilter"
+	KindBGPFilterList = "BGPFilterList"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BGPFilter contains the import and export rules for the routes exchanged with the BGP
+// peers that reference the filter.
+type BGPFilter struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the BGPFilter.
+	Spec BGPFilterSpec `json:"spec,omitempty"`
+}
+
+// BGPFilterSpec contains the specification for a BGPFilter resource.  The rules are
+// evaluated in order, and the action of the first matching rule is applied.  Routes that
+// do not match any rule are handled as if the filter was not referenced.
+type BGPFilterSpec struct {
+	// The ordered set of rules applied to the routes advertised to the peer.
+	Export []BGPFilterRule `json:"export,omitempty" validate:"omitempty,dive"`
+	// The ordered set of rules applied to the routes received from the peer.
+	Import []BGPFilterRule `json:"import,omitempty" validate:"omitempty,dive"`
+}
+
+// BGPFilterRule defines a single BGP filter rule.
+type BGPFilterRule struct {
+	// The CIDR to match the route prefix against.
+	CIDR string `json:"cidr" validate:"required,cidr"`
+	// The match operator, one of Equal, NotEqual, In or NotIn.  In matches routes whose
+	// prefix is equal to or contained in the CIDR.
+	MatchOperator BGPFilterMatchOperator `json:"matchOperator" validate:"bgpfiltermatchoperator"`
+	// The action to take on matching routes, one of Accept or Reject.
+	Action BGPFilterAction `json:"action" validate:"bgpfilteraction"`
+}
+
+type BGPFilterMatchOperator string
+
+const (
+	BGPFilterMatchOperatorEqual    BGPFilterMatchOperator = "Equal"
+	BGPFilterMatchOperatorNotEqual BGPFilterMatchOperator = "NotEqual"
+	BGPFilterMatchOperatorIn       BGPFilterMatchOperator = "In"
+	BGPFilterMatchOperatorNotIn    BGPFilterMatchOperator = "NotIn"
+)
+
+type BGPFilterAction string
+
+const (
+	BGPFilterActionAccept BGPFilterAction = "Accept"
+	BGPFilterActionReject BGPFilterAction = "Reject"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BGPFilterList contains a list of BGPFilter resources.
+type BGPFilterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []BGPFilter `json:"items"`
+}
+
+// NewBGPFilter creates a new (zeroed) BGPFilter struct with the TypeMetadata initialised to the current
+// version.
+func NewBGPFilter() *BGPFilter {
+	return &BGPFilter{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindBGPFilter,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// NewBGPFilterList creates a new (zeroed) BGPFilterList struct with the TypeMetadata initialised to the current
+// version.
+func NewBGPFilterList() *BGPFilterList {
+	return &BGPFilterList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindBGPFilterList,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
@@ -41,13 +41,42 @@ type BGPPeer struct {
 // BGPPeerSpec contains the specification for a BGPPeer resource.
 type BGPPeerSpec struct {
 	// The node name identifying the Calico node instance that is peering with this peer.
-	// If this is not set, this represents a global peer, i.e. a peer that peers with
-	// every node in the deployment.
+	// If this is not set, and NodeSelector is not set, this represents a global peer,
+	// i.e. a peer that peers with every node in the deployment.
 	Node string `json:"node,omitempty" validate:"omitempty,name"`
-	// The IP address of the peer.
-	PeerIP string `json:"peerIP" validate:"omitempty,ip"`
-	// The AS Number of the peer.
-	ASNumber numorstring.ASNumber `json:"asNumber"`
+	// Selector for the nodes that should have this peering.  This cannot be specified
+	// with Node.
+	NodeSelector string `json:"nodeSelector,omitempty" validate:"omitempty,selector"`
+	// The IP address of the peer.  This must be specified unless PeerSelector is set.
+	PeerIP string `json:"peerIP,omitempty" validate:"omitempty,ip"`
+	// The AS Number of the peer.  This must not be specified when PeerSelector is set.
+	ASNumber numorstring.ASNumber `json:"asNumber,omitempty"`
+	// Selector for the remote Calico nodes that the selected nodes should peer with.  The
+	// peerings use the BGP addresses and AS numbers of the selected nodes.  This cannot be
+	// specified with PeerIP or ASNumber.
+	PeerSelector string `json:"peerSelector,omitempty" validate:"omitempty,selector"`
+	// Optional BGP password for the peerings generated by this BGPPeer resource.  The
+	// password is not stored in the BGPPeer, instead it references a secret.
+	Password *BGPPassword `json:"password,omitempty" validate:"omitempty"`
+	// The names of the BGPFilter resources applied to the routes exchanged with this peer.
+	// The filters are applied in order.
+	Filters []string `json:"filters,omitempty" validate:"omitempty,dive,name"`
+}
+
+// BGPPassword contains ways to specify a BGP password.
+type BGPPassword struct {
+	// Selects a key of a secret.  On the Kubernetes datastore this is a key of a Secret in
+	// the Calico namespace, and the Secret must have the projectcalico.org/bgp-password
+	// label.  On etcdv3 this is a key of a secret stored under the restricted secrets prefix.
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty" validate:"required"`
+}
+
+// SecretKeySelector selects a key of a secret.
+type SecretKeySelector struct {
+	// The name of the secret.
+	Name string `json:"name" validate:"name"`
+	// The key of the secret to select from.
+	Key string `json:"key" validate:"required"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
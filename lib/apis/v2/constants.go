@@ -36,6 +36,14 @@ const (
 	// orchestrator.
 	LabelOrchestrator = "projectcalico.org/orchestrator"
 
+	// Label used to denote the ServiceAccount.  This is added to the workload endpoints by
+	// Calico and may be used for service account matches in policy rules.
+	LabelServiceAccount = "projectcalico.org/serviceaccount"
+
+	// Label that must be present on a Kubernetes Secret for it to be referenced by the
+	// password of a BGPPeer.  The value of the label is not used.
+	LabelBGPPassword = "projectcalico.org/bgp-password"
+
 	// Known orchestrators.  Orchestrators are not limited to this list.
 	OrchestratorKubernetes = "k8s"
 	OrchestratorCNI        = "cni"
@@ -53,8 +53,12 @@ type GlobalNetworkPolicy struct {
 }
 
 type GlobalNetworkPolicySpec struct {
-	// Order is an optional field that specifies the order in which the policy is applied.
-	// Policies with higher "order" are applied after those with lower
+	// Tier is the name of the tier that this policy belongs to.  If this is omitted, the
+	// policy is placed in the default tier.  The tier must exist before the policy is
+	// created.
+	Tier string `json:"tier,omitempty" validate:"omitempty,name"`
+	// Order is an optional field that specifies the order in which the policy is applied
+	// within its tier.  Policies with higher "order" are applied after those with lower
 	// order.  If the order is omitted, it may be considered to be "infinite" - i.e. the
 	// policy will be applied last.  Policies with identical order will be applied in
 	// alphanumerical order based on the Policy "Name".
@@ -116,6 +120,11 @@ type GlobalNetworkPolicySpec struct {
 	PreDNAT bool `json:"preDNAT,omitempty"`
 	// ApplyOnForward indicates to apply the rules in this policy on forward traffic.
 	ApplyOnForward bool `json:"applyOnForward,omitempty"`
+
+	// ExpiresAt is an optional time after which the policy is no longer applied.  An expired
+	// policy is deleted by the policy expiry sweeper, which must be running for the expiry to
+	// take effect.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
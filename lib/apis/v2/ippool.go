@@ -40,13 +40,43 @@ type IPPoolSpec struct {
 	// Contains configuration for IPIP tunneling for this pool. If not specified,
 	// then this is defaulted to "Never" (i.e. IPIP tunelling is disabled).
 	IPIPMode IPIPMode `json:"ipipMode,omitempty" validate:"omitempty,ipipmode"`
+	// Contains configuration for VXLAN tunneling for this pool. If not specified,
+	// then this is defaulted to "Never" (i.e. VXLAN tunelling is disabled).  VXLAN
+	// and IPIP tunneling cannot both be enabled on the same pool.
+	VXLANMode VXLANMode `json:"vxlanMode,omitempty" validate:"omitempty,vxlanmode"`
 	// When nat-outgoing is true, packets sent from Calico networked containers in
 	// this pool to destinations outside of this pool will be masqueraded.
 	NATOutgoing bool `json:"natOutgoing,omitempty"`
 	// When disabled is true, Calico IPAM will not assign addresses from this pool.
 	Disabled bool `json:"disabled,omitempty"`
+	// The prefix length of the blocks that Calico IPAM allocates from this pool.  Must be
+	// between 20 and 32 for IPv4 and between 116 and 128 for IPv6, and no smaller than the
+	// pool prefix length.  This cannot be modified once the pool is created.  If not
+	// specified, defaults to 26 for IPv4 and 122 for IPv6.
+	BlockSize int `json:"blockSize,omitempty"`
+	// Selects the nodes that Calico IPAM should assign addresses from this pool to, using
+	// the node labels.  If not specified, the pool may be used by all nodes.  This does not
+	// apply when the pools are explicitly requested in the IPAM assignment request.
+	NodeSelector string `json:"nodeSelector,omitempty" validate:"omitempty,selector"`
+	// The strategy that Calico IPAM uses to choose the blocks and addresses to assign from
+	// this pool.  If not specified, defaults to "RandomBlock".
+	AllocationStrategy AllocationStrategy `json:"allocationStrategy,omitempty" validate:"omitempty,allocationstrategy"`
 }
 
+type AllocationStrategy string
+
+const (
+	// Blocks are chosen starting from a random block, based on the host name, and the least
+	// recently released free address in the block is assigned.
+	AllocationStrategyRandomBlock AllocationStrategy = "RandomBlock"
+	// Blocks are chosen in address order starting from the beginning of the pool, and
+	// addresses are assigned from the lowest free address in the block.
+	AllocationStrategySequentialBlock AllocationStrategy = "SequentialBlock"
+	// Blocks and addresses are chosen as for RandomBlock, so released addresses are not
+	// quickly reused.
+	AllocationStrategyLRUAddress AllocationStrategy = "LRUAddress"
+)
+
 type IPIPMode string
 
 const (
@@ -56,6 +86,14 @@ const (
 )
 const DefaultMode = IPIPModeAlways
 
+type VXLANMode string
+
+const (
+	VXLANModeNever       VXLANMode = "Never"
+	VXLANModeAlways                = "Always"
+	VXLANModeCrossSubnet           = "CrossSubnet"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // IPPoolList contains a list of IPPool resources.
@@ -34,8 +34,12 @@ type NetworkPolicy struct {
 }
 
 type NetworkPolicySpec struct {
-	// Order is an optional field that specifies the order in which the policy is applied.
-	// Policies with higher "order" are applied after those with lower
+	// Tier is the name of the tier that this policy belongs to.  If this is omitted, the
+	// policy is placed in the default tier.  The tier must exist before the policy is
+	// created.
+	Tier string `json:"tier,omitempty" validate:"omitempty,name"`
+	// Order is an optional field that specifies the order in which the policy is applied
+	// within its tier.  Policies with higher "order" are applied after those with lower
 	// order.  If the order is omitted, it may be considered to be "infinite" - i.e. the
 	// policy will be applied last.  Policies with identical order will be applied in
 	// alphanumerical order based on the Policy "Name".
@@ -87,6 +91,11 @@ type NetworkPolicySpec struct {
 	// When the policy is read back again, Types will always be one of these values, never empty
 	// or nil.
 	Types []PolicyType `json:"types,omitempty" validate:"omitempty,dive,policytype"`
+
+	// ExpiresAt is an optional time after which the policy is no longer applied.  An expired
+	// policy is deleted by the policy expiry sweeper, which must be running for the expiry to
+	// take effect.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -44,6 +44,10 @@ type NodeSpec struct {
 
 	// OrchRefs for this node.
 	OrchRefs []OrchRef `json:"orchRefs,omitempty" validate:"omitempty"`
+
+	// IPv4VXLANTunnelAddr is the IPv4 address of the VXLAN tunnel.  This is configured
+	// independently of the BGP configuration since VXLAN does not require BGP.
+	IPv4VXLANTunnelAddr string `json:"ipv4VXLANTunnelAddr,omitempty" validate:"omitempty,ipv4"`
 }
 
 // OrchRef is used to correlate a Calico node to its corresponding representation in a given orchestrator
@@ -67,6 +71,10 @@ type NodeBGPSpec struct {
 	IPv6Address string `json:"ipv6Address,omitempty" validate:"omitempty,ipv6"`
 	// IPv4IPIPTunnelAddr is the IPv4 address of the IP in IP tunnel.
 	IPv4IPIPTunnelAddr string `json:"ipv4IPIPTunnelAddr,omitempty" validate:"omitempty,ipv4"`
+	// RouteReflectorClusterID is the cluster ID of the route reflector cluster that this
+	// node is a route reflector for.  If this is not specified the node is not a route
+	// reflector.
+	RouteReflectorClusterID string `json:"routeReflectorClusterID,omitempty" validate:"omitempty,ipv4"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
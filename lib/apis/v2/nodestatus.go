@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindNodeStatus     = "NodeStatus"
+	KindNodeStatusList = "NodeStatusList"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeStatus contains the status of the Calico agents running on a node, as reported by
+// the agents.  The name of the NodeStatus is the name of the node.
+//
+// The agents write the NodeStatus with a TTL so that the status expires if the agents stop
+// reporting.  The client records the expiry time in the ExpiresAt field, so that readers can
+// ignore an expired status on datastores that do not remove it automatically.
+type NodeStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the NodeStatus.
+	Spec NodeStatusSpec `json:"spec,omitempty"`
+}
+
+// NodeStatusSpec contains the reported status of the node.
+type NodeStatusSpec struct {
+	// The length of time that the agent has been running.
+	Uptime metav1.Duration `json:"uptime,omitempty"`
+	// The status of the agent's sync with the datastore.
+	DatastoreSyncStatus DatastoreSyncStatus `json:"datastoreSyncStatus,omitempty" validate:"omitempty,datastoresyncstatus"`
+	// The state of each of the node's BGP sessions.
+	BGPPeers []BGPPeerStatus `json:"bgpPeers,omitempty" validate:"omitempty,dive"`
+	// The time that policy was last programmed on the node.
+	LastPolicyProgrammingTime *metav1.Time `json:"lastPolicyProgrammingTime,omitempty"`
+	// The time after which the reported status is no longer valid.  This is set by the client
+	// from the TTL supplied when the status is written.  Expired statuses are not returned by
+	// the client.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+type DatastoreSyncStatus string
+
+const (
+	DatastoreSyncStatusWaitForDatastore DatastoreSyncStatus = "WaitForDatastore"
+	DatastoreSyncStatusResyncInProgress DatastoreSyncStatus = "ResyncInProgress"
+	DatastoreSyncStatusInSync           DatastoreSyncStatus = "InSync"
+)
+
+// BGPPeerStatus contains the state of a single BGP session.
+type BGPPeerStatus struct {
+	// The IP address of the peer.
+	PeerIP string `json:"peerIP" validate:"ip"`
+	// The state of the BGP session.
+	State BGPSessionState `json:"state" validate:"bgpsessionstate"`
+	// The time of the last change in the state of the BGP session.
+	Since *metav1.Time `json:"since,omitempty"`
+}
+
+type BGPSessionState string
+
+const (
+	BGPSessionStateIdle        BGPSessionState = "Idle"
+	BGPSessionStateConnect     BGPSessionState = "Connect"
+	BGPSessionStateActive      BGPSessionState = "Active"
+	BGPSessionStateOpenSent    BGPSessionState = "OpenSent"
+	BGPSessionStateOpenConfirm BGPSessionState = "OpenConfirm"
+	BGPSessionStateEstablished BGPSessionState = "Established"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeStatusList contains a list of NodeStatus resources.
+type NodeStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []NodeStatus `json:"items"`
+}
+
+// NewNodeStatus creates a new (zeroed) NodeStatus struct with the TypeMetadata initialised to the current
+// version.
+func NewNodeStatus() *NodeStatus {
+	return &NodeStatus{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindNodeStatus,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// NewNodeStatusList creates a new (zeroed) NodeStatusList struct with the TypeMetadata initialised to the current
+// version.
+func NewNodeStatusList() *NodeStatusList {
+	return &NodeStatusList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindNodeStatusList,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
@@ -15,6 +15,8 @@
 package v2
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
 )
 
@@ -57,6 +59,73 @@ type Rule struct {
 	Source EntityRule `json:"source,omitempty" validate:"omitempty"`
 	// Destination contains the match criteria that apply to destination entity.
 	Destination EntityRule `json:"destination,omitempty" validate:"omitempty"`
+
+	// HTTP contains match criteria that apply to HTTP requests.  These can only be enforced
+	// by a dataplane that is able to inspect L7 traffic (for example, a sidecar proxy), and
+	// require the Protocol, if specified, to be TCP.
+	HTTP *HTTPMatch `json:"http,omitempty" validate:"omitempty"`
+
+	// LogOptions contains options that control how packets matching this rule are logged.
+	// This may only be specified if the Action is Log.
+	LogOptions *LogOptions `json:"logOptions,omitempty" validate:"omitempty"`
+
+	// ExpiresAt is an optional time after which the rule is no longer applied.  Expired rules
+	// are removed from their policy by the policy expiry sweeper, which must be running for the
+	// expiry to take effect.  The policy Types are not changed, so when the last rule in a
+	// direction expires the policy denies all traffic in that direction.  This is only supported
+	// for rules in a GlobalNetworkPolicy or NetworkPolicy.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// LogOptions contains the options for a rule with the Log action.
+type LogOptions struct {
+	// Prefix is an optional prefix added to each logged packet, allowing the logs for a
+	// particular rule to be identified.  At most 29 characters.
+	Prefix string `json:"prefix,omitempty" validate:"omitempty,logprefix"`
+	// RateLimit is an optional limit on the rate at which packets are logged.  Packets in
+	// excess of the limit are not logged, but are otherwise processed as normal.
+	RateLimit *LogRateLimit `json:"rateLimit,omitempty" validate:"omitempty"`
+	// Burst is the maximum number of packets that may be logged in excess of the RateLimit
+	// before the limit is enforced.  This may only be specified if RateLimit is specified.
+	Burst *uint32 `json:"burst,omitempty" validate:"omitempty,gt=0"`
+}
+
+// LogRateLimit is a rate limit for logged packets, expressed as a number of packets per unit
+// of time.
+type LogRateLimit struct {
+	// Rate is the number of packets that may be logged per Unit of time.
+	Rate uint32 `json:"rate" validate:"gt=0"`
+	// Unit is the unit of time of the Rate.  One of "Second" or "Minute".
+	Unit LogRateLimitUnit `json:"unit" validate:"lograteunit"`
+}
+
+type LogRateLimitUnit string
+
+const (
+	LogRateLimitUnitSecond LogRateLimitUnit = "Second"
+	LogRateLimitUnitMinute LogRateLimitUnit = "Minute"
+)
+
+// HTTPMatch is an optional field that apply only to HTTP requests.
+// The Methods and Path fields are joined with AND.
+type HTTPMatch struct {
+	// Methods is an optional field that restricts the rule to apply only to HTTP requests that use
+	// one of the listed HTTP Methods (e.g. GET, PUT, etc.).  Multiple methods are OR'd together.
+	Methods []string `json:"methods,omitempty" validate:"omitempty,dive,httpmethod"`
+	// Paths is an optional field that restricts the rule to apply to HTTP requests that use one of
+	// the listed HTTP Paths.  Multiple paths are OR'd together.
+	// e.g:
+	// - exact: /foo
+	// - prefix: /bar
+	Paths []HTTPPath `json:"paths,omitempty" validate:"omitempty,dive"`
+}
+
+// HTTPPath specifies an HTTP path to match.  It may be either of the form:
+// exact: <path>: which matches the path exactly or
+// prefix: <path-prefix>: which matches the path prefix.  Exactly one of the fields must be set.
+type HTTPPath struct {
+	Exact  string `json:"exact,omitempty" validate:"omitempty,httppath"`
+	Prefix string `json:"prefix,omitempty" validate:"omitempty,httppath"`
 }
 
 // ICMPFields defines structure for ICMP and NotICMP sub-struct for ICMP code and type
@@ -110,6 +179,10 @@ type EntityRule struct {
 	// endpoints across all namespaces.
 	NamespaceSelector string `json:"namespaceSelector,omitempty" validate:"omitempty,selector"`
 
+	// ServiceAccounts is an optional field that restricts the rule to only apply to traffic
+	// that originates from (or terminates at) a pod running as a matching service account.
+	ServiceAccounts *ServiceAccountMatch `json:"serviceAccounts,omitempty" validate:"omitempty"`
+
 	// Ports is an optional field that restricts the rule to only apply to traffic that has a
 	// source (destination) port that matches one of these ranges/values. This value is a
 	// list of integers or strings that represent ranges of ports.
@@ -131,6 +204,20 @@ type EntityRule struct {
 	NotPorts []numorstring.Port `json:"notPorts,omitempty" validate:"omitempty,dive"`
 }
 
+// ServiceAccountMatch contains the service account match criteria for an EntityRule.  When
+// both Names and Selector are specified, a service account must match both.
+type ServiceAccountMatch struct {
+	// Names is an optional field that restricts the rule to only apply to traffic that
+	// originates from (or terminates at) a pod running as a service account whose name is
+	// in the list.
+	Names []string `json:"names,omitempty" validate:"omitempty,dive,name"`
+
+	// Selector is an optional field that restricts the rule to only apply to traffic that
+	// originates from (or terminates at) a pod running as a service account that matches
+	// the given label selector.
+	Selector string `json:"selector,omitempty" validate:"omitempty,selector"`
+}
+
 type Action string
 
 const (
@@ -0,0 +1,126 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+const (
+	KindStagedGlobalNetworkPolicy     = "StagedGlobalNetworkPolicy"
+	KindStagedGlobalNetworkPolicyList = "StagedGlobalNetworkPolicyList"
+)
+
+// StagedAction is the change that a staged policy will make to the enforced policy when the
+// staged policy is promoted.
+type StagedAction string
+
+const (
+	// StagedActionSet creates or replaces the enforced policy.
+	StagedActionSet StagedAction = "Set"
+	// StagedActionDelete deletes the enforced policy.
+	StagedActionDelete StagedAction = "Delete"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StagedGlobalNetworkPolicy is a staged version of a GlobalNetworkPolicy.  A staged policy is
+// evaluated against traffic, allowing the effect of a policy change to be previewed, but it is
+// not enforced.  Once the staged policy has been verified it can be promoted, which applies the
+// staged change to the GlobalNetworkPolicy of the same name.
+//
+// StagedGlobalNetworkPolicy is globally-scoped (i.e. not Namespaced).
+type StagedGlobalNetworkPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the Policy.
+	Spec StagedGlobalNetworkPolicySpec `json:"spec,omitempty"`
+}
+
+// StagedGlobalNetworkPolicySpec contains the StagedAction and the fields of the
+// GlobalNetworkPolicySpec.  See GlobalNetworkPolicySpec for details of each field.
+type StagedGlobalNetworkPolicySpec struct {
+	// The change made to the enforced policy when this policy is promoted.  If this is
+	// omitted, it defaults to Set.  When the StagedAction is Delete, the remaining fields
+	// are ignored.
+	StagedAction StagedAction `json:"stagedAction,omitempty" validate:"omitempty,stagedaction"`
+
+	Tier           string       `json:"tier,omitempty" validate:"omitempty,name"`
+	Order          *float64     `json:"order,omitempty"`
+	IngressRules   []Rule       `json:"ingress,omitempty" validate:"omitempty,dive"`
+	EgressRules    []Rule       `json:"egress,omitempty" validate:"omitempty,dive"`
+	Selector       string       `json:"selector" validate:"selector"`
+	Types          []PolicyType `json:"types,omitempty" validate:"omitempty,dive,policytype"`
+	DoNotTrack     bool         `json:"doNotTrack,omitempty"`
+	PreDNAT        bool         `json:"preDNAT,omitempty"`
+	ApplyOnForward bool         `json:"applyOnForward,omitempty"`
+	ExpiresAt      *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StagedGlobalNetworkPolicyList contains a list of StagedGlobalNetworkPolicy resources.
+type StagedGlobalNetworkPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []StagedGlobalNetworkPolicy `json:"items"`
+}
+
+// NewStagedGlobalNetworkPolicy creates a new (zeroed) StagedGlobalNetworkPolicy struct with the TypeMetadata initialised to the current
+// version.
+func NewStagedGlobalNetworkPolicy() *StagedGlobalNetworkPolicy {
+	return &StagedGlobalNetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindStagedGlobalNetworkPolicy,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// NewStagedGlobalNetworkPolicyList creates a new (zeroed) StagedGlobalNetworkPolicyList struct with the TypeMetadata initialised to the current
+// version.
+func NewStagedGlobalNetworkPolicyList() *StagedGlobalNetworkPolicyList {
+	return &StagedGlobalNetworkPolicyList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindStagedGlobalNetworkPolicyList,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// ConvertStagedGlobalPolicyToEnforced returns the StagedAction of the staged policy and the
+// GlobalNetworkPolicy that it stages.  The ObjectMeta of the staged policy is copied to the
+// returned policy.
+func ConvertStagedGlobalPolicyToEnforced(staged *StagedGlobalNetworkPolicy) (StagedAction, *GlobalNetworkPolicy) {
+	enforced := NewGlobalNetworkPolicy()
+	staged.ObjectMeta.DeepCopyInto(&enforced.ObjectMeta)
+	enforced.Spec = GlobalNetworkPolicySpec{
+		Tier:           staged.Spec.Tier,
+		Order:          staged.Spec.Order,
+		IngressRules:   staged.Spec.IngressRules,
+		EgressRules:    staged.Spec.EgressRules,
+		Selector:       staged.Spec.Selector,
+		Types:          staged.Spec.Types,
+		DoNotTrack:     staged.Spec.DoNotTrack,
+		PreDNAT:        staged.Spec.PreDNAT,
+		ApplyOnForward: staged.Spec.ApplyOnForward,
+		ExpiresAt:      staged.Spec.ExpiresAt,
+	}
+	action := staged.Spec.StagedAction
+	if action == "" {
+		action = StagedActionSet
+	}
+	return action, enforced
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+const (
+	KindStagedNetworkPolicy     = "StagedNetworkPolicy"
+	KindStagedNetworkPolicyList = "StagedNetworkPolicyList"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StagedNetworkPolicy is a staged version of a NetworkPolicy.  A staged policy is evaluated
+// against traffic, allowing the effect of a policy change to be previewed, but it is not
+// enforced.  Once the staged policy has been verified it can be promoted, which applies the
+// staged change to the NetworkPolicy of the same name and namespace.
+//
+// StagedNetworkPolicy is Namespaced.
+type StagedNetworkPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the Policy.
+	Spec StagedNetworkPolicySpec `json:"spec,omitempty"`
+}
+
+// StagedNetworkPolicySpec contains the StagedAction and the fields of the NetworkPolicySpec.
+// See NetworkPolicySpec for details of each field.
+type StagedNetworkPolicySpec struct {
+	// The change made to the enforced policy when this policy is promoted.  If this is
+	// omitted, it defaults to Set.  When the StagedAction is Delete, the remaining fields
+	// are ignored.
+	StagedAction StagedAction `json:"stagedAction,omitempty" validate:"omitempty,stagedaction"`
+
+	Tier         string       `json:"tier,omitempty" validate:"omitempty,name"`
+	Order        *float64     `json:"order,omitempty"`
+	IngressRules []Rule       `json:"ingress,omitempty" validate:"omitempty,dive"`
+	EgressRules  []Rule       `json:"egress,omitempty" validate:"omitempty,dive"`
+	Selector     string       `json:"selector" validate:"selector"`
+	Types        []PolicyType `json:"types,omitempty" validate:"omitempty,dive,policytype"`
+	ExpiresAt    *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StagedNetworkPolicyList contains a list of StagedNetworkPolicy resources.
+type StagedNetworkPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []StagedNetworkPolicy `json:"items"`
+}
+
+// NewStagedNetworkPolicy creates a new (zeroed) StagedNetworkPolicy struct with the TypeMetadata initialised to the current
+// version.
+func NewStagedNetworkPolicy() *StagedNetworkPolicy {
+	return &StagedNetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindStagedNetworkPolicy,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// NewStagedNetworkPolicyList creates a new (zeroed) StagedNetworkPolicyList struct with the TypeMetadata initialised to the current
+// version.
+func NewStagedNetworkPolicyList() *StagedNetworkPolicyList {
+	return &StagedNetworkPolicyList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindStagedNetworkPolicyList,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// ConvertStagedPolicyToEnforced returns the StagedAction of the staged policy and the
+// NetworkPolicy that it stages.  The ObjectMeta of the staged policy is copied to the
+// returned policy.
+func ConvertStagedPolicyToEnforced(staged *StagedNetworkPolicy) (StagedAction, *NetworkPolicy) {
+	enforced := NewNetworkPolicy()
+	staged.ObjectMeta.DeepCopyInto(&enforced.ObjectMeta)
+	enforced.Spec = NetworkPolicySpec{
+		Tier:         staged.Spec.Tier,
+		Order:        staged.Spec.Order,
+		IngressRules: staged.Spec.IngressRules,
+		EgressRules:  staged.Spec.EgressRules,
+		Selector:     staged.Spec.Selector,
+		Types:        staged.Spec.Types,
+		ExpiresAt:    staged.Spec.ExpiresAt,
+	}
+	action := staged.Spec.StagedAction
+	if action == "" {
+		action = StagedActionSet
+	}
+	return action, enforced
+}
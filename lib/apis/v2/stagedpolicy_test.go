@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/projectcalico/libcalico-go/lib/apis/v2"
+)
+
+// These tests verify that the staged policy spec structs are kept in sync with the enforced
+// policy spec structs.
+var _ = Describe("Staged policy specs", func() {
+	expectSameFields := func(staged, enforced interface{}) {
+		stagedFieldsByName := fieldsByName(staged)
+		enforcedFieldsByName := fieldsByName(enforced)
+		Expect(stagedFieldsByName).To(HaveKey("StagedAction"))
+		Expect(stagedFieldsByName).To(HaveLen(len(enforcedFieldsByName) + 1))
+		for n, f := range enforcedFieldsByName {
+			Expect(stagedFieldsByName).To(HaveKey(n))
+			Expect(stagedFieldsByName[n].Tag).To(Equal(f.Tag), "Field "+n+" had different tag")
+			Expect(stagedFieldsByName[n].Type).To(Equal(f.Type), "Field "+n+" had different type")
+		}
+	}
+
+	It("StagedGlobalNetworkPolicySpec should have the fields of GlobalNetworkPolicySpec", func() {
+		expectSameFields(StagedGlobalNetworkPolicySpec{}, GlobalNetworkPolicySpec{})
+	})
+
+	It("StagedNetworkPolicySpec should have the fields of NetworkPolicySpec", func() {
+		expectSameFields(StagedNetworkPolicySpec{}, NetworkPolicySpec{})
+	})
+})
+
+var _ = Describe("Staged policy conversion", func() {
+	order := float64(10)
+
+	It("should convert a StagedGlobalNetworkPolicy with no StagedAction to a Set", func() {
+		staged := NewStagedGlobalNetworkPolicy()
+		staged.ObjectMeta = metav1.ObjectMeta{Name: "policy1", Labels: map[string]string{"a": "b"}}
+		staged.Spec = StagedGlobalNetworkPolicySpec{
+			Order:      &order,
+			Selector:   "has(foo)",
+			Types:      []PolicyType{PolicyTypeIngress},
+			DoNotTrack: true,
+		}
+
+		action, enforced := ConvertStagedGlobalPolicyToEnforced(staged)
+		Expect(action).To(Equal(StagedActionSet))
+		Expect(enforced.Kind).To(Equal(KindGlobalNetworkPolicy))
+		Expect(enforced.ObjectMeta).To(Equal(staged.ObjectMeta))
+		Expect(enforced.Spec).To(Equal(GlobalNetworkPolicySpec{
+			Order:      &order,
+			Selector:   "has(foo)",
+			Types:      []PolicyType{PolicyTypeIngress},
+			DoNotTrack: true,
+		}))
+
+		By("checking the staged policy metadata is not shared")
+		enforced.Labels["a"] = "c"
+		Expect(staged.Labels["a"]).To(Equal("b"))
+	})
+
+	It("should convert a StagedNetworkPolicy with a Delete StagedAction", func() {
+		staged := NewStagedNetworkPolicy()
+		staged.ObjectMeta = metav1.ObjectMeta{Name: "policy1", Namespace: "ns1"}
+		staged.Spec = StagedNetworkPolicySpec{
+			StagedAction: StagedActionDelete,
+			Tier:         "tier1",
+			Selector:     "has(foo)",
+		}
+
+		action, enforced := ConvertStagedPolicyToEnforced(staged)
+		Expect(action).To(Equal(StagedActionDelete))
+		Expect(enforced.Kind).To(Equal(KindNetworkPolicy))
+		Expect(enforced.Name).To(Equal("policy1"))
+		Expect(enforced.Namespace).To(Equal("ns1"))
+		Expect(enforced.Spec).To(Equal(NetworkPolicySpec{
+			Tier:     "tier1",
+			Selector: "has(foo)",
+		}))
+	})
+})
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+const (
+	KindTier     = "Tier"
+	KindTierList = "TierList"
+
+	// DefaultTierName is the name of the tier that contains any policy that does not
+	// explicitly specify a tier.  The default tier does not need to be created.
+	DefaultTierName = "default"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Tier contains a set of policies that are applied to packets.  Multiple tiers may
+// be created and each tier is applied in the order specified in the tier specification.
+// Within a tier, the policies are applied in the order specified in each policy.
+//
+// Each packet is evaluated against the policies in each tier in turn:
+//
+//   - If a policy rule in the tier matches the packet with an "Allow" or "Deny" action
+//     the packet is accepted or dropped immediately, and no further tiers are evaluated.
+//   - If a policy rule in the tier matches the packet with a "Pass" action, the remaining
+//     policies in the tier are skipped and the packet is evaluated against the next tier.
+//   - If no rule in the tier matches the packet, but at least one policy in the tier applies
+//     to the endpoint, the packet is dropped.
+//
+// Tier is globally-scoped (i.e. not Namespaced).
+type Tier struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the Tier.
+	Spec TierSpec `json:"spec,omitempty"`
+}
+
+// TierSpec contains the specification for a security policy Tier resource.
+type TierSpec struct {
+	// Order is an optional field that specifies the order in which the tier is applied.
+	// Tiers with higher "order" are applied after those with lower order.  If the order
+	// is omitted, it may be considered to be "infinite" - i.e. the tier will be applied
+	// last.  Tiers with identical order will be applied in alphanumerical order based
+	// on the Tier "Name".
+	Order *float64 `json:"order,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TierList contains a list of Tier resources.
+type TierList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Tier `json:"items"`
+}
+
+// NewTier creates a new (zeroed) Tier struct with the TypeMetadata initialised to the current
+// version.
+func NewTier() *Tier {
+	return &Tier{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindTier,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
+
+// NewTierList creates a new (zeroed) TierList struct with the TypeMetadata initialised to the current
+// version.
+func NewTierList() *TierList {
+	return &TierList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindTierList,
+			APIVersion: GroupVersionCurrent,
+		},
+	}
+}
@@ -117,6 +117,33 @@ type Client interface {
 	//Close()
 }
 
+// TxnOpType is the type of a single operation within a transaction.
+type TxnOpType uint8
+
+const (
+	TxnCreate TxnOpType = iota
+	TxnUpdate
+	TxnDelete
+)
+
+// TxnOp is a single operation within a transaction.  A TxnCreate and TxnUpdate use the
+// full KVPair, a TxnDelete only uses the Key and (optional) Revision.
+type TxnOp struct {
+	Type   TxnOpType
+	KVPair *model.KVPair
+}
+
+// Transactor is an optional interface implemented by backend clients that are able
+// to apply several operations atomically.
+type Transactor interface {
+	// Txn applies either all or none of the supplied operations.  The same checks are
+	// made as for the individual Create, Update and Delete operations, and the error
+	// returned is the one the first failing operation would have returned on its own.
+	// On success, returns a KVPair for each operation with revision information
+	// filled-in.
+	Txn(ctx context.Context, ops []TxnOp) ([]*model.KVPair, error)
+}
+
 type Syncer interface {
 	// Starts the Syncer.  May start a background goroutine.
 	Start()
@@ -17,6 +17,7 @@ package etcdv3
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -275,6 +276,109 @@ func (c *etcdV3Client) Delete(ctx context.Context, k model.Key, revision string)
 	return previousValue, nil
 }
 
+// Txn applies a set of Create, Update and Delete operations in a single etcdv3 transaction.
+// Either all or none of the operations are applied.
+func (c *etcdV3Client) Txn(ctx context.Context, ops []api.TxnOp) ([]*model.KVPair, error) {
+	logCxt := log.WithField("numOps", len(ops))
+	logCxt.Debug("Processing Txn request")
+
+	conds := []clientv3.Cmp{}
+	thenOps := []clientv3.Op{}
+	elseOps := []clientv3.Op{}
+	revs := make([]int64, len(ops))
+	for i, op := range ops {
+		switch op.Type {
+		case api.TxnCreate, api.TxnUpdate:
+			key, value, err := getKeyValueStrings(op.KVPair)
+			if err != nil {
+				return nil, err
+			}
+			putOpts, err := c.getTTLOption(ctx, op.KVPair)
+			if err != nil {
+				return nil, err
+			}
+			if op.Type == api.TxnCreate {
+				conds = append(conds, clientv3.Compare(clientv3.Version(key), "=", 0))
+			} else {
+				// ResourceVersion must be set for an Update.
+				if revs[i], err = parseRevision(op.KVPair.Revision); err != nil {
+					return nil, err
+				}
+				conds = append(conds, clientv3.Compare(clientv3.ModRevision(key), "=", revs[i]))
+			}
+			thenOps = append(thenOps, clientv3.OpPut(key, value, putOpts...))
+			elseOps = append(elseOps, clientv3.OpGet(key))
+		case api.TxnDelete:
+			key, err := model.KeyToDefaultDeletePath(op.KVPair.Key)
+			if err != nil {
+				return nil, err
+			}
+			if len(op.KVPair.Revision) != 0 {
+				if revs[i], err = parseRevision(op.KVPair.Revision); err != nil {
+					return nil, err
+				}
+				conds = append(conds, clientv3.Compare(clientv3.ModRevision(key), "=", revs[i]))
+			} else {
+				// The key must exist for the delete to succeed.
+				conds = append(conds, clientv3.Compare(clientv3.Version(key), ">", 0))
+			}
+			thenOps = append(thenOps, clientv3.OpDelete(key, clientv3.WithPrevKV()))
+			elseOps = append(elseOps, clientv3.OpGet(key))
+		default:
+			return nil, fmt.Errorf("unknown transaction operation type: %d", op.Type)
+		}
+	}
+
+	logCxt.Debug("Performing etcdv3 transaction for Txn request")
+	txnResp, err := c.etcdClient.Txn(ctx).If(conds...).Then(thenOps...).Else(elseOps...).Commit()
+	if err != nil {
+		logCxt.WithError(err).Warning("Txn failed")
+		return nil, cerrors.ErrorDatastoreError{Err: err}
+	}
+
+	// If the transaction failed, use the results of the Get operations to work out which
+	// operation failed and return the same error that operation would have returned.
+	if !txnResp.Succeeded {
+		for i, op := range ops {
+			getResp := txnResp.Responses[i].GetResponseRange()
+			switch {
+			case op.Type == api.TxnCreate && len(getResp.Kvs) != 0:
+				logCxt.Info("Txn failed due to resource already existing")
+				existing, _ := etcdToKVPair(op.KVPair.Key, getResp.Kvs[0])
+				return []*model.KVPair{existing}, cerrors.ErrorResourceAlreadyExists{Identifier: op.KVPair.Key}
+			case op.Type != api.TxnCreate && len(getResp.Kvs) == 0:
+				logCxt.Info("Txn failed due to resource not existing")
+				return nil, cerrors.ErrorResourceDoesNotExist{Identifier: op.KVPair.Key}
+			case revs[i] != 0 && getResp.Kvs[0].ModRevision != revs[i]:
+				logCxt.Info("Txn failed due to resource update conflict")
+				existing, _ := etcdToKVPair(op.KVPair.Key, getResp.Kvs[0])
+				return []*model.KVPair{existing}, cerrors.ErrorResourceUpdateConflict{Identifier: op.KVPair.Key}
+			}
+		}
+		// The data changed between the transaction and the Get operations - report this
+		// as a conflict on the first operation.
+		logCxt.Info("Txn failed due to concurrent update")
+		return nil, cerrors.ErrorResourceUpdateConflict{Identifier: ops[0].KVPair.Key}
+	}
+
+	rev := strconv.FormatInt(txnResp.Header.Revision, 10)
+	kvps := make([]*model.KVPair, len(ops))
+	for i, op := range ops {
+		if op.Type == api.TxnDelete {
+			// Parse the deleted value.  Don't propagate the error in this case since the
+			// delete did succeed.
+			delResp := txnResp.Responses[i].GetResponseDeleteRange()
+			if len(delResp.PrevKvs) != 0 {
+				kvps[i], _ = etcdToKVPair(op.KVPair.Key, delResp.PrevKvs[0])
+			}
+			continue
+		}
+		op.KVPair.Revision = rev
+		kvps[i] = op.KVPair
+	}
+	return kvps, nil
+}
+
 // Get an entry from the datastore.  This errors if the entry does not exist.
 func (c *etcdV3Client) Get(ctx context.Context, k model.Key, revision string) (*model.KVPair, error) {
 	logCxt := log.WithFields(log.Fields{"model-etcdKey": k, "rev": revision})
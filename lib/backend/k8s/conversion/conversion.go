@@ -103,6 +103,41 @@ func (c Converter) NamespaceToProfile(ns *kapiv1.Namespace) (*model.KVPair, erro
 	return &kvp, nil
 }
 
+// ServiceAccountToProfile converts a ServiceAccount to a Calico Profile.  The Profile stores
+// labels from the ServiceAccount which are inherited by the WorkloadEndpoints running as that
+// ServiceAccount.  This Profile has no rules; policy is provided by the Namespace Profile.
+func (c Converter) ServiceAccountToProfile(sa *kapiv1.ServiceAccount) (*model.KVPair, error) {
+	// Generate the labels to apply to the profile, using a special prefix
+	// to indicate that these are the labels from the parent Kubernetes ServiceAccount.
+	labels := map[string]string{}
+	for k, v := range sa.Labels {
+		labels[ServiceAccountLabelPrefix+k] = v
+	}
+
+	// Create the profile object.
+	name := serviceAccountProfileName(sa.Namespace, sa.Name)
+	profile := apiv2.NewProfile()
+	profile.ObjectMeta = metav1.ObjectMeta{
+		Name:              name,
+		CreationTimestamp: sa.CreationTimestamp,
+		UID:               sa.UID,
+	}
+	profile.Spec = apiv2.ProfileSpec{
+		LabelsToApply: labels,
+	}
+
+	// Embed the profile in a KVPair.
+	kvp := model.KVPair{
+		Key: model.ResourceKey{
+			Name: name,
+			Kind: apiv2.KindProfile,
+		},
+		Value:    profile,
+		Revision: sa.ResourceVersion,
+	}
+	return &kvp, nil
+}
+
 // IsValidCalicoWorkloadEndpoint returns true if the pod should be shown as a workloadEndpoint
 // in the Calico API and false otherwise.  A Pod suitable for Calico should not be host
 // networked and should have been scheduled to a Node.
@@ -176,7 +211,7 @@ func (c Converter) PodToWorkloadEndpoint(pod *kapiv1.Pod) (*model.KVPair, error)
 	interfaceName := VethNameForWorkload(wepName)
 
 	// Build the labels map.  Start with the pod labels, and append two additional labels for
-	// namespace and orchestrator matches.
+	// namespace and orchestrator matches (and service account, if set).
 	labels := pod.Labels
 	if labels == nil {
 		labels = make(map[string]string, 2)
@@ -184,6 +219,14 @@ func (c Converter) PodToWorkloadEndpoint(pod *kapiv1.Pod) (*model.KVPair, error)
 	labels[apiv2.LabelNamespace] = pod.Namespace
 	labels[apiv2.LabelOrchestrator] = apiv2.OrchestratorKubernetes
 
+	// If the pod is running as a service account, add a label for service account name
+	// matches, and the service account profile which provides the service account labels.
+	profiles := []string{profile}
+	if pod.Spec.ServiceAccountName != "" {
+		labels[apiv2.LabelServiceAccount] = pod.Spec.ServiceAccountName
+		profiles = append(profiles, serviceAccountProfileName(pod.Namespace, pod.Spec.ServiceAccountName))
+	}
+
 	// Map any named ports through.
 	var endpointPorts []apiv2.EndpointPort
 	for _, container := range pod.Spec.Containers {
@@ -228,7 +271,7 @@ func (c Converter) PodToWorkloadEndpoint(pod *kapiv1.Pod) (*model.KVPair, error)
 		Pod:           pod.Name,
 		Endpoint:      "eth0",
 		InterfaceName: interfaceName,
-		Profiles:      []string{profile},
+		Profiles:      profiles,
 		IPNetworks:    ipNets,
 		Ports:         endpointPorts,
 	}
@@ -535,3 +578,25 @@ func (c Converter) ProfileNameToNamespace(profileName string) (string, error) {
 
 	return strings.TrimPrefix(profileName, NamespaceProfileNamePrefix), nil
 }
+
+// ProfileNameToServiceAccount extracts the Namespace and ServiceAccount names from the given
+// Profile name.
+func (c Converter) ProfileNameToServiceAccount(profileName string) (ns, sa string, err error) {
+	// Profile objects backed by ServiceAccounts have form "ksa.<ns_name>.<sa_name>"
+	if !strings.HasPrefix(profileName, ServiceAccountProfileNamePrefix) {
+		// This is not backed by a Kubernetes ServiceAccount.
+		return "", "", fmt.Errorf("Profile %s not backed by a ServiceAccount", profileName)
+	}
+
+	// Namespace names cannot contain a ".", so split on the first one.
+	parts := strings.SplitN(strings.TrimPrefix(profileName, ServiceAccountProfileNamePrefix), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("Profile %s has an invalid ServiceAccount profile name", profileName)
+	}
+	return parts[0], parts[1], nil
+}
+
+// serviceAccountProfileName returns the name of the Profile backed by the given ServiceAccount.
+func serviceAccountProfileName(ns, sa string) string {
+	return ServiceAccountProfileNamePrefix + ns + "." + sa
+}
@@ -56,6 +56,20 @@ var _ = Describe("Test parsing strings", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(ns).To(Equal(""))
 	})
+
+	It("should parse valid service account profile names", func() {
+		ns, sa, err := c.ProfileNameToServiceAccount("ksa.default.sa.with.dots")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns).To(Equal("default"))
+		Expect(sa).To(Equal("sa.with.dots"))
+	})
+
+	It("should not parse invalid service account profile names", func() {
+		_, _, err := c.ProfileNameToServiceAccount("kns.default")
+		Expect(err).To(HaveOccurred())
+		_, _, err = c.ProfileNameToServiceAccount("ksa.default")
+		Expect(err).To(HaveOccurred())
+	})
 })
 
 var _ = Describe("Test Pod conversion", func() {
@@ -223,6 +237,32 @@ var _ = Describe("Test Pod conversion", func() {
 		}))
 	})
 
+	It("should parse a Pod with a service account", func() {
+		pod := kapiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "podA",
+				Namespace: "default",
+			},
+			Spec: kapiv1.PodSpec{
+				NodeName:           "nodeA",
+				ServiceAccountName: "sa1",
+			},
+			Status: kapiv1.PodStatus{
+				PodIP: "192.168.0.1",
+			},
+		}
+
+		wep, err := c.PodToWorkloadEndpoint(&pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(wep.Value.(*apiv2.WorkloadEndpoint).Spec.Profiles).To(Equal([]string{"kns.default", "ksa.default.sa1"}))
+		Expect(wep.Value.(*apiv2.WorkloadEndpoint).ObjectMeta.Labels).To(Equal(map[string]string{
+			"projectcalico.org/namespace":      "default",
+			"projectcalico.org/orchestrator":   "k8s",
+			"projectcalico.org/serviceaccount": "sa1",
+		}))
+	})
+
 	It("should not parse a Pod with no NodeName", func() {
 		pod := kapiv1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1442,6 +1482,41 @@ var _ = Describe("Test NetworkPolicy conversion (k8s <= 1.7, no policyTypes)", f
 	})
 })
 
+var _ = Describe("Test ServiceAccount conversion", func() {
+
+	// Use a single instance of the Converter for these tests.
+	c := Converter{}
+
+	It("should parse a ServiceAccount to a Profile", func() {
+		sa := kapiv1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "sa1",
+				Namespace:       "default",
+				ResourceVersion: "1234",
+				Labels: map[string]string{
+					"foo":   "bar",
+					"roger": "rabbit",
+				},
+			},
+		}
+
+		p, err := c.ServiceAccountToProfile(&sa)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Key.(model.ResourceKey).Name).To(Equal("ksa.default.sa1"))
+		Expect(p.Key.(model.ResourceKey).Kind).To(Equal(apiv2.KindProfile))
+		Expect(p.Revision).To(Equal("1234"))
+
+		// Service account profiles only provide labels.
+		Expect(p.Value.(*apiv2.Profile).Spec.IngressRules).To(BeEmpty())
+		Expect(p.Value.(*apiv2.Profile).Spec.EgressRules).To(BeEmpty())
+		Expect(p.Value.(*apiv2.Profile).Spec.LabelsToApply).To(Equal(map[string]string{
+			"pcsa.foo":   "bar",
+			"pcsa.roger": "rabbit",
+		}))
+	})
+})
+
 var _ = Describe("Test Namespace conversion", func() {
 
 	// Use a single instance of the Converter for these tests.
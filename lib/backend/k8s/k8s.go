@@ -153,12 +153,54 @@ func NewKubeClient(kc *apiconfig.KubeConfig) (api.Client, error) {
 		apiv2.KindNetworkPolicy,
 		resources.NewNetworkPolicyClient(cs, crdClientV1),
 	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindStagedGlobalNetworkPolicy,
+		resources.NewStagedGlobalNetworkPolicyClient(cs, crdClientV1),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindStagedNetworkPolicy,
+		resources.NewStagedNetworkPolicyClient(cs, crdClientV1),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindNetworkSet,
+		resources.NewNetworkSetClient(cs, crdClientV1),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindTier,
+		resources.NewTierClient(cs, crdClientV1),
+	)
 	kubeClient.registerResourceClient(
 		reflect.TypeOf(model.ResourceKey{}),
 		reflect.TypeOf(model.ResourceListOptions{}),
 		apiv2.KindBGPPeer,
 		resources.NewBGPPeerClient(cs, crdClientV1),
 	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindBGPFilter,
+		resources.NewBGPFilterClient(cs, crdClientV1),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindIPReservation,
+		resources.NewIPReservationClient(cs, crdClientV1),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		apiv2.KindNodeStatus,
+		resources.NewNodeStatusClient(cs, crdClientV1),
+	)
 	kubeClient.registerResourceClient(
 		reflect.TypeOf(model.ResourceKey{}),
 		reflect.TypeOf(model.ResourceListOptions{}),
@@ -195,12 +237,30 @@ func NewKubeClient(kc *apiconfig.KubeConfig) (api.Client, error) {
 		apiv2.KindWorkloadEndpoint,
 		resources.NewWorkloadEndpointClient(cs),
 	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		model.KindKubernetesService,
+		resources.NewServiceClient(cs),
+	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.ResourceKey{}),
+		reflect.TypeOf(model.ResourceListOptions{}),
+		model.KindKubernetesEndpoints,
+		resources.NewEndpointsClient(cs),
+	)
 	kubeClient.registerResourceClient(
 		reflect.TypeOf(model.BlockAffinityKey{}),
 		reflect.TypeOf(model.BlockAffinityListOptions{}),
 		"",
 		resources.NewAffinityBlockClient(cs),
 	)
+	kubeClient.registerResourceClient(
+		reflect.TypeOf(model.SecretKey{}),
+		reflect.TypeOf(model.SecretListOptions{}),
+		"",
+		resources.NewSecretClient(cs),
+	)
 
 	return kubeClient, nil
 }
@@ -261,10 +321,17 @@ func (c *KubeClient) Clean() error {
 	kinds := []string{
 		apiv2.KindBGPConfiguration,
 		apiv2.KindBGPPeer,
+		apiv2.KindBGPFilter,
 		apiv2.KindClusterInformation,
 		apiv2.KindFelixConfiguration,
 		apiv2.KindGlobalNetworkPolicy,
 		apiv2.KindIPPool,
+		apiv2.KindIPReservation,
+		apiv2.KindNetworkSet,
+		apiv2.KindNodeStatus,
+		apiv2.KindStagedGlobalNetworkPolicy,
+		apiv2.KindStagedNetworkPolicy,
+		apiv2.KindTier,
 	}
 	ctx := context.Background()
 	for _, k := range kinds {
@@ -322,6 +389,12 @@ func buildCRDClientV1(cfg rest.Config) (*rest.RESTClient, error) {
 				&apiv2.IPPoolList{},
 				&apiv2.BGPPeer{},
 				&apiv2.BGPPeerList{},
+				&apiv2.BGPFilter{},
+				&apiv2.BGPFilterList{},
+				&apiv2.IPReservation{},
+				&apiv2.IPReservationList{},
+				&apiv2.NodeStatus{},
+				&apiv2.NodeStatusList{},
 				&apiv2.BGPConfiguration{},
 				&apiv2.BGPConfigurationList{},
 				&apiv2.ClusterInformation{},
@@ -330,6 +403,14 @@ func buildCRDClientV1(cfg rest.Config) (*rest.RESTClient, error) {
 				&apiv2.GlobalNetworkPolicyList{},
 				&apiv2.NetworkPolicy{},
 				&apiv2.NetworkPolicyList{},
+				&apiv2.StagedGlobalNetworkPolicy{},
+				&apiv2.StagedGlobalNetworkPolicyList{},
+				&apiv2.StagedNetworkPolicy{},
+				&apiv2.StagedNetworkPolicyList{},
+				&apiv2.NetworkSet{},
+				&apiv2.NetworkSetList{},
+				&apiv2.Tier{},
+				&apiv2.TierList{},
 			)
 			return nil
 		})
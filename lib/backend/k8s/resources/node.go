@@ -36,6 +36,7 @@ const (
 	nodeBgpIpv4AddrAnnotation = "projectcalico.org/IPv4Address"
 	nodeBgpIpv6AddrAnnotation = "projectcalico.org/IPv6Address"
 	nodeBgpAsnAnnotation      = "projectcalico.org/ASNumber"
+	nodeVXLANTunnelAnnotation = "projectcalico.org/IPv4VXLANTunnelAddr"
 )
 
 func NewNodeClient(c *kubernetes.Clientset) K8sResourceClient {
@@ -184,9 +185,24 @@ func K8sNodeToCalico(k8sNode *kapiv1.Node) (*model.KVPair, error) {
 	calicoNode.ObjectMeta.Name = k8sNode.Name
 	SetCalicoMetadataFromK8sAnnotations(calicoNode, k8sNode)
 
-	// Extract the BGP configuration stored in the annotations.
-	bgpSpec := &apiv2.NodeBGPSpec{}
+	// The Calico Node inherits the labels of the k8s Node, so that they may be used by
+	// Calico selectors (e.g. IP pool node selectors).  Labels set explicitly on the
+	// Calico Node take precedence.
+	if len(k8sNode.Labels) > 0 {
+		labels := map[string]string{}
+		for k, v := range k8sNode.Labels {
+			labels[k] = v
+		}
+		for k, v := range calicoNode.Labels {
+			labels[k] = v
+		}
+		calicoNode.Labels = labels
+	}
+
+	// Extract the VXLAN tunnel address and BGP configuration stored in the annotations.
 	annotations := k8sNode.ObjectMeta.Annotations
+	calicoNode.Spec.IPv4VXLANTunnelAddr = annotations[nodeVXLANTunnelAnnotation]
+	bgpSpec := &apiv2.NodeBGPSpec{}
 	bgpSpec.IPv4Address = annotations[nodeBgpIpv4AddrAnnotation]
 	bgpSpec.IPv6Address = annotations[nodeBgpIpv6AddrAnnotation]
 	asnString, ok := annotations[nodeBgpAsnAnnotation]
@@ -221,8 +237,29 @@ func K8sNodeToCalico(k8sNode *kapiv1.Node) (*model.KVPair, error) {
 // node into the k8s node.
 func mergeCalicoNodeIntoK8sNode(calicoNode *apiv2.Node, k8sNode *kapiv1.Node) (*kapiv1.Node, error) {
 	// Set the k8s annotations from the Calico node metadata.  This ensures the k8s annotations
-	// is initialized.
-	SetK8sAnnotationsFromCalicoMetadata(k8sNode, calicoNode)
+	// is initialized.  Labels that are inherited from the k8s node are not stored in the
+	// annotation, so that changes to the k8s node labels are picked up.
+	metadataNode := &apiv2.Node{ObjectMeta: calicoNode.ObjectMeta}
+	metadataNode.Labels = nil
+	for k, v := range calicoNode.Labels {
+		if kv, ok := k8sNode.Labels[k]; ok && kv == v {
+			continue
+		}
+		if metadataNode.Labels == nil {
+			metadataNode.Labels = map[string]string{}
+		}
+		metadataNode.Labels[k] = v
+	}
+	SetK8sAnnotationsFromCalicoMetadata(k8sNode, metadataNode)
+	if len(metadataNode.Labels) == 0 {
+		delete(k8sNode.Annotations, labelsAnnotation)
+	}
+
+	if calicoNode.Spec.IPv4VXLANTunnelAddr != "" {
+		k8sNode.Annotations[nodeVXLANTunnelAnnotation] = calicoNode.Spec.IPv4VXLANTunnelAddr
+	} else {
+		delete(k8sNode.Annotations, nodeVXLANTunnelAnnotation)
+	}
 
 	if calicoNode.Spec.BGP == nil {
 		// If it is a empty NodeBGPSpec, remove all annotations.
@@ -153,6 +153,83 @@ var _ = Describe("Test Node conversion", func() {
 		calicoNode.Spec.BGP.IPv4IPIPTunnelAddr = "172.100.0.1"
 		newCalicoNode, err := K8sNodeToCalico(newK8sNode)
 		Expect(err).NotTo(HaveOccurred())
+
+		// The calico node also inherits the k8s node labels.
+		calicoNode.Labels = map[string]string{
+			"label1":                      "foo",
+			"label2":                      "bar",
+			"net.beta.kubernetes.io/role": "master",
+		}
 		Expect(newCalicoNode.Value).To(Equal(calicoNode))
 	})
+
+	It("Should store the VXLAN tunnel address in an annotation", func() {
+		k8sNode := &k8sapi.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "TestNode",
+				ResourceVersion: "1234",
+				Annotations:     map[string]string{},
+			},
+		}
+
+		calicoNode := apiv2.NewNode()
+		calicoNode.Name = "TestNode"
+		calicoNode.Spec.IPv4VXLANTunnelAddr = "10.0.0.1"
+
+		newK8sNode, err := mergeCalicoNodeIntoK8sNode(calicoNode, k8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newK8sNode.Annotations).To(HaveKeyWithValue(nodeVXLANTunnelAnnotation, "10.0.0.1"))
+
+		kvp, err := K8sNodeToCalico(newK8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvp.Value.(*apiv2.Node).Spec.IPv4VXLANTunnelAddr).To(Equal("10.0.0.1"))
+		Expect(kvp.Value.(*apiv2.Node).Spec.BGP).To(BeNil())
+
+		calicoNode.Spec.IPv4VXLANTunnelAddr = ""
+		newK8sNode, err = mergeCalicoNodeIntoK8sNode(calicoNode, newK8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newK8sNode.Annotations).NotTo(HaveKey(nodeVXLANTunnelAnnotation))
+	})
+
+	It("Should inherit k8s node labels without storing them in the Calico labels annotation", func() {
+		k8sNode := &k8sapi.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "TestNode",
+				ResourceVersion: "1234",
+				Labels: map[string]string{
+					"failure-domain.beta.kubernetes.io/zone": "zone1",
+					"rack":                                   "rack1",
+				},
+				Annotations: map[string]string{
+					labelsAnnotation: `{"rack":"rack2","label1":"foo"}`,
+				},
+			},
+		}
+
+		By("Converting the k8s node and checking the Calico labels take precedence")
+		kvp, err := K8sNodeToCalico(k8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		calicoNode := kvp.Value.(*apiv2.Node)
+		Expect(calicoNode.Labels).To(Equal(map[string]string{
+			"failure-domain.beta.kubernetes.io/zone": "zone1",
+			"rack":                                   "rack2",
+			"label1":                                 "foo",
+		}))
+
+		By("Merging the Calico node back and checking only the Calico specific labels are stored")
+		calicoNode.Labels["rack"] = "rack1"
+		newK8sNode, err := mergeCalicoNodeIntoK8sNode(calicoNode, k8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newK8sNode.Annotations).To(HaveKeyWithValue(labelsAnnotation, `{"label1":"foo"}`))
+		Expect(newK8sNode.Labels).To(Equal(map[string]string{
+			"failure-domain.beta.kubernetes.io/zone": "zone1",
+			"rack":                                   "rack1",
+		}))
+
+		By("Removing the Calico specific labels and checking the annotation is removed")
+		delete(calicoNode.Labels, "label1")
+		newK8sNode, err = mergeCalicoNodeIntoK8sNode(calicoNode, newK8sNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newK8sNode.Annotations).NotTo(HaveKey(labelsAnnotation))
+	})
 })
@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 	kapiv1 "k8s.io/api/core/v1"
@@ -72,6 +74,21 @@ func (c *profileClient) Get(ctx context.Context, key model.Key, revision string)
 	if rk.Name == "" {
 		return nil, fmt.Errorf("Profile key missing name: %+v", rk)
 	}
+
+	// Profiles may be backed by a ServiceAccount rather than a Namespace.
+	if strings.HasPrefix(rk.Name, conversion.ServiceAccountProfileNamePrefix) {
+		namespaceName, serviceAccountName, err := c.converter.ProfileNameToServiceAccount(rk.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse Profile name: %s", err)
+		}
+		serviceAccount, err := c.clientSet.CoreV1().ServiceAccounts(namespaceName).Get(serviceAccountName, metav1.GetOptions{ResourceVersion: revision})
+		if err != nil {
+			return nil, K8sErrorToCalico(err, rk)
+		}
+
+		return c.converter.ServiceAccountToProfile(serviceAccount)
+	}
+
 	namespaceName, err := c.converter.ProfileNameToNamespace(rk.Name)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to parse Profile name: %s", err)
@@ -109,8 +126,13 @@ func (c *profileClient) List(ctx context.Context, list model.ListInterface, revi
 		}, nil
 	}
 
-	// Otherwise, enumerate all.
-	namespaces, err := c.clientSet.CoreV1().Namespaces().List(metav1.ListOptions{ResourceVersion: revision})
+	// Otherwise, enumerate all.  The revision is a combination of the Namespace and
+	// ServiceAccount revisions.
+	nsRevision, saRevision, err := splitProfileRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := c.clientSet.CoreV1().Namespaces().List(metav1.ListOptions{ResourceVersion: nsRevision})
 	if err != nil {
 		return nil, K8sErrorToCalico(err, nl)
 	}
@@ -124,9 +146,24 @@ func (c *profileClient) List(ctx context.Context, list model.ListInterface, revi
 		}
 		kvps = append(kvps, kvp)
 	}
+
+	serviceAccounts, err := c.clientSet.CoreV1().ServiceAccounts(metav1.NamespaceAll).List(metav1.ListOptions{ResourceVersion: saRevision})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, nl)
+	}
+
+	// For each ServiceAccount, return a profile.
+	for _, sa := range serviceAccounts.Items {
+		kvp, err := c.converter.ServiceAccountToProfile(&sa)
+		if err != nil {
+			log.Errorf("Unable to convert k8s ServiceAccount to Calico Profile: ServiceAccount=%s/%s: %v", sa.Namespace, sa.Name, err)
+			continue
+		}
+		kvps = append(kvps, kvp)
+	}
 	return &model.KVPairList{
 		KVPairs:  kvps,
-		Revision: namespaces.ResourceVersion,
+		Revision: joinProfileRevision(namespaces.ResourceVersion, serviceAccounts.ResourceVersion),
 	}, nil
 }
 
@@ -138,17 +175,135 @@ func (c *profileClient) Watch(ctx context.Context, list model.ListInterface, rev
 	if len(list.(model.ResourceListOptions).Name) != 0 {
 		return nil, fmt.Errorf("cannot watch specific resource instance: %s", list.(model.ResourceListOptions).Name)
 	}
+	nsRevision, saRevision, err := splitProfileRevision(revision)
+	if err != nil {
+		return nil, err
+	}
 
-	k8sWatch, err := c.clientSet.CoreV1().Namespaces().Watch(metav1.ListOptions{ResourceVersion: revision})
+	nsWatch, err := c.clientSet.CoreV1().Namespaces().Watch(metav1.ListOptions{ResourceVersion: nsRevision})
 	if err != nil {
 		return nil, K8sErrorToCalico(err, list)
 	}
-	converter := func(r Resource) (*model.KVPair, error) {
+	nsConverter := func(r Resource) (*model.KVPair, error) {
 		k8sNamespace, ok := r.(*kapiv1.Namespace)
 		if !ok {
 			return nil, errors.New("profile conversion with incorrect k8s resource type")
 		}
 		return c.converter.NamespaceToProfile(k8sNamespace)
 	}
-	return newK8sWatcherConverter(ctx, "Profile", converter, k8sWatch), nil
+
+	saWatch, err := c.clientSet.CoreV1().ServiceAccounts(metav1.NamespaceAll).Watch(metav1.ListOptions{ResourceVersion: saRevision})
+	if err != nil {
+		nsWatch.Stop()
+		return nil, K8sErrorToCalico(err, list)
+	}
+	saConverter := func(r Resource) (*model.KVPair, error) {
+		k8sServiceAccount, ok := r.(*kapiv1.ServiceAccount)
+		if !ok {
+			return nil, errors.New("profile conversion with incorrect k8s resource type")
+		}
+		return c.converter.ServiceAccountToProfile(k8sServiceAccount)
+	}
+
+	return newProfileWatcher(
+		ctx,
+		newK8sWatcherConverter(ctx, "Profile (Namespace)", nsConverter, nsWatch),
+		newK8sWatcherConverter(ctx, "Profile (ServiceAccount)", saConverter, saWatch),
+	), nil
+}
+
+// joinProfileRevision combines the Namespace and ServiceAccount revisions into a single
+// Profile revision.
+func joinProfileRevision(nsRevision, saRevision string) string {
+	return nsRevision + "/" + saRevision
+}
+
+// splitProfileRevision splits a Profile revision into the Namespace and ServiceAccount
+// revisions.  A blank revision is split into two blank revisions.
+func splitProfileRevision(revision string) (nsRevision, saRevision string, err error) {
+	if revision == "" {
+		return "", "", nil
+	}
+	parts := strings.Split(revision, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("Invalid Profile revision: %s", revision)
+	}
+	return parts[0], parts[1], nil
+}
+
+// newProfileWatcher returns a watcher that merges the events from the Namespace and
+// ServiceAccount backed Profile watchers.
+func newProfileWatcher(ctx context.Context, nsWatch, saWatch api.WatchInterface) api.WatchInterface {
+	ctx, cancel := context.WithCancel(ctx)
+	pw := &profileWatcher{
+		nsWatch:    nsWatch,
+		saWatch:    saWatch,
+		context:    ctx,
+		cancel:     cancel,
+		resultChan: make(chan api.WatchEvent, resultsBufSize),
+	}
+	go pw.processProfileEvents()
+	return pw
+}
+
+type profileWatcher struct {
+	nsWatch    api.WatchInterface
+	saWatch    api.WatchInterface
+	context    context.Context
+	cancel     context.CancelFunc
+	resultChan chan api.WatchEvent
+	terminated uint32
+}
+
+// Stop stops the watcher and releases associated resources.
+// This calls through to the context cancel function.
+func (pw *profileWatcher) Stop() {
+	pw.cancel()
+	pw.nsWatch.Stop()
+	pw.saWatch.Stop()
+}
+
+// ResultChan returns a channel used to receive WatchEvents.
+func (pw *profileWatcher) ResultChan() <-chan api.WatchEvent {
+	return pw.resultChan
+}
+
+// HasTerminated returns true when the watcher has completed termination processing.
+func (pw *profileWatcher) HasTerminated() bool {
+	return atomic.LoadUint32(&pw.terminated) != 0
+}
+
+// Loop to process the events from both underlying watchers.  When either watcher
+// terminates, this watcher terminates.
+func (pw *profileWatcher) processProfileEvents() {
+	log.Info("Profile watcher process started")
+	defer func() {
+		log.Info("Profile watcher process terminated")
+		pw.Stop()
+		close(pw.resultChan)
+		atomic.AddUint32(&pw.terminated, 1)
+	}()
+
+	for {
+		var event api.WatchEvent
+		var ok bool
+		select {
+		case event, ok = <-pw.nsWatch.ResultChan():
+		case event, ok = <-pw.saWatch.ResultChan():
+		case <-pw.context.Done(): // user cancel
+			log.Info("Process profile watcher done event")
+			return
+		}
+		if !ok {
+			log.Info("Underlying profile watcher terminated")
+			return
+		}
+
+		select {
+		case pw.resultChan <- event:
+		case <-pw.context.Done():
+			log.Info("Process profile watcher done event during watch event")
+			return
+		}
+	}
 }
@@ -0,0 +1,163 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// The namespace containing the Kubernetes Secrets referenced by Calico resources.
+const secretsNamespace = metav1.NamespaceSystem
+
+func NewSecretClient(c *kubernetes.Clientset) K8sResourceClient {
+	return &SecretClient{
+		clientSet: c,
+	}
+}
+
+// Implements the api.Client interface for Secrets.  The Secrets are managed through the
+// Kubernetes API, so this client is read only.  Only the Secrets in the Calico namespace
+// that have the BGP password label are visible, so that the other Secrets in the namespace
+// are never listed, watched or cached by Calico.
+type SecretClient struct {
+	clientSet *kubernetes.Clientset
+}
+
+func (c *SecretClient) Create(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	log.Warn("Operation Create is not supported on Secret type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: kvp.Key,
+		Operation:  "Create",
+	}
+}
+
+func (c *SecretClient) Update(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	log.Warn("Operation Update is not supported on Secret type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: kvp.Key,
+		Operation:  "Update",
+	}
+}
+
+func (c *SecretClient) Delete(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	log.Warn("Operation Delete is not supported on Secret type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: key,
+		Operation:  "Delete",
+	}
+}
+
+func (c *SecretClient) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	log.Debug("Received Get request on Secret type")
+	k := key.(model.SecretKey)
+	secret, err := c.clientSet.CoreV1().Secrets(secretsNamespace).Get(k.Name, metav1.GetOptions{ResourceVersion: revision})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, key)
+	}
+	if _, ok := secret.Labels[apiv2.LabelBGPPassword]; !ok {
+		log.WithField("Secret", k.Name).Debug("Secret does not have the BGP password label")
+		return nil, cerrors.ErrorResourceDoesNotExist{Identifier: key}
+	}
+	return secretToKVPair(secret), nil
+}
+
+func (c *SecretClient) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+	log.Debug("Received List request on Secret type")
+	sl := list.(model.SecretListOptions)
+	kvpl := &model.KVPairList{
+		KVPairs:  []*model.KVPair{},
+		Revision: revision,
+	}
+
+	// If a name is specified, then do an exact lookup.
+	if sl.Name != "" {
+		kvp, err := c.Get(ctx, model.SecretKey{Name: sl.Name}, revision)
+		if err != nil {
+			if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+				return nil, err
+			}
+			return kvpl, nil
+		}
+		kvpl.KVPairs = append(kvpl.KVPairs, kvp)
+		kvpl.Revision = kvp.Revision
+		return kvpl, nil
+	}
+
+	secrets, err := c.clientSet.CoreV1().Secrets(secretsNamespace).List(metav1.ListOptions{
+		ResourceVersion: revision,
+		LabelSelector:   apiv2.LabelBGPPassword,
+	})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, list)
+	}
+	for i := range secrets.Items {
+		kvpl.KVPairs = append(kvpl.KVPairs, secretToKVPair(&secrets.Items[i]))
+	}
+	kvpl.Revision = secrets.ResourceVersion
+	return kvpl, nil
+}
+
+func (c *SecretClient) EnsureInitialized() error {
+	return nil
+}
+
+func (c *SecretClient) Watch(ctx context.Context, list model.ListInterface, revision string) (api.WatchInterface, error) {
+	sl := list.(model.SecretListOptions)
+	if len(sl.Name) != 0 {
+		return nil, fmt.Errorf("cannot watch specific resource instance: %s", sl.Name)
+	}
+
+	k8sWatch, err := c.clientSet.CoreV1().Secrets(secretsNamespace).Watch(metav1.ListOptions{
+		ResourceVersion: revision,
+		LabelSelector:   apiv2.LabelBGPPassword,
+	})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, list)
+	}
+	converter := func(r Resource) (*model.KVPair, error) {
+		secret, ok := r.(*kapiv1.Secret)
+		if !ok {
+			return nil, errors.New("secret conversion with incorrect k8s resource type")
+		}
+		return secretToKVPair(secret), nil
+	}
+	return newK8sWatcherConverter(ctx, "Secret", converter, k8sWatch), nil
+}
+
+// secretToKVPair converts a Kubernetes Secret to the equivalent Secret KVPair.
+func secretToKVPair(secret *kapiv1.Secret) *model.KVPair {
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	return &model.KVPair{
+		Key:      model.SecretKey{Name: secret.Name},
+		Value:    data,
+		Revision: secret.ResourceVersion,
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+func NewServiceClient(c *kubernetes.Clientset) K8sResourceClient {
+	return &serviceClient{
+		clientSet: c,
+	}
+}
+
+// Implements the api.Client interface for Kubernetes Services.  The Services are managed
+// through the Kubernetes API, so this client is read only.
+type serviceClient struct {
+	clientSet *kubernetes.Clientset
+}
+
+func (c *serviceClient) Create(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	log.Warn("Operation Create is not supported on Service type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: kvp.Key,
+		Operation:  "Create",
+	}
+}
+
+func (c *serviceClient) Update(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	log.Warn("Operation Update is not supported on Service type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: kvp.Key,
+		Operation:  "Update",
+	}
+}
+
+func (c *serviceClient) Delete(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	log.Warn("Operation Delete is not supported on Service type")
+	return nil, cerrors.ErrorOperationNotSupported{
+		Identifier: key,
+		Operation:  "Delete",
+	}
+}
+
+func (c *serviceClient) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	log.Debug("Received Get request on Service type")
+	k := key.(model.ResourceKey)
+	r, err := c.clientSet.CoreV1().Services(k.Namespace).Get(k.Name, metav1.GetOptions{ResourceVersion: revision})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, key)
+	}
+	return serviceToKVPair(r), nil
+}
+
+func (c *serviceClient) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+	log.Debug("Received List request on Service type")
+	l := list.(model.ResourceListOptions)
+	kvps := []*model.KVPair{}
+
+	if l.Name != "" {
+		// The resource is fully qualified, so perform a Get instead.  If the entry does not
+		// exist then we just return an empty list.
+		kvp, err := c.Get(ctx, model.ResourceKey{Name: l.Name, Namespace: l.Namespace, Kind: model.KindKubernetesService}, revision)
+		if err != nil {
+			if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+				return nil, err
+			}
+			return &model.KVPairList{
+				KVPairs:  kvps,
+				Revision: revision,
+			}, nil
+		}
+		return &model.KVPairList{
+			KVPairs:  []*model.KVPair{kvp},
+			Revision: revision,
+		}, nil
+	}
+
+	rl, err := c.clientSet.CoreV1().Services(l.Namespace).List(metav1.ListOptions{ResourceVersion: revision})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, list)
+	}
+	for i := range rl.Items {
+		kvps = append(kvps, serviceToKVPair(&rl.Items[i]))
+	}
+	return &model.KVPairList{
+		KVPairs:  kvps,
+		Revision: rl.ResourceVersion,
+	}, nil
+}
+
+func (c *serviceClient) EnsureInitialized() error {
+	return nil
+}
+
+func (c *serviceClient) Watch(ctx context.Context, list model.ListInterface, revision string) (api.WatchInterface, error) {
+	l := list.(model.ResourceListOptions)
+	if len(l.Name) != 0 {
+		return nil, fmt.Errorf("cannot watch specific resource instance: %s", l.Name)
+	}
+
+	k8sWatch, err := c.clientSet.CoreV1().Services(l.Namespace).Watch(metav1.ListOptions{ResourceVersion: revision})
+	if err != nil {
+		return nil, K8sErrorToCalico(err, list)
+	}
+	converter := func(r Resource) (*model.KVPair, error) {
+		k8sRes, ok := r.(*kapiv1.Service)
+		if !ok {
+			return nil, errors.New("service conversion with incorrect k8s resource type")
+		}
+		return serviceToKVPair(k8sRes), nil
+	}
+	return newK8sWatcherConverter(ctx, "Service", converter, k8sWatch), nil
+}
+
+// serviceToKVPair wraps the Kubernetes Service in a KVPair.  The Kubernetes resource is
+// used as the value without conversion.
+func serviceToKVPair(r *kapiv1.Service) *model.KVPair {
+	return &model.KVPair{
+		Key: model.ResourceKey{
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Kind:      model.KindKubernetesService,
+		},
+		Value:    r,
+		Revision: r.ResourceVersion,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var (
+	matchBGPFilter = regexp.MustCompile("^/?calico/bgp/v1/filter/([^/]+)$")
+	typeBGPFilter  = reflect.TypeOf(BGPFilter{})
+)
+
+type BGPFilterKey struct {
+	Name string `json:"-" validate:"required,name"`
+}
+
+func (key BGPFilterKey) defaultPath() (string, error) {
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("/calico/bgp/v1/filter/%s", key.Name), nil
+}
+
+func (key BGPFilterKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key BGPFilterKey) defaultDeleteParentPaths() ([]string, error) {
+	return nil, nil
+}
+
+func (key BGPFilterKey) valueType() reflect.Type {
+	return typeBGPFilter
+}
+
+func (key BGPFilterKey) String() string {
+	return fmt.Sprintf("BGPFilter(name=%s)", key.Name)
+}
+
+type BGPFilterListOptions struct {
+	Name string
+}
+
+func (options BGPFilterListOptions) defaultPathRoot() string {
+	if options.Name == "" {
+		return "/calico/bgp/v1/filter"
+	}
+	return fmt.Sprintf("/calico/bgp/v1/filter/%s", options.Name)
+}
+
+func (options BGPFilterListOptions) KeyFromDefaultPath(path string) Key {
+	log.Debugf("Get BGPFilter key from %s", path)
+	r := matchBGPFilter.FindAllStringSubmatch(path, -1)
+	if len(r) != 1 {
+		log.Debugf("Didn't match regex")
+		return nil
+	}
+	name := r[0][1]
+	if options.Name != "" && name != options.Name {
+		log.Debugf("Didn't match name %s != %s", options.Name, name)
+		return nil
+	}
+	return BGPFilterKey{Name: name}
+}
+
+type BGPFilter struct {
+	// The ordered rules applied to the routes advertised to a peer.
+	Export []BGPFilterRule `json:"export,omitempty"`
+	// The ordered rules applied to the routes received from a peer.
+	Import []BGPFilterRule `json:"import,omitempty"`
+}
+
+type BGPFilterRule struct {
+	CIDR          net.IPNet `json:"cidr"`
+	MatchOperator string    `json:"match_operator"`
+	Action        string    `json:"action"`
+}
@@ -183,4 +183,21 @@ type BGPPeer struct {
 	// converts large uints to float e notation which breaks the BIRD
 	// configuration.
 	ASNum numorstring.ASNumber `json:"as_num,string"`
+
+	// PasswordSecretRef references the secret key containing the BGP password for the
+	// peering.  This is not written to the backend, it is resolved by the BGP syncer.
+	PasswordSecretRef *SecretKeyRef `json:"-"`
+
+	// Password is the resolved BGP password.  This is only filled in by the BGP syncer
+	// for the node that requires it.
+	Password string `json:"password,omitempty"`
+
+	// Filters are the names of the BGP filters applied to the peering, in order.
+	Filters []string `json:"filters,omitempty"`
+}
+
+// SecretKeyRef references a key of a secret.
+type SecretKeyRef struct {
+	Name string
+	Key  string
 }
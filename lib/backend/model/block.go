@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/net"
@@ -91,6 +92,10 @@ type AllocationBlock struct {
 	Unallocated    []int                 `json:"unallocated"`
 	Attributes     []AllocationAttribute `json:"attributes"`
 
+	// EmptySince is the time at which the block was first seen to be empty by IPAM empty
+	// block reclamation.  It is cleared whenever an address is assigned from the block.
+	EmptySince *time.Time `json:"emptySince,omitempty"`
+
 	// HostAffinity is deprecated in favor of Affinity.
 	// This is only to keep compatiblity with existing deployments.
 	// The data format should be `Affinity: host:hostname` (not `hostAffinity: hostname`).
@@ -100,4 +105,8 @@ type AllocationBlock struct {
 type AllocationAttribute struct {
 	AttrPrimary   *string           `json:"handle_id"`
 	AttrSecondary map[string]string `json:"secondary"`
+
+	// The host that the addresses were assigned for.  This is not set for addresses
+	// assigned by older versions of Calico.
+	Host string `json:"host,omitempty"`
 }
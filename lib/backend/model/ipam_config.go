@@ -44,6 +44,9 @@ func (key IPAMConfigKey) String() string {
 }
 
 type IPAMConfig struct {
-	StrictAffinity     bool `json:"strict_affinity,omitempty"`
-	AutoAllocateBlocks bool `json:"auto_allocate_blocks,omitempty"`
+	StrictAffinity        bool   `json:"strict_affinity,omitempty"`
+	AutoAllocateBlocks    bool   `json:"auto_allocate_blocks,omitempty"`
+	EmptyBlockIdleSeconds int    `json:"empty_block_idle_seconds,omitempty"`
+	MaxBlocksPerHost      int    `json:"max_blocks_per_host,omitempty"`
+	AllowBorrowing        string `json:"allow_borrowing,omitempty"`
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/ipip"
 	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/vxlan"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -92,10 +93,11 @@ func (options IPPoolListOptions) KeyFromDefaultPath(path string) Key {
 }
 
 type IPPool struct {
-	CIDR          net.IPNet `json:"cidr"`
-	IPIPInterface string    `json:"ipip"`
-	IPIPMode      ipip.Mode `json:"ipip_mode"`
-	Masquerade    bool      `json:"masquerade"`
-	IPAM          bool      `json:"ipam"`
-	Disabled      bool      `json:"disabled"`
+	CIDR          net.IPNet  `json:"cidr"`
+	IPIPInterface string     `json:"ipip"`
+	IPIPMode      ipip.Mode  `json:"ipip_mode"`
+	VXLANMode     vxlan.Mode `json:"vxlan_mode,omitempty"`
+	Masquerade    bool       `json:"masquerade"`
+	IPAM          bool       `json:"ipam"`
+	Disabled      bool       `json:"disabled"`
 }
@@ -202,8 +202,20 @@ func KeyFromDefaultPath(path string) Key {
 	} else if m := matchPolicy.FindStringSubmatch(path); m != nil {
 		log.Debugf("Path is a policy: %v", path)
 		return PolicyKey{
+			Tier: unescapeName(m[1]),
 			Name: unescapeName(m[2]),
 		}
+	} else if m := matchStagedPolicy.FindStringSubmatch(path); m != nil {
+		log.Debugf("Path is a staged policy: %v", path)
+		return StagedPolicyKey{
+			Tier: unescapeName(m[1]),
+			Name: unescapeName(m[2]),
+		}
+	} else if m := matchTier.FindStringSubmatch(path); m != nil {
+		log.Debugf("Path is a tier: %v", path)
+		return TierKey{
+			Name: unescapeName(m[1]),
+		}
 	} else if m := matchProfile.FindStringSubmatch(path); m != nil {
 		log.Debugf("Path is a profile: %v (%v)", path, m[2])
 		pk := ProfileKey{unescapeName(m[1])}
@@ -219,6 +231,9 @@ func KeyFromDefaultPath(path string) Key {
 			return ProfileLabelsKey{ProfileKey: pk}
 		}
 		return nil
+	} else if m := matchNetworkSet.FindStringSubmatch(path); m != nil {
+		log.Debugf("Path is a network set: %v", path)
+		return NetworkSetKey{Name: unescapeName(m[1])}
 	} else if m := matchHostIp.FindStringSubmatch(path); m != nil {
 		log.Debugf("Path is a host ID: %v", path)
 		return HostIPKey{Hostname: m[1]}
@@ -237,6 +252,12 @@ func KeyFromDefaultPath(path string) Key {
 	} else if m := matchHostConfig.FindStringSubmatch(path); m != nil {
 		log.Debugf("Path is a host config: %v", path)
 		return HostConfigKey{Hostname: m[1], Name: m[2]}
+	} else if m := matchBGPFilter.FindStringSubmatch(path); m != nil {
+		log.Debugf("Path is a BGP filter: %v", path)
+		return BGPFilterKey{Name: m[1]}
+	} else if m := matchSecret.FindStringSubmatch(path); m != nil {
+		log.Debugf("Path is a secret: %v", path)
+		return SecretKey{Name: m[1]}
 	} else if matchReadyFlag.MatchString(path) {
 		log.Debugf("Path is a ready flag: %v", path)
 		return ReadyFlagKey{}
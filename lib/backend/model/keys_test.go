@@ -49,7 +49,37 @@ var _ = DescribeTable(
 	Entry(
 		"policy with a /",
 		"/calico/v1/policy/tier/default/policy/biff%2fbop",
-		PolicyKey{Name: "biff/bop"},
+		PolicyKey{Tier: "default", Name: "biff/bop"},
+	),
+	Entry(
+		"policy in a non-default tier",
+		"/calico/v1/policy/tier/tier1/policy/biff",
+		PolicyKey{Tier: "tier1", Name: "biff"},
+	),
+	Entry(
+		"staged policy with a /",
+		"/calico/v1/policy/tier/tier1/stagedpolicy/biff%2fbop",
+		StagedPolicyKey{Tier: "tier1", Name: "biff/bop"},
+	),
+	Entry(
+		"tier",
+		"/calico/v1/policy/tier/tier1/metadata",
+		TierKey{Name: "tier1"},
+	),
+	Entry(
+		"network set with a /",
+		"/calico/v1/netset/ns1%2fnetset1",
+		NetworkSetKey{Name: "ns1/netset1"},
+	),
+	Entry(
+		"BGP filter",
+		"/calico/bgp/v1/filter/filter-1",
+		BGPFilterKey{Name: "filter-1"},
+	),
+	Entry(
+		"secret",
+		"/calico/secrets/v1/bgp-passwords",
+		SecretKey{Name: "bgp-passwords"},
 	),
 	Entry(
 		"workload with a /",
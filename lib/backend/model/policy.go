@@ -32,6 +32,9 @@ var (
 )
 
 type PolicyKey struct {
+	// The name of the tier containing the policy.  If empty, the policy is in the
+	// default tier.
+	Tier string `json:"-" validate:"omitempty,name"`
 	Name string `json:"-" validate:"required,name"`
 }
 
@@ -39,8 +42,8 @@ func (key PolicyKey) defaultPath() (string, error) {
 	if key.Name == "" {
 		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
 	}
-	e := fmt.Sprintf("/calico/v1/policy/tier/default/policy/%s",
-		escapeName(key.Name))
+	e := fmt.Sprintf("/calico/v1/policy/tier/%s/policy/%s",
+		escapeName(tierOrDefault(key.Tier)), escapeName(key.Name))
 	return e, nil
 }
 
@@ -57,15 +60,17 @@ func (key PolicyKey) valueType() reflect.Type {
 }
 
 func (key PolicyKey) String() string {
-	return fmt.Sprintf("Policy(name=%s)", key.Name)
+	return fmt.Sprintf("Policy(tier=%s, name=%s)", tierOrDefault(key.Tier), key.Name)
 }
 
 type PolicyListOptions struct {
+	// The name of the tier.  If empty, the default tier is listed.
+	Tier string
 	Name string
 }
 
 func (options PolicyListOptions) defaultPathRoot() string {
-	k := "/calico/v1/policy/tier/default/policy"
+	k := fmt.Sprintf("/calico/v1/policy/tier/%s/policy", escapeName(tierOrDefault(options.Tier)))
 	if options.Name == "" {
 		return k
 	}
@@ -80,12 +85,17 @@ func (options PolicyListOptions) KeyFromDefaultPath(path string) Key {
 		log.Debugf("Didn't match regex")
 		return nil
 	}
+	tier := unescapeName(r[0][1])
 	name := unescapeName(r[0][2])
+	if tier != tierOrDefault(options.Tier) {
+		log.Debugf("Didn't match tier %s != %s", tierOrDefault(options.Tier), tier)
+		return nil
+	}
 	if options.Name != "" && name != options.Name {
 		log.Debugf("Didn't match name %s != %s", options.Name, name)
 		return nil
 	}
-	return PolicyKey{Name: name}
+	return PolicyKey{Tier: tier, Name: name}
 }
 
 type Policy struct {
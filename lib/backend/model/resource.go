@@ -21,11 +21,19 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	kapiv1 "k8s.io/api/core/v1"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/namespace"
 )
 
+const (
+	// The Kubernetes Service and Endpoints resources.  These are only available when using
+	// the Kubernetes datastore, and the values are the Kubernetes resource types.
+	KindKubernetesService   = namespace.KindKubernetesService
+	KindKubernetesEndpoints = namespace.KindKubernetesEndpoints
+)
+
 // Name/type information about a single resource.
 type resourceInfo struct {
 	typeOf reflect.Type
@@ -58,6 +66,21 @@ func init() {
 		"bgppeers",
 		reflect.TypeOf(apiv2.BGPPeer{}),
 	)
+	registerResourceInfo(
+		apiv2.KindBGPFilter,
+		"bgpfilters",
+		reflect.TypeOf(apiv2.BGPFilter{}),
+	)
+	registerResourceInfo(
+		apiv2.KindIPReservation,
+		"ipreservations",
+		reflect.TypeOf(apiv2.IPReservation{}),
+	)
+	registerResourceInfo(
+		apiv2.KindNodeStatus,
+		"nodestatuses",
+		reflect.TypeOf(apiv2.NodeStatus{}),
+	)
 	registerResourceInfo(
 		apiv2.KindBGPConfiguration,
 		"bgpconfigurations",
@@ -78,6 +101,11 @@ func init() {
 		"globalnetworkpolicies",
 		reflect.TypeOf(apiv2.GlobalNetworkPolicy{}),
 	)
+	registerResourceInfo(
+		apiv2.KindStagedGlobalNetworkPolicy,
+		"stagedglobalnetworkpolicies",
+		reflect.TypeOf(apiv2.StagedGlobalNetworkPolicy{}),
+	)
 	registerResourceInfo(
 		apiv2.KindHostEndpoint,
 		"hostendpoints",
@@ -93,6 +121,16 @@ func init() {
 		"networkpolicies",
 		reflect.TypeOf(apiv2.NetworkPolicy{}),
 	)
+	registerResourceInfo(
+		apiv2.KindStagedNetworkPolicy,
+		"stagednetworkpolicies",
+		reflect.TypeOf(apiv2.StagedNetworkPolicy{}),
+	)
+	registerResourceInfo(
+		apiv2.KindNetworkSet,
+		"networksets",
+		reflect.TypeOf(apiv2.NetworkSet{}),
+	)
 	registerResourceInfo(
 		apiv2.KindNode,
 		"nodes",
@@ -103,11 +141,26 @@ func init() {
 		"profiles",
 		reflect.TypeOf(apiv2.Profile{}),
 	)
+	registerResourceInfo(
+		apiv2.KindTier,
+		"tiers",
+		reflect.TypeOf(apiv2.Tier{}),
+	)
 	registerResourceInfo(
 		apiv2.KindWorkloadEndpoint,
 		"workloadendpoints",
 		reflect.TypeOf(apiv2.WorkloadEndpoint{}),
 	)
+	registerResourceInfo(
+		KindKubernetesService,
+		"kubernetesservices",
+		reflect.TypeOf(kapiv1.Service{}),
+	)
+	registerResourceInfo(
+		KindKubernetesEndpoints,
+		"kubernetesendpoints",
+		reflect.TypeOf(kapiv1.Endpoints{}),
+	)
 }
 
 type ResourceKey struct {
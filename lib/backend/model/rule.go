@@ -61,7 +61,58 @@ type Rule struct {
 	NotDstNets     []*net.IPNet       `json:"!dst_nets,omitempty" validate:"omitempty"`
 	NotDstPorts    []numorstring.Port `json:"!dst_ports,omitempty" validate:"omitempty,dive"`
 
-	LogPrefix string `json:"log_prefix,omitempty" validate:"omitempty"`
+	HTTPMatch *HTTPMatch `json:"http,omitempty" validate:"omitempty"`
+
+	LogPrefix    string        `json:"log_prefix,omitempty" validate:"omitempty,logprefix"`
+	LogRateLimit *LogRateLimit `json:"log_rate_limit,omitempty" validate:"omitempty"`
+}
+
+// LogRateLimit limits the rate at which packets matching a rule are logged.  Rate is
+// the number of packets per Unit ("second" or "minute"), and Burst is the number of
+// packets that may be logged in excess of the rate before the limit is enforced.
+type LogRateLimit struct {
+	Rate  uint32 `json:"rate" validate:"gt=0"`
+	Unit  string `json:"unit" validate:"backendlograteunit"`
+	Burst uint32 `json:"burst,omitempty"`
+}
+
+func (l LogRateLimit) String() string {
+	if l.Burst > 0 {
+		return fmt.Sprintf("%d/%s burst %d", l.Rate, l.Unit, l.Burst)
+	}
+	return fmt.Sprintf("%d/%s", l.Rate, l.Unit)
+}
+
+// HTTPMatch contains the HTTP match criteria of a rule.  The Methods and Paths are
+// joined with AND; multiple methods or multiple paths are joined with OR.
+type HTTPMatch struct {
+	Methods []string   `json:"methods,omitempty" validate:"omitempty,dive,httpmethod"`
+	Paths   []HTTPPath `json:"paths,omitempty" validate:"omitempty,dive"`
+}
+
+// HTTPPath is an HTTP path to match, either exactly or as a prefix.
+type HTTPPath struct {
+	Exact  string `json:"exact,omitempty" validate:"omitempty,httppath"`
+	Prefix string `json:"prefix,omitempty" validate:"omitempty,httppath"`
+}
+
+func (m HTTPMatch) String() string {
+	parts := make([]string, 0)
+	if len(m.Methods) > 0 {
+		parts = append(parts, "methods", strings.Join(m.Methods, ","))
+	}
+	if len(m.Paths) > 0 {
+		paths := make([]string, len(m.Paths))
+		for ii, p := range m.Paths {
+			if p.Exact != "" {
+				paths[ii] = "exact:" + p.Exact
+			} else {
+				paths[ii] = "prefix:" + p.Prefix
+			}
+		}
+		parts = append(parts, "paths", strings.Join(paths, ","))
+	}
+	return strings.Join(parts, " ")
 }
 
 func combineNets(n *net.IPNet, nets []*net.IPNet) []*net.IPNet {
@@ -220,5 +271,18 @@ func (r Rule) String() string {
 		}
 	}
 
+	// HTTP attributes.
+	if r.HTTPMatch != nil {
+		parts = append(parts, "http", r.HTTPMatch.String())
+	}
+
+	// Log attributes.
+	if r.LogPrefix != "" {
+		parts = append(parts, "log-prefix", fmt.Sprintf("%#v", r.LogPrefix))
+	}
+	if r.LogRateLimit != nil {
+		parts = append(parts, "log-rate-limit", r.LogRateLimit.String())
+	}
+
 	return strings.Join(parts, " ")
 }
@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+var (
+	matchSecret = regexp.MustCompile("^/?calico/secrets/v1/([^/]+)$")
+	typeSecret  = reflect.TypeOf(map[string]string{})
+)
+
+// SecretKey is the key for a secret referenced by another resource, for example the
+// password of a BGPPeer.  The value is a map of the secret keys to the secret values.
+//
+// On etcdv3 the secrets are stored under a separate prefix so that read access may be
+// restricted to the components that require it.  On the Kubernetes datastore the secrets
+// are the Kubernetes Secrets in the Calico namespace that have the BGP password label, other
+// Secrets are not visible through this key.
+type SecretKey struct {
+	Name string `json:"-" validate:"required,name"`
+}
+
+func (key SecretKey) defaultPath() (string, error) {
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("/calico/secrets/v1/%s", key.Name), nil
+}
+
+func (key SecretKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key SecretKey) defaultDeleteParentPaths() ([]string, error) {
+	return nil, nil
+}
+
+func (key SecretKey) valueType() reflect.Type {
+	return typeSecret
+}
+
+func (key SecretKey) String() string {
+	return fmt.Sprintf("Secret(name=%s)", key.Name)
+}
+
+type SecretListOptions struct {
+	Name string
+}
+
+func (options SecretListOptions) defaultPathRoot() string {
+	if options.Name == "" {
+		return "/calico/secrets/v1"
+	}
+	return fmt.Sprintf("/calico/secrets/v1/%s", options.Name)
+}
+
+func (options SecretListOptions) KeyFromDefaultPath(path string) Key {
+	log.Debugf("Get Secret key from %s", path)
+	r := matchSecret.FindAllStringSubmatch(path, -1)
+	if len(r) != 1 {
+		log.Debugf("Didn't match regex")
+		return nil
+	}
+	name := r[0][1]
+	if options.Name != "" && name != options.Name {
+		log.Debugf("Didn't match name %s != %s", options.Name, name)
+		return nil
+	}
+	return SecretKey{Name: name}
+}
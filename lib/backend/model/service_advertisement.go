@@ -0,0 +1,69 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var (
+	typeServiceAdvertisement = reflect.TypeOf(ServiceAdvertisement{})
+)
+
+// ServiceAdvertisementKey is the key for a Kubernetes Service IP that should be advertised
+// over BGP by a specific node.  These are calculated by the BGP syncer and are not stored
+// in the datastore.
+type ServiceAdvertisementKey struct {
+	Nodename string    `json:"-" validate:"required,name"`
+	CIDR     net.IPNet `json:"-" validate:"required"`
+}
+
+func (key ServiceAdvertisementKey) defaultPath() (string, error) {
+	if key.CIDR.IP == nil {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "cidr"}
+	}
+	if key.Nodename == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "node"}
+	}
+	c := strings.Replace(key.CIDR.String(), "/", "-", 1)
+	e := fmt.Sprintf("/calico/bgp/v1/host/%s/svc_ip_v%d/%s", key.Nodename, key.CIDR.Version(), c)
+	return e, nil
+}
+
+func (key ServiceAdvertisementKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key ServiceAdvertisementKey) defaultDeleteParentPaths() ([]string, error) {
+	return nil, nil
+}
+
+func (key ServiceAdvertisementKey) valueType() reflect.Type {
+	return typeServiceAdvertisement
+}
+
+func (key ServiceAdvertisementKey) String() string {
+	return fmt.Sprintf("ServiceAdvertisement(node=%s, cidr=%s)", key.Nodename, key.CIDR)
+}
+
+type ServiceAdvertisement struct {
+	// CIDR is the Service IP to advertise.
+	CIDR net.IPNet `json:"cidr"`
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+var (
+	matchStagedPolicy = regexp.MustCompile("^/?calico/v1/policy/tier/([^/]+)/stagedpolicy/([^/]+)$")
+	typeStagedPolicy  = reflect.TypeOf(StagedPolicy{})
+)
+
+// StagedPolicyKey is the key of a staged policy.  Staged policies are kept separate from
+// enforced policies (which use a PolicyKey) so that they can be evaluated without being
+// enforced.
+type StagedPolicyKey struct {
+	// The name of the tier containing the policy.  If empty, the policy is in the
+	// default tier.
+	Tier string `json:"-" validate:"omitempty,name"`
+	Name string `json:"-" validate:"required,name"`
+}
+
+func (key StagedPolicyKey) defaultPath() (string, error) {
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	e := fmt.Sprintf("/calico/v1/policy/tier/%s/stagedpolicy/%s",
+		escapeName(tierOrDefault(key.Tier)), escapeName(key.Name))
+	return e, nil
+}
+
+func (key StagedPolicyKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key StagedPolicyKey) defaultDeleteParentPaths() ([]string, error) {
+	return nil, nil
+}
+
+func (key StagedPolicyKey) valueType() reflect.Type {
+	return typeStagedPolicy
+}
+
+func (key StagedPolicyKey) String() string {
+	return fmt.Sprintf("StagedPolicy(tier=%s, name=%s)", tierOrDefault(key.Tier), key.Name)
+}
+
+type StagedPolicyListOptions struct {
+	// The name of the tier.  If empty, the default tier is listed.
+	Tier string
+	Name string
+}
+
+func (options StagedPolicyListOptions) defaultPathRoot() string {
+	k := fmt.Sprintf("/calico/v1/policy/tier/%s/stagedpolicy", escapeName(tierOrDefault(options.Tier)))
+	if options.Name == "" {
+		return k
+	}
+	k = k + fmt.Sprintf("/%s", escapeName(options.Name))
+	return k
+}
+
+func (options StagedPolicyListOptions) KeyFromDefaultPath(path string) Key {
+	log.Debugf("Get StagedPolicy key from %s", path)
+	r := matchStagedPolicy.FindAllStringSubmatch(path, -1)
+	if len(r) != 1 {
+		log.Debugf("Didn't match regex")
+		return nil
+	}
+	tier := unescapeName(r[0][1])
+	name := unescapeName(r[0][2])
+	if tier != tierOrDefault(options.Tier) {
+		log.Debugf("Didn't match tier %s != %s", tierOrDefault(options.Tier), tier)
+		return nil
+	}
+	if options.Name != "" && name != options.Name {
+		log.Debugf("Didn't match name %s != %s", options.Name, name)
+		return nil
+	}
+	return StagedPolicyKey{Tier: tier, Name: name}
+}
+
+// StagedPolicy is the value of a staged policy.  It contains the policy that would be
+// enforced if the staged policy was promoted, and the staged action.  A staged action of
+// "delete" indicates that promoting the staged policy would delete the enforced policy.
+type StagedPolicy struct {
+	StagedAction string `json:"staged_action,omitempty"`
+	Policy
+}
+
+func (p StagedPolicy) String() string {
+	return fmt.Sprintf("staged_action:%v,%v", p.StagedAction, p.Policy.String())
+}
@@ -17,6 +17,7 @@ package bgpsyncer
 import (
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/k8s"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
 	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
@@ -25,7 +26,9 @@ import (
 // New creates a new BGP v1 Syncer.  Since only etcdv3 supports Watchers for all of
 // the required resource types, the WatcherSyncer will go into a polling loop for
 // KDD.  An optional node name may be supplied.  If set, the syncer only watches
-// the specified node rather than all nodes.
+// the specified node rather than all nodes.  BGP passwords are only resolved for the
+// peerings of the specified node.  On KDD, the syncer also calculates the Service IP
+// advertisements from the Kubernetes Services and Endpoints.
 func New(client api.Client, callbacks api.SyncerCallbacks, node string, watchAllNodes bool) api.Syncer {
 	// Create the set of ResourceTypes required for Felix.  Since the update processors
 	// also cache state, we need to create individual ones per syncer rather than create
@@ -57,11 +60,49 @@ func New(client api.Client, callbacks api.SyncerCallbacks, node string, watchAll
 			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindBGPPeer},
 			UpdateProcessor: updateprocessors.NewBGPPeerUpdateProcessor(),
 		},
+		{
+			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindBGPFilter},
+			UpdateProcessor: updateprocessors.NewBGPFilterUpdateProcessor(),
+		},
+		{
+			// The selector based BGPPeers are expanded using the labels and BGP addresses
+			// of all of the nodes, so we always need to watch the raw Node resources.  These
+			// are consumed by the BGPPeer expander and not passed on to the callbacks.
+			ListInterface: model.ResourceListOptions{Kind: apiv2.KindNode},
+		},
 		{
 			ListInterface: model.BlockAffinityListOptions{Host: node},
 		},
 	}
 
+	if node != "" {
+		// The BGP passwords are resolved from the secrets for the peerings of our node.
+		// The secrets are consumed by the password resolver and not passed on to the
+		// callbacks.
+		resourceTypes = append(resourceTypes, watchersyncer.ResourceType{
+			ListInterface: model.SecretListOptions{},
+		})
+	}
+
+	if _, ok := client.(*k8s.KubeClient); ok {
+		// The Service advertisements are calculated from the Kubernetes Services and
+		// Endpoints, which are only available on KDD.
+		resourceTypes = append(resourceTypes,
+			watchersyncer.ResourceType{
+				ListInterface: model.ResourceListOptions{Kind: model.KindKubernetesService},
+			},
+			watchersyncer.ResourceType{
+				ListInterface: model.ResourceListOptions{Kind: model.KindKubernetesEndpoints},
+			},
+		)
+	}
+
+	// Wrap the callbacks to resolve the BGP passwords, calculate the Service advertisements
+	// and expand the selector based BGPPeers.
+	callbacks = newBGPPasswordResolver(callbacks, client, node)
+	callbacks = newServiceAdvertiser(callbacks, node)
+	callbacks = newBGPPeerExpander(callbacks, node)
+
 	return watchersyncer.New(
 		client,
 		resourceTypes,
@@ -0,0 +1,240 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// secretGetter is the subset of the backend client used to look up secrets.
+type secretGetter interface {
+	Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error)
+}
+
+// newBGPPasswordResolver returns a SyncerCallbacks that wraps the supplied callbacks,
+// resolving the password secret references of the v1 BGPPeers.
+//
+// The password is only resolved for the peerings of the supplied node, i.e. the node
+// specific peerings for that node and the global peerings.  If no node name is supplied then
+// no passwords are resolved.  The secret reference is never passed on to the wrapped
+// callbacks.
+//
+// The secrets are watched by the syncer and consumed by the resolver, they are not passed
+// on to the wrapped callbacks.  Only the keys that are referenced by the local peerings are
+// cached, the rest of the secret data is discarded.  When a peering references a key that
+// is not cached, the secret is read using the supplied client.  When a secret changes, an
+// update is sent for each of the local peerings whose password has changed as a result.
+func newBGPPasswordResolver(callbacks api.SyncerCallbacks, client secretGetter, node string) *bgpPasswordResolver {
+	return &bgpPasswordResolver{
+		callbacks: callbacks,
+		client:    client,
+		node:      node,
+		secrets:   make(map[string]map[string]string),
+		peers:     make(map[string]*resolvedPeer),
+	}
+}
+
+type bgpPasswordResolver struct {
+	callbacks api.SyncerCallbacks
+	client    secretGetter
+	node      string
+
+	// The referenced keys of each referenced secret, indexed by secret name.  A referenced
+	// key that is not present in the secret is not included.
+	secrets map[string]map[string]string
+
+	// The local peerings that reference a secret, indexed by key.
+	peers map[string]*resolvedPeer
+}
+
+// resolvedPeer is a local peering that references a secret, along with the password that
+// was last sent for it.
+type resolvedPeer struct {
+	kvp      model.KVPair
+	password string
+}
+
+func (r *bgpPasswordResolver) OnStatusUpdated(status api.SyncStatus) {
+	r.callbacks.OnStatusUpdated(status)
+}
+
+func (r *bgpPasswordResolver) OnUpdates(updates []api.Update) {
+	filtered := make([]api.Update, 0, len(updates))
+	for _, u := range updates {
+		if k, ok := u.Key.(model.SecretKey); ok {
+			data, _ := u.Value.(map[string]string)
+			r.storeSecret(k.Name, data)
+			filtered = append(filtered, r.refreshPasswords(k.Name)...)
+			continue
+		}
+
+		peer, ok := u.Value.(*model.BGPPeer)
+		if !ok || peer.PasswordSecretRef == nil {
+			r.removePeer(u.Key)
+			filtered = append(filtered, u)
+			continue
+		}
+
+		// Take a copy of the peer so that we do not modify data owned by the caller.
+		resolved := *peer
+		resolved.PasswordSecretRef = nil
+		if r.isLocalPeering(u.Key) {
+			resolved.Password = r.addPeer(u.KVPair, peer.PasswordSecretRef)
+		} else {
+			r.removePeer(u.Key)
+		}
+		u.Value = &resolved
+		filtered = append(filtered, u)
+	}
+	if len(filtered) > 0 {
+		r.callbacks.OnUpdates(filtered)
+	}
+}
+
+// addPeer tracks a local peering that references a secret and returns its password.  If
+// the peering references a key that is not already cached, the secret is read so that the
+// key may be added to the cache.
+func (r *bgpPasswordResolver) addPeer(kvp model.KVPair, ref *model.SecretKeyRef) string {
+	loaded := r.referencedKeys(ref.Name)[ref.Key]
+	old, hadOld := r.peers[kvp.Key.String()]
+	p := &resolvedPeer{kvp: kvp}
+	r.peers[kvp.Key.String()] = p
+	if hadOld {
+		r.pruneSecret(old.kvp.Value.(*model.BGPPeer).PasswordSecretRef.Name)
+	}
+	if !loaded {
+		r.loadSecret(ref.Name)
+	}
+	p.password = r.lookupPassword(kvp.Key, ref)
+	return p.password
+}
+
+// removePeer stops tracking the peering.
+func (r *bgpPasswordResolver) removePeer(key model.Key) {
+	p, ok := r.peers[key.String()]
+	if !ok {
+		return
+	}
+	delete(r.peers, key.String())
+	r.pruneSecret(p.kvp.Value.(*model.BGPPeer).PasswordSecretRef.Name)
+}
+
+// pruneSecret removes the keys of the secret that are no longer referenced from the cache.
+func (r *bgpPasswordResolver) pruneSecret(name string) {
+	r.storeSecret(name, r.secrets[name])
+}
+
+// loadSecret reads the secret and stores its referenced keys in the cache.
+func (r *bgpPasswordResolver) loadSecret(name string) {
+	kvp, err := r.client.Get(context.Background(), model.SecretKey{Name: name}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.WithError(err).WithField("Secret", name).Warn("Unable to read secret for BGP password")
+		}
+		r.storeSecret(name, nil)
+		return
+	}
+	data, _ := kvp.Value.(map[string]string)
+	r.storeSecret(name, data)
+}
+
+// storeSecret stores the referenced keys of the secret in the cache.  A nil data map
+// indicates that the secret does not exist.
+func (r *bgpPasswordResolver) storeSecret(name string, data map[string]string) {
+	cached := make(map[string]string)
+	for k := range r.referencedKeys(name) {
+		if v, ok := data[k]; ok {
+			cached[k] = v
+		}
+	}
+	if len(cached) == 0 {
+		delete(r.secrets, name)
+		return
+	}
+	r.secrets[name] = cached
+}
+
+// referencedKeys returns the keys of the secret that are referenced by the local peerings.
+func (r *bgpPasswordResolver) referencedKeys(name string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, p := range r.peers {
+		ref := p.kvp.Value.(*model.BGPPeer).PasswordSecretRef
+		if ref.Name == name {
+			keys[ref.Key] = true
+		}
+	}
+	return keys
+}
+
+// refreshPasswords re-resolves the passwords of the local peerings that reference the
+// secret, and returns an update for each peering whose password has changed.
+func (r *bgpPasswordResolver) refreshPasswords(secret string) []api.Update {
+	var updates []api.Update
+	for _, p := range r.peers {
+		peer := p.kvp.Value.(*model.BGPPeer)
+		if peer.PasswordSecretRef.Name != secret {
+			continue
+		}
+		password := r.lookupPassword(p.kvp.Key, peer.PasswordSecretRef)
+		if password == p.password {
+			continue
+		}
+		log.WithField("Peer", p.kvp.Key).Info("BGP password has changed")
+		p.password = password
+		resolved := *peer
+		resolved.PasswordSecretRef = nil
+		resolved.Password = password
+		kvp := p.kvp
+		kvp.Value = &resolved
+		updates = append(updates, api.Update{
+			KVPair:     kvp,
+			UpdateType: api.UpdateTypeKVUpdated,
+		})
+	}
+	return updates
+}
+
+// isLocalPeering returns true if the peering is configured on our node.
+func (r *bgpPasswordResolver) isLocalPeering(key model.Key) bool {
+	if r.node == "" {
+		return false
+	}
+	switch k := key.(type) {
+	case model.GlobalBGPPeerKey:
+		return true
+	case model.NodeBGPPeerKey:
+		return k.Nodename == r.node
+	}
+	return false
+}
+
+// lookupPassword returns the password referenced by the secret key reference.  If the
+// password cannot be found, an empty password is returned and the failure is logged.
+func (r *bgpPasswordResolver) lookupPassword(key model.Key, ref *model.SecretKeyRef) string {
+	logCxt := log.WithFields(log.Fields{"Peer": key, "Secret": ref.Name, "Key": ref.Key})
+	password, ok := r.secrets[ref.Name][ref.Key]
+	if !ok {
+		logCxt.Warn("Unable to find BGP password in secret")
+		return ""
+	}
+	logCxt.Debug("Resolved BGP password")
+	return password
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// fakeSecrets is a secretGetter backed by a map of secrets.
+type fakeSecrets map[string]map[string]string
+
+func (f fakeSecrets) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	data, ok := f[key.(model.SecretKey).Name]
+	if !ok {
+		return nil, cerrors.ErrorResourceDoesNotExist{Identifier: key}
+	}
+	return &model.KVPair{Key: key, Value: data}, nil
+}
+
+func secretUpdate(name string, data map[string]string) api.Update {
+	u := api.Update{
+		KVPair:     model.KVPair{Key: model.SecretKey{Name: name}},
+		UpdateType: api.UpdateTypeKVUpdated,
+	}
+	if data != nil {
+		u.Value = data
+	} else {
+		u.UpdateType = api.UpdateTypeKVDeleted
+	}
+	return u
+}
+
+func peerUpdate(key model.Key, ref *model.SecretKeyRef) api.Update {
+	return api.Update{
+		KVPair: model.KVPair{
+			Key: key,
+			Value: &model.BGPPeer{
+				PeerIP:            net.MustParseIP("10.0.0.254"),
+				ASNum:             65001,
+				PasswordSecretRef: ref,
+			},
+		},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+var _ = Describe("BGP password resolver", func() {
+	secrets := secretUpdate("bgp-secrets", map[string]string{"tor1": "s3cret"})
+	ref := &model.SecretKeyRef{Name: "bgp-secrets", Key: "tor1"}
+	globalKey := model.GlobalBGPPeerKey{PeerIP: net.MustParseIP("10.0.0.254")}
+	node1Key := model.NodeBGPPeerKey{Nodename: "node1", PeerIP: net.MustParseIP("10.0.0.254")}
+	node2Key := model.NodeBGPPeerKey{Nodename: "node2", PeerIP: net.MustParseIP("10.0.0.254")}
+	var rec *recorder
+	var client fakeSecrets
+
+	password := func(key model.Key) string {
+		ExpectWithOffset(1, rec.state).To(HaveKey(key.String()))
+		peer := rec.state[key.String()].Value.(*model.BGPPeer)
+		ExpectWithOffset(1, peer.PasswordSecretRef).To(BeNil())
+		return peer.Password
+	}
+
+	BeforeEach(func() {
+		rec = &recorder{state: map[string]*model.KVPair{}}
+		client = fakeSecrets{"bgp-secrets": {"tor1": "s3cret"}}
+	})
+
+	It("should only resolve the passwords for the local node", func() {
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{
+			secrets,
+			peerUpdate(globalKey, ref),
+			peerUpdate(node1Key, ref),
+			peerUpdate(node2Key, ref),
+		})
+		Expect(password(globalKey)).To(Equal("s3cret"))
+		Expect(password(node1Key)).To(Equal("s3cret"))
+		Expect(password(node2Key)).To(Equal(""))
+	})
+
+	It("should not resolve any passwords if no node is specified", func() {
+		r := newBGPPasswordResolver(rec, client, "")
+		r.OnUpdates([]api.Update{
+			secrets,
+			peerUpdate(globalKey, ref),
+			peerUpdate(node1Key, ref),
+		})
+		Expect(password(globalKey)).To(Equal(""))
+		Expect(password(node1Key)).To(Equal(""))
+	})
+
+	It("should handle a missing secret or secret key", func() {
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{
+			secrets,
+			peerUpdate(globalKey, &model.SecretKeyRef{Name: "missing", Key: "tor1"}),
+			peerUpdate(node1Key, &model.SecretKeyRef{Name: "bgp-secrets", Key: "missing"}),
+		})
+		Expect(password(globalKey)).To(Equal(""))
+		Expect(password(node1Key)).To(Equal(""))
+	})
+
+	It("should not modify the original update values", func() {
+		r := newBGPPasswordResolver(rec, client, "node1")
+		u := peerUpdate(node1Key, ref)
+		original := u.Value.(*model.BGPPeer)
+		r.OnUpdates([]api.Update{secrets, u})
+		Expect(password(node1Key)).To(Equal("s3cret"))
+		Expect(original.PasswordSecretRef).To(Equal(ref))
+		Expect(original.Password).To(Equal(""))
+	})
+
+	It("should not pass on the secrets", func() {
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{secrets})
+		Expect(rec.state).To(BeEmpty())
+	})
+
+	It("should send an update when a password changes", func() {
+		delete(client, "bgp-secrets")
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{
+			peerUpdate(node1Key, ref),
+			peerUpdate(node2Key, ref),
+			peerUpdate(globalKey, &model.SecretKeyRef{Name: "other-secrets", Key: "tor1"}),
+		})
+		Expect(password(node1Key)).To(Equal(""))
+
+		By("adding the secret")
+		rec.updates = 0
+		r.OnUpdates([]api.Update{secrets})
+		Expect(rec.updates).To(Equal(1))
+		Expect(password(node1Key)).To(Equal("s3cret"))
+
+		By("changing a different key of the secret")
+		r.OnUpdates([]api.Update{secretUpdate("bgp-secrets", map[string]string{"tor1": "s3cret", "tor2": "other"})})
+		Expect(rec.updates).To(Equal(1))
+
+		By("changing the password")
+		r.OnUpdates([]api.Update{secretUpdate("bgp-secrets", map[string]string{"tor1": "changed"})})
+		Expect(rec.updates).To(Equal(2))
+		Expect(password(node1Key)).To(Equal("changed"))
+
+		By("deleting the secret")
+		r.OnUpdates([]api.Update{secretUpdate("bgp-secrets", nil)})
+		Expect(rec.updates).To(Equal(3))
+		Expect(password(node1Key)).To(Equal(""))
+		Expect(password(node2Key)).To(Equal(""))
+
+		By("deleting the peer before re-adding the secret")
+		r.OnUpdates([]api.Update{{KVPair: model.KVPair{Key: node1Key}, UpdateType: api.UpdateTypeKVDeleted}})
+		rec.updates = 0
+		r.OnUpdates([]api.Update{secrets})
+		Expect(rec.updates).To(Equal(0))
+	})
+
+	It("should read the secret when a peering references a key that is not cached", func() {
+		client["bgp-secrets"] = map[string]string{"tor1": "s3cret", "tor2": "other"}
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{secrets})
+		Expect(r.secrets).To(BeEmpty())
+
+		r.OnUpdates([]api.Update{peerUpdate(node1Key, ref)})
+		Expect(password(node1Key)).To(Equal("s3cret"))
+
+		r.OnUpdates([]api.Update{peerUpdate(globalKey, &model.SecretKeyRef{Name: "bgp-secrets", Key: "tor2"})})
+		Expect(password(globalKey)).To(Equal("other"))
+	})
+
+	It("should only cache the referenced keys", func() {
+		r := newBGPPasswordResolver(rec, client, "node1")
+		r.OnUpdates([]api.Update{
+			peerUpdate(node1Key, ref),
+			peerUpdate(node2Key, &model.SecretKeyRef{Name: "bgp-secrets", Key: "tor2"}),
+			secretUpdate("bgp-secrets", map[string]string{"tor1": "s3cret", "tor2": "other"}),
+			secretUpdate("other-secrets", map[string]string{"tor1": "other"}),
+		})
+		Expect(password(node1Key)).To(Equal("s3cret"))
+		Expect(r.secrets).To(Equal(map[string]map[string]string{"bgp-secrets": {"tor1": "s3cret"}}))
+
+		By("deleting the peer")
+		r.OnUpdates([]api.Update{{KVPair: model.KVPair{Key: node1Key}, UpdateType: api.UpdateTypeKVDeleted}})
+		Expect(r.secrets).To(BeEmpty())
+	})
+})
@@ -0,0 +1,362 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+// The AS number used for a selected peer node when neither the node nor the global BGP
+// configuration specifies one.
+const defaultASNumber = numorstring.ASNumber(64512)
+
+// newBGPPeerExpander returns a SyncerCallbacks that wraps the supplied callbacks, expanding
+// the selector based BGPPeer resources into the equivalent v1 per-node peerings.
+//
+// The BGPPeer update processor passes through the selector based BGPPeer resources in the
+// v2 format, and the syncer passes through the v2 Node resources.  These are consumed by the
+// expander and are not passed on to the wrapped callbacks.  When a selector based BGPPeer or
+// a Node changes, the peerings are recalculated for the nodes that may be affected by the
+// change, and only the changes in the peerings are sent to the wrapped callbacks.
+//
+// Explicit per-node peerings take precedence over the expanded peerings, so an expanded
+// peering is not sent for a node and peer IP that has an explicit peering.
+//
+// If a node name is supplied, the peerings are only calculated for that node.
+func newBGPPeerExpander(callbacks api.SyncerCallbacks, node string) *bgpPeerExpander {
+	return &bgpPeerExpander{
+		callbacks: callbacks,
+		node:      node,
+		nodes:     make(map[string]*apiv2.Node),
+		peers:     make(map[string]*expandedPeer),
+		asNumber:  defaultASNumber,
+		peerings:  make(map[string]map[string]*model.KVPair),
+		explicit:  make(map[string]bool),
+	}
+}
+
+type bgpPeerExpander struct {
+	callbacks api.SyncerCallbacks
+	node      string
+	nodes     map[string]*apiv2.Node
+	peers     map[string]*expandedPeer
+	asNumber  numorstring.ASNumber
+
+	// The expanded peerings, indexed by node name and then by the peering key.
+	peerings map[string]map[string]*model.KVPair
+
+	// The keys of the explicit per-node peerings.
+	explicit map[string]bool
+}
+
+// expandedPeer is a selector based BGPPeer along with its parsed selectors, so that the
+// selectors are only parsed when the BGPPeer changes.
+type expandedPeer struct {
+	peer         *apiv2.BGPPeer
+	nodeSelector selector.Selector
+	peerSelector selector.Selector
+	peerIP       cnet.IP
+	passwordRef  *model.SecretKeyRef
+}
+
+// newExpandedPeer parses the selectors and peer IP of the BGPPeer.  It returns nil if the
+// BGPPeer is not valid.
+func newExpandedPeer(peer *apiv2.BGPPeer) *expandedPeer {
+	logCxt := log.WithField("BGPPeer", peer.Name)
+	p := &expandedPeer{
+		peer:        peer,
+		passwordRef: updateprocessors.GetBGPPeerPasswordSecretRef(peer),
+	}
+	var err error
+	if peer.Spec.NodeSelector != "" {
+		if p.nodeSelector, err = selector.Parse(peer.Spec.NodeSelector); err != nil {
+			logCxt.WithError(err).Warn("Unable to parse node selector, ignoring BGPPeer")
+			return nil
+		}
+	}
+	if peer.Spec.PeerSelector != "" {
+		if p.peerSelector, err = selector.Parse(peer.Spec.PeerSelector); err != nil {
+			logCxt.WithError(err).Warn("Unable to parse peer selector, ignoring BGPPeer")
+			return nil
+		}
+		return p
+	}
+	ip := cnet.ParseIP(peer.Spec.PeerIP)
+	if ip == nil {
+		logCxt.WithField("PeerIP", peer.Spec.PeerIP).Warn("Unable to parse peer IP, ignoring BGPPeer")
+		return nil
+	}
+	p.peerIP = *ip
+	return p
+}
+
+// selects returns true if the peer selector of the BGPPeer selects the node.
+func (p *expandedPeer) selects(node *apiv2.Node) bool {
+	return p.peerSelector != nil && node != nil && node.Spec.BGP != nil && p.peerSelector.Evaluate(node.Labels)
+}
+
+func (e *bgpPeerExpander) OnStatusUpdated(status api.SyncStatus) {
+	e.callbacks.OnStatusUpdated(status)
+}
+
+func (e *bgpPeerExpander) OnUpdates(updates []api.Update) {
+	filtered := make([]api.Update, 0, len(updates))
+	dirty := make(map[string]bool)
+	for _, u := range updates {
+		switch k := u.Key.(type) {
+		case model.ResourceKey:
+			switch k.Kind {
+			case apiv2.KindNode:
+				old := e.nodes[k.Name]
+				n, _ := u.Value.(*apiv2.Node)
+				if n != nil {
+					e.nodes[k.Name] = n
+				} else {
+					delete(e.nodes, k.Name)
+				}
+				e.markNodeDirty(dirty, k.Name, old, n)
+				continue
+			case apiv2.KindBGPPeer:
+				if old, ok := e.peers[k.Name]; ok {
+					e.markPeerDirty(dirty, old)
+					delete(e.peers, k.Name)
+				}
+				if p, ok := u.Value.(*apiv2.BGPPeer); ok {
+					if ep := newExpandedPeer(p); ep != nil {
+						e.peers[k.Name] = ep
+						e.markPeerDirty(dirty, ep)
+					}
+				}
+				continue
+			}
+		case model.NodeBGPPeerKey:
+			// An explicit per-node peering, pass the update through but track the key so
+			// that an expanded peering does not replace it.
+			if u.Value != nil {
+				e.explicit[k.String()] = true
+			} else {
+				delete(e.explicit, k.String())
+			}
+			dirty[k.Nodename] = true
+		case model.GlobalBGPConfigKey:
+			// Track the default AS number, but still pass the update through.  This may
+			// affect the peerings of every node.
+			if k.Name == "as_num" {
+				e.asNumber = defaultASNumber
+				if s, ok := u.Value.(string); ok {
+					if asn, err := numorstring.ASNumberFromString(s); err == nil {
+						e.asNumber = asn
+					} else {
+						log.WithError(err).Warnf("Unable to parse default AS number: %s", s)
+					}
+				}
+				for name := range e.nodes {
+					dirty[name] = true
+				}
+			}
+		}
+		filtered = append(filtered, u)
+	}
+
+	filtered = append(filtered, e.recalculate(dirty)...)
+	if len(filtered) > 0 {
+		e.callbacks.OnUpdates(filtered)
+	}
+}
+
+// markNodeDirty marks the nodes whose peerings may be affected by a change to the node.
+// This is the node itself, along with the nodes that have a peer whose peer selector selects
+// either the old or the new version of the node.
+func (e *bgpPeerExpander) markNodeDirty(dirty map[string]bool, name string, old, new *apiv2.Node) {
+	dirty[name] = true
+	for _, p := range e.peers {
+		if p.selects(old) || p.selects(new) {
+			e.markPeerDirty(dirty, p)
+		}
+	}
+}
+
+// markPeerDirty marks the nodes that the peer applies to.
+func (e *bgpPeerExpander) markPeerDirty(dirty map[string]bool, p *expandedPeer) {
+	for name, node := range e.nodes {
+		if e.node != "" && name != e.node {
+			continue
+		}
+		if peerAppliesToNode(p.peer, p.nodeSelector, node) {
+			dirty[name] = true
+		}
+	}
+}
+
+// recalculate calculates the peerings of each of the dirty nodes, and returns the updates
+// required to move from the previously calculated peerings.
+func (e *bgpPeerExpander) recalculate(dirty map[string]bool) []api.Update {
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	// Process the peers in name order.  If multiple peers result in the same peering then
+	// the peer with the lowest alphanumeric name is used.
+	peerNames := make([]string, 0, len(e.peers))
+	for name := range e.peers {
+		peerNames = append(peerNames, name)
+	}
+	sort.Strings(peerNames)
+
+	var updates []api.Update
+	for nodeName := range dirty {
+		if e.node != "" && nodeName != e.node {
+			continue
+		}
+		peerings := e.calculateNodePeerings(nodeName, peerNames)
+		old := e.peerings[nodeName]
+		for k, kvp := range peerings {
+			oldKVP, ok := old[k]
+			if ok && peeringsEqual(oldKVP.Value.(*model.BGPPeer), kvp.Value.(*model.BGPPeer)) {
+				continue
+			}
+			updateType := api.UpdateTypeKVNew
+			if ok {
+				updateType = api.UpdateTypeKVUpdated
+			}
+			updates = append(updates, api.Update{
+				KVPair:     *kvp,
+				UpdateType: updateType,
+			})
+		}
+		for k, kvp := range old {
+			// If an explicit peering now has the same key then it has already replaced
+			// the expanded peering, so the delete must not be sent.
+			if _, ok := peerings[k]; ok || e.explicit[k] {
+				continue
+			}
+			updates = append(updates, api.Update{
+				KVPair:     model.KVPair{Key: kvp.Key},
+				UpdateType: api.UpdateTypeKVDeleted,
+			})
+		}
+		if len(peerings) > 0 {
+			e.peerings[nodeName] = peerings
+		} else {
+			delete(e.peerings, nodeName)
+		}
+	}
+	log.Debugf("Recalculated selector based BGP peerings for %d nodes, %d updates", len(dirty), len(updates))
+	return updates
+}
+
+// calculateNodePeerings calculates the expanded peerings for a node, excluding the peerings
+// that have an explicit peering with the same key.
+func (e *bgpPeerExpander) calculateNodePeerings(nodeName string, peerNames []string) map[string]*model.KVPair {
+	peerings := make(map[string]*model.KVPair)
+	node, ok := e.nodes[nodeName]
+	if !ok {
+		return peerings
+	}
+
+	for _, name := range peerNames {
+		p := e.peers[name]
+		if !peerAppliesToNode(p.peer, p.nodeSelector, node) {
+			continue
+		}
+
+		if p.peerSelector == nil {
+			// Explicit peer IP.
+			addPeering(peerings, nodeName, p.peerIP, p.peer.Spec.ASNumber, p.passwordRef, p.peer.Spec.Filters)
+			continue
+		}
+
+		// Peer with each of the selected nodes, excluding this node.
+		for peerName, peerNode := range e.nodes {
+			if peerName == nodeName || !p.selects(peerNode) {
+				continue
+			}
+			asn := e.asNumber
+			if peerNode.Spec.BGP.ASNumber != nil {
+				asn = *peerNode.Spec.BGP.ASNumber
+			}
+			for _, addr := range []string{peerNode.Spec.BGP.IPv4Address, peerNode.Spec.BGP.IPv6Address} {
+				if addr == "" {
+					continue
+				}
+				ip, _, err := cnet.ParseCIDROrIP(addr)
+				if err != nil {
+					log.WithError(err).WithField("Node", peerName).Warn("Unable to parse node BGP address")
+					continue
+				}
+				addPeering(peerings, nodeName, *ip, asn, p.passwordRef, p.peer.Spec.Filters)
+			}
+		}
+	}
+
+	for k := range peerings {
+		if e.explicit[k] {
+			delete(peerings, k)
+		}
+	}
+	return peerings
+}
+
+// peerAppliesToNode returns true if the peer should be configured on the node.
+func peerAppliesToNode(peer *apiv2.BGPPeer, nodeSelector selector.Selector, node *apiv2.Node) bool {
+	switch {
+	case peer.Spec.Node != "":
+		return peer.Spec.Node == node.Name
+	case nodeSelector != nil:
+		return nodeSelector.Evaluate(node.Labels)
+	default:
+		return true
+	}
+}
+
+// addPeering adds a peering to the set of peerings, unless a peering for the same node and
+// peer IP has already been added.
+func addPeering(peerings map[string]*model.KVPair, node string, ip cnet.IP, asn numorstring.ASNumber, passwordRef *model.SecretKeyRef, filters []string) {
+	key := model.NodeBGPPeerKey{Nodename: node, PeerIP: ip}
+	if _, ok := peerings[key.String()]; ok {
+		return
+	}
+	peerings[key.String()] = &model.KVPair{
+		Key:   key,
+		Value: &model.BGPPeer{PeerIP: ip, ASNum: asn, PasswordSecretRef: passwordRef, Filters: filters},
+	}
+}
+
+// peeringsEqual returns true if the two peerings for the same node and peer IP have the
+// same configuration.
+func peeringsEqual(a, b *model.BGPPeer) bool {
+	if a.ASNum != b.ASNum || len(a.Filters) != len(b.Filters) {
+		return false
+	}
+	for i := range a.Filters {
+		if a.Filters[i] != b.Filters[i] {
+			return false
+		}
+	}
+	if a.PasswordSecretRef == nil || b.PasswordSecretRef == nil {
+		return a.PasswordSecretRef == b.PasswordSecretRef
+	}
+	return *a.PasswordSecretRef == *b.PasswordSecretRef
+}
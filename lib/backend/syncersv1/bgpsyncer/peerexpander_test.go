@@ -0,0 +1,296 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+// recorder is a SyncerCallbacks that stores the current state of the updates it receives.
+type recorder struct {
+	status  api.SyncStatus
+	state   map[string]*model.KVPair
+	updates int
+}
+
+func (r *recorder) OnStatusUpdated(status api.SyncStatus) {
+	r.status = status
+}
+
+func (r *recorder) OnUpdates(updates []api.Update) {
+	r.updates += len(updates)
+	for _, u := range updates {
+		if u.Value == nil {
+			delete(r.state, u.Key.String())
+		} else {
+			kvp := u.KVPair
+			r.state[u.Key.String()] = &kvp
+		}
+	}
+}
+
+func newNodeUpdate(name string, labels map[string]string, ipv4 string, asn *numorstring.ASNumber) api.Update {
+	n := apiv2.NewNode()
+	n.Name = name
+	n.Labels = labels
+	n.Spec.BGP = &apiv2.NodeBGPSpec{IPv4Address: ipv4, ASNumber: asn}
+	return api.Update{
+		KVPair:     model.KVPair{Key: model.ResourceKey{Kind: apiv2.KindNode, Name: name}, Value: n},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func newPeerUpdate(name string, spec apiv2.BGPPeerSpec) api.Update {
+	p := apiv2.NewBGPPeer()
+	p.Name = name
+	p.Spec = spec
+	return api.Update{
+		KVPair:     model.KVPair{Key: model.ResourceKey{Kind: apiv2.KindBGPPeer, Name: name}, Value: p},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func peering(node, ip string, asn numorstring.ASNumber) *model.KVPair {
+	return &model.KVPair{
+		Key:   model.NodeBGPPeerKey{Nodename: node, PeerIP: net.MustParseIP(ip)},
+		Value: &model.BGPPeer{PeerIP: net.MustParseIP(ip), ASNum: asn},
+	}
+}
+
+func expectPeerings(r *recorder, expected ...*model.KVPair) {
+	peerings := map[string]*model.KVPair{}
+	for k, v := range r.state {
+		if _, ok := v.Key.(model.NodeBGPPeerKey); ok {
+			peerings[k] = v
+		}
+	}
+	Expect(peerings).To(HaveLen(len(expected)))
+	for _, e := range expected {
+		Expect(peerings).To(HaveKeyWithValue(e.Key.String(), e))
+	}
+}
+
+var _ = Describe("BGPPeer expander", func() {
+	var r *recorder
+	var e *bgpPeerExpander
+	asn := numorstring.ASNumber(65001)
+
+	BeforeEach(func() {
+		r = &recorder{state: map[string]*model.KVPair{}}
+		e = newBGPPeerExpander(r, "")
+	})
+
+	It("should pass through status and unrelated updates", func() {
+		e.OnStatusUpdated(api.InSync)
+		Expect(r.status).To(Equal(api.InSync))
+
+		e.OnUpdates([]api.Update{{
+			KVPair:     model.KVPair{Key: model.GlobalBGPConfigKey{Name: "loglevel"}, Value: "info"},
+			UpdateType: api.UpdateTypeKVNew,
+		}})
+		Expect(r.state).To(HaveLen(1))
+	})
+
+	It("should expand a route reflector topology and track node label changes", func() {
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("rr1", map[string]string{"rr": "true"}, "10.0.0.1/24", nil),
+			newNodeUpdate("node1", nil, "10.0.0.2/24", nil),
+			newNodeUpdate("node2", nil, "10.0.0.3", &asn),
+			newPeerUpdate("peer-to-rrs", apiv2.BGPPeerSpec{NodeSelector: "!has(rr)", PeerSelector: "has(rr)"}),
+			newPeerUpdate("rr-mesh", apiv2.BGPPeerSpec{NodeSelector: "has(rr)", PeerSelector: "has(rr)"}),
+		})
+
+		By("checking the raw resources are not passed through")
+		Expect(r.state).NotTo(HaveKey(model.ResourceKey{Kind: apiv2.KindNode, Name: "rr1"}.String()))
+
+		By("checking each client peers with the route reflector")
+		expectPeerings(r,
+			peering("node1", "10.0.0.1", 64512),
+			peering("node2", "10.0.0.1", 64512),
+		)
+
+		By("making node2 a route reflector")
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node2", map[string]string{"rr": "true"}, "10.0.0.3", &asn),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.1", 64512),
+			peering("node1", "10.0.0.3", asn),
+			peering("rr1", "10.0.0.3", asn),
+			peering("node2", "10.0.0.1", 64512),
+		)
+
+		By("changing the default AS number")
+		e.OnUpdates([]api.Update{{
+			KVPair:     model.KVPair{Key: model.GlobalBGPConfigKey{Name: "as_num"}, Value: "64000"},
+			UpdateType: api.UpdateTypeKVNew,
+		}})
+		expectPeerings(r,
+			peering("node1", "10.0.0.1", 64000),
+			peering("node1", "10.0.0.3", asn),
+			peering("rr1", "10.0.0.3", asn),
+			peering("node2", "10.0.0.1", 64000),
+		)
+
+		By("deleting the route reflector mesh peer and the first route reflector")
+		e.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: apiv2.KindBGPPeer, Name: "rr-mesh"}}, UpdateType: api.UpdateTypeKVDeleted},
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: apiv2.KindNode, Name: "rr1"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.3", asn),
+		)
+	})
+
+	It("should expand a node selector peer with an explicit peer IP", func() {
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node1", map[string]string{"rack": "rack1"}, "10.0.0.1/24", nil),
+			newNodeUpdate("node2", map[string]string{"rack": "rack2"}, "10.0.0.2/24", nil),
+			newPeerUpdate("tor1", apiv2.BGPPeerSpec{NodeSelector: "rack == 'rack1'", PeerIP: "10.0.0.254", ASNumber: asn}),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.254", asn),
+		)
+
+		By("moving node2 into rack1")
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node2", map[string]string{"rack": "rack1"}, "10.0.0.2/24", nil),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.254", asn),
+			peering("node2", "10.0.0.254", asn),
+		)
+	})
+
+	It("should only calculate peerings for the local node if specified", func() {
+		e = newBGPPeerExpander(r, "node1")
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("rr1", map[string]string{"rr": "true"}, "10.0.0.1/24", nil),
+			newNodeUpdate("node1", nil, "10.0.0.2/24", nil),
+			newNodeUpdate("node2", nil, "10.0.0.3/24", nil),
+			newPeerUpdate("peer-to-rrs", apiv2.BGPPeerSpec{PeerSelector: "has(rr)"}),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.1", 64512),
+		)
+	})
+
+	It("should send an update when the password of a peer changes", func() {
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node1", map[string]string{"rack": "rack1"}, "10.0.0.1/24", nil),
+			newPeerUpdate("tor1", apiv2.BGPPeerSpec{NodeSelector: "rack == 'rack1'", PeerIP: "10.0.0.254", ASNumber: asn}),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.254", asn),
+		)
+
+		e.OnUpdates([]api.Update{
+			newPeerUpdate("tor1", apiv2.BGPPeerSpec{
+				NodeSelector: "rack == 'rack1'",
+				PeerIP:       "10.0.0.254",
+				ASNumber:     asn,
+				Password: &apiv2.BGPPassword{
+					SecretKeyRef: &apiv2.SecretKeySelector{Name: "bgp-secrets", Key: "tor1"},
+				},
+			}),
+		})
+		p := peering("node1", "10.0.0.254", asn)
+		p.Value.(*model.BGPPeer).PasswordSecretRef = &model.SecretKeyRef{Name: "bgp-secrets", Key: "tor1"}
+		expectPeerings(r, p)
+	})
+
+	It("should only recalculate the peerings affected by a node change", func() {
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("rr1", map[string]string{"rr": "true"}, "10.0.0.1/24", nil),
+			newNodeUpdate("node1", nil, "10.0.0.2/24", nil),
+			newNodeUpdate("node2", nil, "10.0.0.3/24", nil),
+			newPeerUpdate("peer-to-rrs", apiv2.BGPPeerSpec{NodeSelector: "!has(rr)", PeerSelector: "has(rr)"}),
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.1", 64512),
+			peering("node2", "10.0.0.1", 64512),
+		)
+
+		By("changing the labels of a node that is not selected as a peer")
+		r.updates = 0
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node1", map[string]string{"zone": "a"}, "10.0.0.2/24", nil),
+		})
+		Expect(r.updates).To(Equal(0))
+
+		By("changing the address of the route reflector")
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("rr1", map[string]string{"rr": "true"}, "10.0.0.4/24", nil),
+		})
+		Expect(r.updates).To(Equal(4))
+		expectPeerings(r,
+			peering("node1", "10.0.0.4", 64512),
+			peering("node2", "10.0.0.4", 64512),
+		)
+	})
+
+	It("should not replace an explicit per-node peering with an expanded peering", func() {
+		explicitASN := numorstring.ASNumber(65002)
+		explicit := peering("node1", "10.0.0.254", explicitASN)
+		e.OnUpdates([]api.Update{
+			newNodeUpdate("node1", map[string]string{"rack": "rack1"}, "10.0.0.1/24", nil),
+			newNodeUpdate("node2", map[string]string{"rack": "rack1"}, "10.0.0.2/24", nil),
+			{KVPair: *explicit, UpdateType: api.UpdateTypeKVNew},
+			newPeerUpdate("tor1", apiv2.BGPPeerSpec{NodeSelector: "rack == 'rack1'", PeerIP: "10.0.0.254", ASNumber: asn}),
+		})
+		expectPeerings(r,
+			explicit,
+			peering("node2", "10.0.0.254", asn),
+		)
+
+		By("deleting the selector based peer")
+		e.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: apiv2.KindBGPPeer, Name: "tor1"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectPeerings(r, explicit)
+
+		By("re-adding the selector based peer and deleting the explicit peer")
+		e.OnUpdates([]api.Update{
+			newPeerUpdate("tor1", apiv2.BGPPeerSpec{NodeSelector: "rack == 'rack1'", PeerIP: "10.0.0.254", ASNumber: asn}),
+		})
+		expectPeerings(r,
+			explicit,
+			peering("node2", "10.0.0.254", asn),
+		)
+		e.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: explicit.Key}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectPeerings(r,
+			peering("node1", "10.0.0.254", asn),
+			peering("node2", "10.0.0.254", asn),
+		)
+
+		By("re-adding the explicit peer")
+		e.OnUpdates([]api.Update{
+			{KVPair: *explicit, UpdateType: api.UpdateTypeKVNew},
+		})
+		expectPeerings(r,
+			explicit,
+			peering("node2", "10.0.0.254", asn),
+		)
+	})
+})
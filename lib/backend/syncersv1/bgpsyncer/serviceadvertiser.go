@@ -0,0 +1,282 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	kapiv1 "k8s.io/api/core/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// newServiceAdvertiser returns a SyncerCallbacks that wraps the supplied callbacks,
+// calculating the per-node Service IP advertisements from the Kubernetes Services and
+// Endpoints.
+//
+// The Service cluster and external IP ranges configured in the global BGP configuration
+// are advertised by every node.  In addition, the cluster and external IPs of a Service with
+// an external traffic policy of Local are advertised individually by each node that has a
+// ready endpoint for the Service, so that traffic is only attracted to those nodes.  Only
+// IPs within the configured ranges are advertised.
+//
+// The Kubernetes Services and Endpoints are consumed by the advertiser and are not passed
+// on to the wrapped callbacks.  If a node name is supplied, the advertisements are only
+// calculated for that node.  When a Service or its Endpoints change, only the advertisements
+// of that Service are recalculated.  All of the Services are recalculated when the configured
+// ranges change.
+func newServiceAdvertiser(callbacks api.SyncerCallbacks, node string) *serviceAdvertiser {
+	return &serviceAdvertiser{
+		callbacks:      callbacks,
+		node:           node,
+		services:       make(map[string]*kapiv1.Service),
+		endpoints:      make(map[string]*kapiv1.Endpoints),
+		advertisements: make(map[string]map[string]model.ServiceAdvertisementKey),
+		refCounts:      make(map[string]int),
+	}
+}
+
+type serviceAdvertiser struct {
+	callbacks     api.SyncerCallbacks
+	node          string
+	services      map[string]*kapiv1.Service
+	endpoints     map[string]*kapiv1.Endpoints
+	clusterCIDRs  []cnet.IPNet
+	externalCIDRs []cnet.IPNet
+
+	// The advertisements of each Service, indexed by Service key and then by advertisement
+	// key.
+	advertisements map[string]map[string]model.ServiceAdvertisementKey
+
+	// The number of Services that require each advertisement, indexed by advertisement key.
+	// Several Services may share an IP, so an advertisement is only withdrawn once no Service
+	// requires it.
+	refCounts map[string]int
+}
+
+func (a *serviceAdvertiser) OnStatusUpdated(status api.SyncStatus) {
+	a.callbacks.OnStatusUpdated(status)
+}
+
+func (a *serviceAdvertiser) OnUpdates(updates []api.Update) {
+	filtered := make([]api.Update, 0, len(updates))
+	dirty := make(map[string]bool)
+	rangesChanged := false
+	for _, u := range updates {
+		switch k := u.Key.(type) {
+		case model.ResourceKey:
+			switch k.Kind {
+			case model.KindKubernetesService:
+				if s, ok := u.Value.(*kapiv1.Service); ok {
+					a.services[k.String()] = s
+				} else {
+					delete(a.services, k.String())
+				}
+				dirty[k.String()] = true
+				continue
+			case model.KindKubernetesEndpoints:
+				// The Endpoints have the same namespace and name as the Service, so key off
+				// the equivalent Service key.
+				sk := model.ResourceKey{Kind: model.KindKubernetesService, Namespace: k.Namespace, Name: k.Name}
+				if e, ok := u.Value.(*kapiv1.Endpoints); ok {
+					a.endpoints[sk.String()] = e
+				} else {
+					delete(a.endpoints, sk.String())
+				}
+				dirty[sk.String()] = true
+				continue
+			}
+		case model.GlobalBGPConfigKey:
+			// Track the configured Service IP ranges, but still pass the update through.
+			switch k.Name {
+			case "svc_cluster_ips":
+				a.clusterCIDRs = parseCIDRs(u.Value)
+				rangesChanged = true
+			case "svc_external_ips":
+				a.externalCIDRs = parseCIDRs(u.Value)
+				rangesChanged = true
+			}
+		}
+		filtered = append(filtered, u)
+	}
+
+	if rangesChanged {
+		// The configured ranges apply to every Service.
+		for key := range a.services {
+			dirty[key] = true
+		}
+	}
+	if len(dirty) > 0 {
+		filtered = append(filtered, a.recalculate(dirty)...)
+	}
+	if len(filtered) > 0 {
+		a.callbacks.OnUpdates(filtered)
+	}
+}
+
+// recalculate recalculates the advertisements of the dirty Services, and returns the updates
+// required to move from the previously calculated advertisements.
+func (a *serviceAdvertiser) recalculate(dirty map[string]bool) []api.Update {
+	// Track the advertisements whose reference count changes, along with whether each was
+	// advertised beforehand.
+	before := make(map[string]model.ServiceAdvertisementKey)
+	wasAdvertised := make(map[string]bool)
+	adjust := func(k string, ak model.ServiceAdvertisementKey, delta int) {
+		if _, ok := before[k]; !ok {
+			before[k] = ak
+			wasAdvertised[k] = a.refCounts[k] > 0
+		}
+		a.refCounts[k] += delta
+		if a.refCounts[k] == 0 {
+			delete(a.refCounts, k)
+		}
+	}
+
+	for key := range dirty {
+		old := a.advertisements[key]
+		current := a.calculateService(key)
+		for k, ak := range current {
+			if _, ok := old[k]; !ok {
+				adjust(k, ak, 1)
+			}
+		}
+		for k, ak := range old {
+			if _, ok := current[k]; !ok {
+				adjust(k, ak, -1)
+			}
+		}
+		if len(current) > 0 {
+			a.advertisements[key] = current
+		} else {
+			delete(a.advertisements, key)
+		}
+	}
+
+	// The value is fully determined by the key, so there are no modified entries.
+	var updates []api.Update
+	for k, ak := range before {
+		advertised := a.refCounts[k] > 0
+		switch {
+		case advertised && !wasAdvertised[k]:
+			updates = append(updates, api.Update{
+				KVPair:     model.KVPair{Key: ak, Value: &model.ServiceAdvertisement{CIDR: ak.CIDR}},
+				UpdateType: api.UpdateTypeKVNew,
+			})
+		case !advertised && wasAdvertised[k]:
+			updates = append(updates, api.Update{
+				KVPair:     model.KVPair{Key: ak},
+				UpdateType: api.UpdateTypeKVDeleted,
+			})
+		}
+	}
+	log.Debugf("Recalculated %d Services, %d updates", len(dirty), len(updates))
+	return updates
+}
+
+// calculateService returns the advertisements required by the Service with the given key,
+// indexed by advertisement key.
+func (a *serviceAdvertiser) calculateService(key string) map[string]model.ServiceAdvertisementKey {
+	svc, ok := a.services[key]
+	if !ok || svc.Spec.ExternalTrafficPolicy != kapiv1.ServiceExternalTrafficPolicyTypeLocal {
+		// The Service IPs are reachable from all nodes, so these are covered by the
+		// ranges advertised by every node.
+		return nil
+	}
+	ips := a.serviceIPs(svc)
+	if len(ips) == 0 {
+		return nil
+	}
+	advertisements := make(map[string]model.ServiceAdvertisementKey)
+	for _, node := range localEndpointNodes(a.endpoints[key]) {
+		if a.node != "" && node != a.node {
+			continue
+		}
+		for _, ip := range ips {
+			k := model.ServiceAdvertisementKey{Nodename: node, CIDR: *ip.Network()}
+			advertisements[k.String()] = k
+		}
+	}
+	return advertisements
+}
+
+// serviceIPs returns the cluster and external IPs of the Service that are within the
+// configured Service IP ranges.
+func (a *serviceAdvertiser) serviceIPs(svc *kapiv1.Service) []cnet.IP {
+	var ips []cnet.IP
+	if ip := cnet.ParseIP(svc.Spec.ClusterIP); ip != nil && cidrsContain(a.clusterCIDRs, *ip) {
+		ips = append(ips, *ip)
+	}
+	external := append([]string{}, svc.Spec.ExternalIPs...)
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		external = append(external, ingress.IP)
+	}
+	for _, e := range external {
+		if ip := cnet.ParseIP(e); ip != nil && cidrsContain(a.externalCIDRs, *ip) {
+			ips = append(ips, *ip)
+		}
+	}
+	return ips
+}
+
+// localEndpointNodes returns the names of the nodes that have a ready endpoint in the
+// Endpoints.
+func localEndpointNodes(endpoints *kapiv1.Endpoints) []string {
+	if endpoints == nil {
+		return nil
+	}
+	var nodes []string
+	seen := map[string]bool{}
+	for _, subset := range endpoints.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.NodeName == nil || seen[*addr.NodeName] {
+				continue
+			}
+			seen[*addr.NodeName] = true
+			nodes = append(nodes, *addr.NodeName)
+		}
+	}
+	return nodes
+}
+
+// parseCIDRs parses the comma separated list of CIDRs in the config value.
+func parseCIDRs(value interface{}) []cnet.IPNet {
+	s, _ := value.(string)
+	var cidrs []cnet.IPNet
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c == "" {
+			continue
+		}
+		_, cidr, err := cnet.ParseCIDR(c)
+		if err != nil {
+			log.WithError(err).Warnf("Unable to parse Service IP range: %s", c)
+			continue
+		}
+		cidrs = append(cidrs, *cidr)
+	}
+	return cidrs
+}
+
+// cidrsContain returns true if the IP is within one of the CIDRs.
+func cidrsContain(cidrs []cnet.IPNet, ip cnet.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip.IP) {
+			return true
+		}
+	}
+	return false
+}
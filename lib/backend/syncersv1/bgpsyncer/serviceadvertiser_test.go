@@ -0,0 +1,217 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgpsyncer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+func newServiceUpdate(name string, policy kapiv1.ServiceExternalTrafficPolicyType, clusterIP string, externalIPs ...string) api.Update {
+	svc := &kapiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: kapiv1.ServiceSpec{
+			ClusterIP:             clusterIP,
+			ExternalIPs:           externalIPs,
+			ExternalTrafficPolicy: policy,
+		},
+	}
+	return api.Update{
+		KVPair: model.KVPair{
+			Key:   model.ResourceKey{Kind: model.KindKubernetesService, Namespace: "default", Name: name},
+			Value: svc,
+		},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func newEndpointsUpdate(name string, nodes ...string) api.Update {
+	subset := kapiv1.EndpointSubset{}
+	for i := range nodes {
+		subset.Addresses = append(subset.Addresses, kapiv1.EndpointAddress{NodeName: &nodes[i]})
+	}
+	eps := &kapiv1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Subsets:    []kapiv1.EndpointSubset{subset},
+	}
+	return api.Update{
+		KVPair: model.KVPair{
+			Key:   model.ResourceKey{Kind: model.KindKubernetesEndpoints, Namespace: "default", Name: name},
+			Value: eps,
+		},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func newSvcConfigUpdate(name, value string) api.Update {
+	return api.Update{
+		KVPair:     model.KVPair{Key: model.GlobalBGPConfigKey{Name: name}, Value: value},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func advertisement(node, cidr string) *model.KVPair {
+	c := net.MustParseCIDR(cidr)
+	return &model.KVPair{
+		Key:   model.ServiceAdvertisementKey{Nodename: node, CIDR: c},
+		Value: &model.ServiceAdvertisement{CIDR: c},
+	}
+}
+
+func expectAdvertisements(r *recorder, expected ...*model.KVPair) {
+	advertisements := map[string]*model.KVPair{}
+	for k, v := range r.state {
+		if _, ok := v.Key.(model.ServiceAdvertisementKey); ok {
+			advertisements[k] = v
+		}
+	}
+	Expect(advertisements).To(HaveLen(len(expected)))
+	for _, e := range expected {
+		Expect(advertisements).To(HaveKeyWithValue(e.Key.String(), e))
+	}
+}
+
+var _ = Describe("Service advertiser", func() {
+	var r *recorder
+	var a *serviceAdvertiser
+	local := kapiv1.ServiceExternalTrafficPolicyTypeLocal
+	cluster := kapiv1.ServiceExternalTrafficPolicyTypeCluster
+
+	BeforeEach(func() {
+		r = &recorder{state: map[string]*model.KVPair{}}
+		a = newServiceAdvertiser(r, "")
+	})
+
+	It("should advertise local Service IPs from the nodes with endpoints", func() {
+		a.OnUpdates([]api.Update{
+			newSvcConfigUpdate("svc_cluster_ips", "10.96.0.0/12"),
+			newSvcConfigUpdate("svc_external_ips", "172.16.10.0/24"),
+			newServiceUpdate("local", local, "10.96.0.10", "172.16.10.1", "192.168.0.1"),
+			newServiceUpdate("cluster", cluster, "10.96.0.11", "172.16.10.2"),
+			newEndpointsUpdate("local", "node1", "node2", "node1"),
+			newEndpointsUpdate("cluster", "node1"),
+		})
+
+		By("checking the config is passed through and the Kubernetes resources are not")
+		Expect(r.state).To(HaveKey(model.GlobalBGPConfigKey{Name: "svc_cluster_ips"}.String()))
+		Expect(r.state).NotTo(HaveKey(model.ResourceKey{Kind: model.KindKubernetesService, Namespace: "default", Name: "local"}.String()))
+
+		By("checking only the IPs within the configured ranges are advertised")
+		expectAdvertisements(r,
+			advertisement("node1", "10.96.0.10/32"),
+			advertisement("node1", "172.16.10.1/32"),
+			advertisement("node2", "10.96.0.10/32"),
+			advertisement("node2", "172.16.10.1/32"),
+		)
+
+		By("moving the endpoints to node3")
+		a.OnUpdates([]api.Update{
+			newEndpointsUpdate("local", "node3"),
+		})
+		expectAdvertisements(r,
+			advertisement("node3", "10.96.0.10/32"),
+			advertisement("node3", "172.16.10.1/32"),
+		)
+
+		By("removing the external IP range")
+		a.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.GlobalBGPConfigKey{Name: "svc_external_ips"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectAdvertisements(r,
+			advertisement("node3", "10.96.0.10/32"),
+		)
+
+		By("deleting the Service")
+		a.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: model.KindKubernetesService, Namespace: "default", Name: "local"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectAdvertisements(r)
+	})
+
+	It("should only calculate advertisements for the local node if specified", func() {
+		a = newServiceAdvertiser(r, "node2")
+		a.OnUpdates([]api.Update{
+			newSvcConfigUpdate("svc_cluster_ips", "10.96.0.0/12,fd00:96::/108"),
+			newServiceUpdate("local", local, "fd00:96::10"),
+			newEndpointsUpdate("local", "node1", "node2"),
+		})
+		expectAdvertisements(r,
+			advertisement("node2", "fd00:96::10/128"),
+		)
+	})
+
+	It("should only recalculate the Service whose Endpoints changed", func() {
+		svcB := newServiceUpdate("b", local, "10.96.0.11")
+		a.OnUpdates([]api.Update{
+			newSvcConfigUpdate("svc_cluster_ips", "10.96.0.0/12"),
+			newServiceUpdate("a", local, "10.96.0.10"),
+			svcB,
+			newEndpointsUpdate("a", "node1"),
+			newEndpointsUpdate("b", "node1"),
+		})
+		expectAdvertisements(r,
+			advertisement("node1", "10.96.0.10/32"),
+			advertisement("node1", "10.96.0.11/32"),
+		)
+
+		By("changing the cached Service b without notifying the advertiser")
+		svcB.Value.(*kapiv1.Service).Spec.ExternalTrafficPolicy = cluster
+
+		By("moving the endpoints of Service a")
+		r.updates = 0
+		a.OnUpdates([]api.Update{
+			newEndpointsUpdate("a", "node2"),
+		})
+		Expect(r.updates).To(Equal(2))
+		expectAdvertisements(r,
+			advertisement("node2", "10.96.0.10/32"),
+			advertisement("node1", "10.96.0.11/32"),
+		)
+	})
+
+	It("should keep an advertisement that is still required by another Service", func() {
+		a.OnUpdates([]api.Update{
+			newSvcConfigUpdate("svc_external_ips", "172.16.10.0/24"),
+			newServiceUpdate("a", local, "", "172.16.10.1"),
+			newServiceUpdate("b", local, "", "172.16.10.1"),
+			newEndpointsUpdate("a", "node1"),
+			newEndpointsUpdate("b", "node1"),
+		})
+		expectAdvertisements(r,
+			advertisement("node1", "172.16.10.1/32"),
+		)
+
+		By("deleting one of the Services")
+		a.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: model.KindKubernetesService, Namespace: "default", Name: "a"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectAdvertisements(r,
+			advertisement("node1", "172.16.10.1/32"),
+		)
+
+		By("deleting the other Service")
+		a.OnUpdates([]api.Update{
+			{KVPair: model.KVPair{Key: model.ResourceKey{Kind: model.KindKubernetesService, Namespace: "default", Name: "b"}}, UpdateType: api.UpdateTypeKVDeleted},
+		})
+		expectAdvertisements(r)
+	})
+})
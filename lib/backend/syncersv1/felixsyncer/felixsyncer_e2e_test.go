@@ -251,6 +251,34 @@ var _ = testutils.E2eDatastoreDescribe("Felix syncer tests", testutils.Datastore
 				})
 			}
 
+			By("Creating a StagedGlobalNetworkPolicy")
+			sgnp, err := c.StagedGlobalNetworkPolicies().Create(
+				ctx,
+				&apiv2.StagedGlobalNetworkPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "staged-policy-1"},
+					Spec: apiv2.StagedGlobalNetworkPolicySpec{
+						Selector: "has(label1)",
+						Types:    []apiv2.PolicyType{apiv2.PolicyTypeIngress},
+					},
+				},
+				options.SetOptions{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			// The staged policy is sent with a staged policy key rather than a policy key ( +1 )
+			expectedCacheSize += 1
+			syncTester.ExpectCacheSize(expectedCacheSize)
+			syncTester.ExpectData(model.KVPair{
+				Key: model.StagedPolicyKey{Tier: "default", Name: "staged-policy-1"},
+				Value: &model.StagedPolicy{
+					StagedAction: "set",
+					Policy: model.Policy{
+						Selector: "has(label1)",
+						Types:    []string{"ingress"},
+					},
+				},
+				Revision: sgnp.ResourceVersion,
+			})
+
 			By("Starting a new syncer and verifying that all current entries are returned before sync status")
 			// We need to create a new syncTester and syncer.
 			current := syncTester.GetCacheEntries()
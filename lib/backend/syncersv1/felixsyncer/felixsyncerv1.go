@@ -62,6 +62,22 @@ func New(client api.Client, callbacks api.SyncerCallbacks, datastoreType apiconf
 			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindNetworkPolicy},
 			UpdateProcessor: updateprocessors.NewNetworkPolicyUpdateProcessor(),
 		},
+		{
+			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindStagedGlobalNetworkPolicy},
+			UpdateProcessor: updateprocessors.NewStagedGlobalNetworkPolicyUpdateProcessor(),
+		},
+		{
+			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindStagedNetworkPolicy},
+			UpdateProcessor: updateprocessors.NewStagedNetworkPolicyUpdateProcessor(),
+		},
+		{
+			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindNetworkSet},
+			UpdateProcessor: updateprocessors.NewNetworkSetUpdateProcessor(),
+		},
+		{
+			ListInterface:   model.ResourceListOptions{Kind: apiv2.KindTier},
+			UpdateProcessor: updateprocessors.NewTierUpdateProcessor(),
+		},
 	}
 
 	if datastoreType != apiconfig.Kubernetes {
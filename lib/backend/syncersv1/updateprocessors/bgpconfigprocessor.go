@@ -34,8 +34,12 @@ func NewBGPConfigUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
 		func(node, name string) model.Key { return model.NodeBGPConfigKey{Nodename: node, Name: name} },
 		func(name string) model.Key { return model.GlobalBGPConfigKey{Name: name} },
 		map[string]ValueToStringFn{
-			"loglevel":  logLevelStringifier,
-			"node_mesh": nodeMeshStringifier,
+			"loglevel":              logLevelStringifier,
+			"node_mesh":             nodeMeshStringifier,
+			"svc_cluster_ips":       svcIPBlocksStringifier,
+			"svc_external_ips":      svcIPBlocksStringifier,
+			"communities":           communitiesStringifier,
+			"prefix_advertisements": prefixAdvertisementsStringifier,
 		},
 	)
 }
@@ -66,3 +70,54 @@ var nodeMeshStringifier = func(value interface{}) string {
 type nodeToNodeMesh struct {
 	Enabled bool `json:"enabled"`
 }
+
+// The service cluster and external IP CIDR blocks are written as a comma separated list of
+// CIDRs.
+var svcIPBlocksStringifier = func(value interface{}) string {
+	var cidrs []string
+	switch blocks := value.(type) {
+	case []apiv2.ServiceClusterIPBlock:
+		for _, b := range blocks {
+			cidrs = append(cidrs, b.CIDR)
+		}
+	case []apiv2.ServiceExternalIPBlock:
+		for _, b := range blocks {
+			cidrs = append(cidrs, b.CIDR)
+		}
+	}
+	return strings.Join(cidrs, ",")
+}
+
+// The named communities are written as a JSON object mapping the community name to the
+// community value.
+var communitiesStringifier = func(value interface{}) string {
+	communities := value.([]apiv2.Community)
+	m := make(map[string]string, len(communities))
+	for _, c := range communities {
+		m[c.Name] = c.Value
+	}
+	d, err := json.Marshal(m)
+	cerrors.FatalIfErrored(err)
+	return string(d)
+}
+
+// The prefix advertisements are written as a JSON list of the CIDRs and the communities to
+// attach to the routes within each CIDR.  Each community is either a community value, or
+// the name of one of the named communities.
+var prefixAdvertisementsStringifier = func(value interface{}) string {
+	pas := value.([]apiv2.PrefixAdvertisement)
+	v1 := make([]prefixAdvertisement, len(pas))
+	for i, pa := range pas {
+		v1[i] = prefixAdvertisement{CIDR: pa.CIDR, Communities: pa.Communities}
+	}
+	d, err := json.Marshal(v1)
+	cerrors.FatalIfErrored(err)
+	return string(d)
+}
+
+// prefixAdvertisement is the JSON structure of a prefix advertisement understood by the
+// Calico BGP component.
+type prefixAdvertisement struct {
+	CIDR        string   `json:"cidr"`
+	Communities []string `json:"communities"`
+}
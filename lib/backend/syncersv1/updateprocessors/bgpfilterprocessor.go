@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"errors"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// Create a new SyncerUpdateProcessor to sync BGPFilter data in v1 format for
+// consumption by the BGP daemon.
+func NewBGPFilterUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
+	return NewSimpleUpdateProcessor(apiv2.KindBGPFilter, convertBGPFilterV2ToV1Key, convertBGPFilterV2ToV1Value)
+}
+
+func convertBGPFilterV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
+	if v2key.Name == "" {
+		return model.BGPFilterKey{}, errors.New("Missing Name field to create a v1 BGPFilter Key")
+	}
+	return model.BGPFilterKey{
+		Name: v2key.Name,
+	}, nil
+}
+
+func convertBGPFilterV2ToV1Value(val interface{}) (interface{}, error) {
+	v2res, ok := val.(*apiv2.BGPFilter)
+	if !ok {
+		return nil, errors.New("Value is not a valid BGPFilter resource value")
+	}
+
+	export, err := convertBGPFilterRulesV2ToV1(v2res.Spec.Export)
+	if err != nil {
+		return nil, err
+	}
+	imp, err := convertBGPFilterRulesV2ToV1(v2res.Spec.Import)
+	if err != nil {
+		return nil, err
+	}
+	return &model.BGPFilter{
+		Export: export,
+		Import: imp,
+	}, nil
+}
+
+func convertBGPFilterRulesV2ToV1(rules []apiv2.BGPFilterRule) ([]model.BGPFilterRule, error) {
+	var v1rules []model.BGPFilterRule
+	for _, r := range rules {
+		_, cidr, err := cnet.ParseCIDR(r.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		v1rules = append(v1rules, model.BGPFilterRule{
+			CIDR:          *cidr,
+			MatchOperator: string(r.MatchOperator),
+			Action:        string(r.Action),
+		})
+	}
+	return v1rules, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Test the BGPFilter update processor", func() {
+	v2FilterKey1 := model.ResourceKey{
+		Kind: apiv2.KindBGPFilter,
+		Name: "filter1",
+	}
+	v1FilterKey1 := model.BGPFilterKey{
+		Name: "filter1",
+	}
+
+	It("should handle conversion of valid BGPFilters", func() {
+		up := updateprocessors.NewBGPFilterUpdateProcessor()
+
+		By("converting a BGPFilter with no rules")
+		res := apiv2.NewBGPFilter()
+		res.Name = v2FilterKey1.Name
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2FilterKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{{
+			Key:      v1FilterKey1,
+			Value:    &model.BGPFilter{},
+			Revision: "abcde",
+		}}))
+
+		By("converting a BGPFilter with import and export rules")
+		res.Spec.Export = []apiv2.BGPFilterRule{
+			{CIDR: "10.0.0.0/8", MatchOperator: apiv2.BGPFilterMatchOperatorIn, Action: apiv2.BGPFilterActionAccept},
+			{CIDR: "0.0.0.0/0", MatchOperator: apiv2.BGPFilterMatchOperatorIn, Action: apiv2.BGPFilterActionReject},
+		}
+		res.Spec.Import = []apiv2.BGPFilterRule{
+			{CIDR: "fd00::/64", MatchOperator: apiv2.BGPFilterMatchOperatorEqual, Action: apiv2.BGPFilterActionReject},
+		}
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2FilterKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{{
+			Key: v1FilterKey1,
+			Value: &model.BGPFilter{
+				Export: []model.BGPFilterRule{
+					{CIDR: net.MustParseCIDR("10.0.0.0/8"), MatchOperator: "In", Action: "Accept"},
+					{CIDR: net.MustParseCIDR("0.0.0.0/0"), MatchOperator: "In", Action: "Reject"},
+				},
+				Import: []model.BGPFilterRule{
+					{CIDR: net.MustParseCIDR("fd00::/64"), MatchOperator: "Equal", Action: "Reject"},
+				},
+			},
+			Revision: "abcdef",
+		}}))
+
+		By("deleting the BGPFilter")
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2FilterKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{{
+			Key: v1FilterKey1,
+		}}))
+	})
+
+	It("should treat a BGPFilter with an invalid CIDR as deleted", func() {
+		up := updateprocessors.NewBGPFilterUpdateProcessor()
+
+		res := apiv2.NewBGPFilter()
+		res.Name = v2FilterKey1.Name
+		res.Spec.Import = []apiv2.BGPFilterRule{
+			{CIDR: "10.0.0.0/33", MatchOperator: apiv2.BGPFilterMatchOperatorIn, Action: apiv2.BGPFilterActionAccept},
+		}
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2FilterKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{{
+			Key: v1FilterKey1,
+		}}))
+	})
+})
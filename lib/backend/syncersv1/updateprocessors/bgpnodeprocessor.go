@@ -45,7 +45,7 @@ func (c *bgpNodeUpdateProcessor) Process(kvp *model.KVPair) ([]*model.KVPair, er
 
 	// Extract the separate bits of BGP config - these are stored as separate keys in the
 	// v1 model.  For a delete these will all be nil.
-	var asNum, ipv4, netv4, ipv6, netv6 interface{}
+	var asNum, ipv4, netv4, ipv6, netv6, rrClusterID interface{}
 	if kvp.Value != nil {
 		node, ok := kvp.Value.(*apiv2.Node)
 		if !ok {
@@ -81,6 +81,9 @@ func (c *bgpNodeUpdateProcessor) Process(kvp *model.KVPair) ([]*model.KVPair, er
 			if bgp.ASNumber != nil {
 				asNum = bgp.ASNumber.String()
 			}
+			if len(bgp.RouteReflectorClusterID) != 0 {
+				rrClusterID = bgp.RouteReflectorClusterID
+			}
 		}
 	}
 
@@ -125,6 +128,14 @@ func (c *bgpNodeUpdateProcessor) Process(kvp *model.KVPair) ([]*model.KVPair, er
 			Value:    asNum,
 			Revision: kvp.Revision,
 		},
+		{
+			Key: model.NodeBGPConfigKey{
+				Nodename: name,
+				Name:     "rr_cluster_id",
+			},
+			Value:    rrClusterID,
+			Revision: kvp.Revision,
+		},
 	}, err
 }
 
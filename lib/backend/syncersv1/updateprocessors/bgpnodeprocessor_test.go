@@ -29,7 +29,7 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 		Kind: apiv2.KindNode,
 		Name: "bgpnode1",
 	}
-	numBgpConfigs := 5
+	numBgpConfigs := 6
 	up := updateprocessors.NewBGPNodeUpdateProcessor()
 
 	BeforeEach(func() {
@@ -44,11 +44,12 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 		res := apiv2.NewNode()
 		res.Name = "bgpnode1"
 		expected := map[string]interface{}{
-			"ip_addr_v4": "",
-			"ip_addr_v6": "",
-			"network_v4": nil,
-			"network_v6": nil,
-			"as_num":     nil,
+			"ip_addr_v4":    "",
+			"ip_addr_v6":    "",
+			"network_v4":    nil,
+			"network_v6":    nil,
+			"as_num":        nil,
+			"rr_cluster_id": nil,
 		}
 		kvps, err := up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -86,11 +87,12 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 			IPv4Address: "1.2.3.4",
 		}
 		expected = map[string]interface{}{
-			"ip_addr_v4": "1.2.3.4",
-			"ip_addr_v6": "",
-			"network_v4": "1.2.3.4/32",
-			"network_v6": nil,
-			"as_num":     nil,
+			"ip_addr_v4":    "1.2.3.4",
+			"ip_addr_v6":    "",
+			"network_v4":    "1.2.3.4/32",
+			"network_v6":    nil,
+			"as_num":        nil,
+			"rr_cluster_id": nil,
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -111,11 +113,12 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 			IPv6Address: "aa:bb:cc::",
 		}
 		expected = map[string]interface{}{
-			"ip_addr_v4": "",
-			"ip_addr_v6": "aa:bb:cc::",
-			"network_v4": nil,
-			"network_v6": "aa:bb:cc::/128",
-			"as_num":     nil,
+			"ip_addr_v4":    "",
+			"ip_addr_v6":    "aa:bb:cc::",
+			"network_v4":    nil,
+			"network_v6":    "aa:bb:cc::/128",
+			"as_num":        nil,
+			"rr_cluster_id": nil,
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -139,11 +142,39 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 			ASNumber:    &asn,
 		}
 		expected = map[string]interface{}{
-			"ip_addr_v4": "1.2.3.4",
-			"ip_addr_v6": "aa:bb:cc::ffff",
-			"network_v4": "1.2.3.0/24",
-			"network_v6": "aa:bb:cc::ff00/120",
-			"as_num":     "12345",
+			"ip_addr_v4":    "1.2.3.4",
+			"ip_addr_v6":    "aa:bb:cc::ffff",
+			"network_v4":    "1.2.3.0/24",
+			"network_v6":    "aa:bb:cc::ff00/120",
+			"as_num":        "12345",
+			"rr_cluster_id": nil,
+		}
+		kvps, err = up.Process(&model.KVPair{
+			Key:   v2NodeKey1,
+			Value: res,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		checkExpectedConfigs(
+			kvps,
+			isNodeBgpConfig,
+			numBgpConfigs,
+			expected,
+		)
+
+		By("converting a route reflector Node")
+		res = apiv2.NewNode()
+		res.Name = "bgpnode1"
+		res.Spec.BGP = &apiv2.NodeBGPSpec{
+			IPv4Address:             "1.2.3.4/24",
+			RouteReflectorClusterID: "224.0.0.1",
+		}
+		expected = map[string]interface{}{
+			"ip_addr_v4":    "1.2.3.4",
+			"ip_addr_v6":    "",
+			"network_v4":    "1.2.3.0/24",
+			"network_v6":    nil,
+			"as_num":        nil,
+			"rr_cluster_id": "224.0.0.1",
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -195,11 +226,12 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 		})
 		// IPv4 address should be blank, network should be nil (deleted)
 		expected := map[string]interface{}{
-			"ip_addr_v4": "",
-			"ip_addr_v6": "aa:bb:cc::ffff",
-			"network_v4": nil,
-			"network_v6": "aa:bb:cc::ff00/120",
-			"as_num":     "12345",
+			"ip_addr_v4":    "",
+			"ip_addr_v6":    "aa:bb:cc::ffff",
+			"network_v4":    nil,
+			"network_v6":    "aa:bb:cc::ff00/120",
+			"as_num":        "12345",
+			"rr_cluster_id": nil,
 		}
 		Expect(err).To(HaveOccurred())
 		checkExpectedConfigs(
@@ -222,11 +254,12 @@ var _ = Describe("Test the (BGP) Node update processor", func() {
 		})
 		// IPv6 address should be blank, network should be nil (deleted)
 		expected = map[string]interface{}{
-			"ip_addr_v4": "1.2.3.4",
-			"ip_addr_v6": "",
-			"network_v4": "1.2.3.0/24",
-			"network_v6": nil,
-			"as_num":     nil,
+			"ip_addr_v4":    "1.2.3.4",
+			"ip_addr_v6":    "",
+			"network_v4":    "1.2.3.0/24",
+			"network_v6":    nil,
+			"as_num":        nil,
+			"rr_cluster_id": nil,
 		}
 		Expect(err).To(HaveOccurred())
 		checkExpectedConfigs(
@@ -17,16 +17,93 @@ package updateprocessors
 import (
 	"errors"
 
+	log "github.com/sirupsen/logrus"
+
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
-// Create a new SyncerUpdateProcessor to sync IPPool data in v1 format for
-// consumption by both Felix and the BGP daemon.
+// Create a new SyncerUpdateProcessor to sync BGPPeer data in v1 format for
+// consumption by the BGP daemon.
+//
+// BGPPeers that specify a node selector or a peer selector cannot be converted to the v1
+// format without knowledge of the nodes, so these are passed through unchanged in the v2
+// format.  The BGP syncer expands these into the equivalent per-node v1 peerings.
 func NewBGPPeerUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
-	return NewConflictResolvingCacheUpdateProcessor(apiv2.KindBGPPeer, convertBGPPeerV2ToV1)
+	return &bgpPeerUpdateProcessor{
+		explicit:  NewConflictResolvingCacheUpdateProcessor(apiv2.KindBGPPeer, convertBGPPeerV2ToV1),
+		selectors: make(map[string]bool),
+	}
+}
+
+// bgpPeerUpdateProcessor implements the SyncerUpdateProcessor interface.  It tracks whether
+// each BGPPeer was last sent as a selector based peer (passed through in the v2 format) or as
+// an explicit peer (converted to the v1 format), so that the correct deletes are sent when a
+// peer is deleted or switches between the two.
+type bgpPeerUpdateProcessor struct {
+	explicit  watchersyncer.SyncerUpdateProcessor
+	selectors map[string]bool
+}
+
+func (p *bgpPeerUpdateProcessor) Process(kvp *model.KVPair) ([]*model.KVPair, error) {
+	v2key, ok := kvp.Key.(model.ResourceKey)
+	if !ok || v2key.Kind != apiv2.KindBGPPeer {
+		return nil, errors.New("Key is not a valid BGPPeer resource key")
+	}
+
+	wasSelector, known := p.selectors[v2key.Name]
+	isSelector := false
+	if res, ok := kvp.Value.(*apiv2.BGPPeer); ok {
+		isSelector = IsSelectorBGPPeer(res)
+	}
+
+	var kvps []*model.KVPair
+	var err error
+	switch {
+	case isSelector:
+		// If this was previously an explicit peer then remove the v1 peer before passing
+		// through the v2 resource.
+		if known && !wasSelector {
+			kvps, err = p.explicit.Process(&model.KVPair{Key: kvp.Key})
+		}
+		p.selectors[v2key.Name] = true
+		kvps = append(kvps, kvp)
+	case wasSelector:
+		// This was previously a selector based peer, remove the v2 resource and then handle
+		// as an explicit peer if this is not a delete.
+		kvps = append(kvps, &model.KVPair{Key: kvp.Key})
+		delete(p.selectors, v2key.Name)
+		if kvp.Value != nil {
+			var more []*model.KVPair
+			more, err = p.explicit.Process(kvp)
+			kvps = append(kvps, more...)
+			p.selectors[v2key.Name] = false
+		}
+	default:
+		kvps, err = p.explicit.Process(kvp)
+		if kvp.Value != nil {
+			p.selectors[v2key.Name] = false
+		} else {
+			delete(p.selectors, v2key.Name)
+		}
+	}
+	return kvps, err
+}
+
+// OnSyncerStarting is called when syncer is starting a full sync for the associated
+// resource types.  Clear our caches since we will be getting a full resync.
+func (p *bgpPeerUpdateProcessor) OnSyncerStarting() {
+	log.Debug("Sync starting called on BGP peer update processor")
+	p.selectors = make(map[string]bool)
+	p.explicit.OnSyncerStarting()
+}
+
+// IsSelectorBGPPeer returns true if the BGPPeer uses a node selector or peer selector, and
+// therefore needs to be expanded into the per-node peerings.
+func IsSelectorBGPPeer(peer *apiv2.BGPPeer) bool {
+	return peer.Spec.NodeSelector != "" || peer.Spec.PeerSelector != ""
 }
 
 // Convert v2 KVPair to the equivalent v1 KVPair.
@@ -64,9 +141,23 @@ func convertBGPPeerV2ToV1(kvp *model.KVPair) (*model.KVPair, error) {
 	return &model.KVPair{
 		Key: v1key,
 		Value: &model.BGPPeer{
-			PeerIP: *ip,
-			ASNum:  v2res.Spec.ASNumber,
+			PeerIP:            *ip,
+			ASNum:             v2res.Spec.ASNumber,
+			PasswordSecretRef: GetBGPPeerPasswordSecretRef(v2res),
+			Filters:           v2res.Spec.Filters,
 		},
 		Revision: kvp.Revision,
 	}, nil
 }
+
+// GetBGPPeerPasswordSecretRef returns the reference to the secret key containing the
+// password for the BGPPeer, or nil if the BGPPeer does not have a password.
+func GetBGPPeerPasswordSecretRef(peer *apiv2.BGPPeer) *model.SecretKeyRef {
+	if peer.Spec.Password == nil || peer.Spec.Password.SecretKeyRef == nil {
+		return nil
+	}
+	return &model.SecretKeyRef{
+		Name: peer.Spec.Password.SecretKeyRef.Name,
+		Key:  peer.Spec.Password.SecretKeyRef.Key,
+	}
+}
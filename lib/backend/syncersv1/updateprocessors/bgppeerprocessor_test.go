@@ -212,6 +212,118 @@ var _ = Describe("Test the BGPPeer update processor", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should pass through selector based BGPPeers", func() {
+		up := updateprocessors.NewBGPPeerUpdateProcessor()
+
+		By("adding a BGPPeer with a peer selector")
+		res := apiv2.NewBGPPeer()
+		res.Name = v2PeerKey1.Name
+		res.Spec.NodeSelector = "!has(rr)"
+		res.Spec.PeerSelector = "has(rr)"
+		kvp := &model.KVPair{
+			Key:      v2PeerKey1,
+			Value:    res,
+			Revision: "abcde",
+		}
+		kvps, err := up.Process(kvp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{kvp}))
+
+		By("updating the BGPPeer to be an explicit global peer")
+		res = apiv2.NewBGPPeer()
+		res.Name = v2PeerKey1.Name
+		res.Spec.PeerIP = ip1str
+		res.Spec.ASNumber = 11111
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2PeerKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v2PeerKey1,
+			},
+			{
+				Key: v1GlobalPeerKeyIP1,
+				Value: &model.BGPPeer{
+					PeerIP: v1GlobalPeerKeyIP1.PeerIP,
+					ASNum:  11111,
+				},
+				Revision: "abcdef",
+			},
+		}))
+
+		By("updating the BGPPeer to use a node selector")
+		res = apiv2.NewBGPPeer()
+		res.Name = v2PeerKey1.Name
+		res.Spec.NodeSelector = "rack == 'rack1'"
+		res.Spec.PeerIP = ip1str
+		res.Spec.ASNumber = 11111
+		kvp = &model.KVPair{
+			Key:      v2PeerKey1,
+			Value:    res,
+			Revision: "abcdefg",
+		}
+		kvps, err = up.Process(kvp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalPeerKeyIP1,
+			},
+			kvp,
+		}))
+
+		By("deleting the BGPPeer")
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2PeerKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v2PeerKey1,
+			},
+		}))
+	})
+
+	It("should include the password secret reference and filters of a BGPPeer", func() {
+		up := updateprocessors.NewBGPPeerUpdateProcessor()
+
+		res := apiv2.NewBGPPeer()
+		res.Name = v2PeerKey1.Name
+		res.Spec.PeerIP = ip2str
+		res.Spec.ASNumber = 11111
+		res.Spec.Node = node1
+		res.Spec.Password = &apiv2.BGPPassword{
+			SecretKeyRef: &apiv2.SecretKeySelector{
+				Name: "bgp-secrets",
+				Key:  "peer-a",
+			},
+		}
+		res.Spec.Filters = []string{"filter-1", "filter-2"}
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2PeerKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1Node1PeerKeyIP2,
+				Value: &model.BGPPeer{
+					PeerIP: v1Node1PeerKeyIP2.PeerIP,
+					ASNum:  11111,
+					PasswordSecretRef: &model.SecretKeyRef{
+						Name: "bgp-secrets",
+						Key:  "peer-a",
+					},
+					Filters: []string{"filter-1", "filter-2"},
+				},
+				Revision: "abcde",
+			},
+		}))
+	})
+
 	It("should fail to convert an invalid resource", func() {
 		up := updateprocessors.NewBGPPeerUpdateProcessor()
 
@@ -102,8 +102,8 @@ var (
 // each field, using either the name of the field or the value in the confignamev1 tag
 // as the name of the config key and the value of the field converted to the config value.
 // The struct field values are converted as follows:
-// -  if the field is nil, or an empty string - the converted value is nil indicating a
-//    deletion of the config key.
+// -  if the field is nil, an empty string or an empty slice - the converted value is nil
+//    indicating a deletion of the config key.
 // -  if a converter has been provided for the field then the value is converted using
 //    that converter.
 // -  if it is a string field, the value is used as is.
@@ -201,13 +201,17 @@ func (c *configUpdateProcessor) processAddOrModified(kvp *model.KVPair) ([]*mode
 		}
 
 		// Extract the field value and dereference pointers, storing a nil value if the pointer is nil
-		// or if it's a zero length string.
+		// or if it's a zero length string or slice.
 		var value interface{}
 		field := specValue.Field(i)
 		if field.Kind() == reflect.Ptr {
 			if !field.IsNil() {
 				value = field.Elem().Interface()
 			}
+		} else if field.Kind() == reflect.Slice {
+			if field.Len() > 0 {
+				value = field.Interface()
+			}
 		} else {
 			value = field.Interface()
 			if s, ok := value.(string); ok && len(s) == 0 {
@@ -70,7 +70,7 @@ var _ = Describe("Test the generic configuration update processor and the concre
 	}
 	numFelixConfigs := 46
 	numClusterConfigs := 3
-	numBgpConfigs := 3
+	numBgpConfigs := 7
 	felixMappedNames := map[string]interface{}{
 		"RouteRefreshInterval":    nil,
 		"IptablesRefreshInterval": nil,
@@ -430,9 +430,13 @@ var _ = Describe("Test the generic configuration update processor and the concre
 
 		By("validating an empty configuration")
 		expected := map[string]interface{}{
-			"loglevel":  nil,
-			"as_num":    nil,
-			"node_mesh": nil,
+			"loglevel":              nil,
+			"as_num":                nil,
+			"node_mesh":             nil,
+			"svc_cluster_ips":       nil,
+			"svc_external_ips":      nil,
+			"communities":           nil,
+			"prefix_advertisements": nil,
 		}
 		kvps, err := cc.Process(&model.KVPair{
 			Key:   globalBgpConfigKey,
@@ -452,10 +456,27 @@ var _ = Describe("Test the generic configuration update processor and the concre
 		res.Spec.LogSeverityScreen = "warning"
 		res.Spec.ASNumber = &asNum
 		res.Spec.NodeToNodeMeshEnabled = &n2n
+		res.Spec.ServiceClusterIPs = []apiv2.ServiceClusterIPBlock{
+			{CIDR: "10.96.0.0/12"},
+			{CIDR: "fd00:96::/108"},
+		}
+		res.Spec.ServiceExternalIPs = []apiv2.ServiceExternalIPBlock{
+			{CIDR: "172.16.10.0/24"},
+		}
+		res.Spec.Communities = []apiv2.Community{
+			{Name: "standard", Value: "65000:100"},
+		}
+		res.Spec.PrefixAdvertisements = []apiv2.PrefixAdvertisement{
+			{CIDR: "192.168.0.0/16", Communities: []string{"standard", "65000:1:2"}},
+		}
 		expected = map[string]interface{}{
-			"loglevel":  "none",
-			"as_num":    "12345",
-			"node_mesh": "{\"enabled\":true}",
+			"loglevel":              "none",
+			"as_num":                "12345",
+			"node_mesh":             "{\"enabled\":true}",
+			"svc_cluster_ips":       "10.96.0.0/12,fd00:96::/108",
+			"svc_external_ips":      "172.16.10.0/24",
+			"communities":           "{\"standard\":\"65000:100\"}",
+			"prefix_advertisements": "[{\"cidr\":\"192.168.0.0/16\",\"communities\":[\"standard\",\"65000:1:2\"]}]",
 		}
 		kvps, err = cc.Process(&model.KVPair{
 			Key:   globalBgpConfigKey,
@@ -474,6 +495,10 @@ var _ = Describe("Test the generic configuration update processor and the concre
 		res.Spec.LogSeverityScreen = "debug"
 		res.Spec.ASNumber = nil
 		res.Spec.NodeToNodeMeshEnabled = &n2n
+		res.Spec.ServiceClusterIPs = []apiv2.ServiceClusterIPBlock{}
+		res.Spec.ServiceExternalIPs = nil
+		res.Spec.Communities = nil
+		res.Spec.PrefixAdvertisements = nil
 		expected = map[string]interface{}{
 			"loglevel":  "debug",
 			"as_num":    nil,
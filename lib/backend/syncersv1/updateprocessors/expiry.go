@@ -0,0 +1,39 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+)
+
+// isExpired returns true if an expiry time is set and the expiry time has been reached.
+func isExpired(expiresAt *metav1.Time, now time.Time) bool {
+	return expiresAt != nil && !now.Before(expiresAt.Time)
+}
+
+// unexpiredRules returns the rules that have not expired.
+func unexpiredRules(rules []apiv2.Rule, now time.Time) []apiv2.Rule {
+	var filtered []apiv2.Rule
+	for _, r := range rules {
+		if !isExpired(r.ExpiresAt, now) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
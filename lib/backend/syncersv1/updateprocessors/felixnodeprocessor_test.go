@@ -29,7 +29,7 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		Kind: apiv2.KindNode,
 		Name: "mynode",
 	}
-	numFelixConfigs := 2
+	numFelixConfigs := 3
 	up := updateprocessors.NewFelixNodeUpdateProcessor()
 
 	BeforeEach(func() {
@@ -45,8 +45,9 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		res := apiv2.NewNode()
 		res.Name = "mynode"
 		expected := map[string]interface{}{
-			hostIPMarker:       nil,
-			"IpInIpTunnelAddr": nil,
+			hostIPMarker:          nil,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": nil,
 		}
 		kvps, err := up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -85,8 +86,9 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		}
 		ip := net.MustParseIP("1.2.3.4")
 		expected = map[string]interface{}{
-			hostIPMarker:       &ip,
-			"IpInIpTunnelAddr": nil,
+			hostIPMarker:          &ip,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": nil,
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -109,8 +111,9 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		}
 		ip = net.MustParseIP("100.200.100.200")
 		expected = map[string]interface{}{
-			hostIPMarker:       &ip,
-			"IpInIpTunnelAddr": nil,
+			hostIPMarker:          &ip,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": nil,
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -132,8 +135,30 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 			IPv4IPIPTunnelAddr: "192.100.100.100",
 		}
 		expected = map[string]interface{}{
-			hostIPMarker:       nil,
-			"IpInIpTunnelAddr": "192.100.100.100",
+			hostIPMarker:          nil,
+			"IpInIpTunnelAddr":    "192.100.100.100",
+			"IPv4VXLANTunnelAddr": nil,
+		}
+		kvps, err = up.Process(&model.KVPair{
+			Key:   v2NodeKey1,
+			Value: res,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		checkExpectedConfigs(
+			kvps,
+			isNodeFelixConfig,
+			numFelixConfigs,
+			expected,
+		)
+
+		By("converting a Node with a VXLAN tunnel address and no BGP config")
+		res = apiv2.NewNode()
+		res.Name = "mynode"
+		res.Spec.IPv4VXLANTunnelAddr = "192.200.200.200"
+		expected = map[string]interface{}{
+			hostIPMarker:          nil,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": "192.200.200.200",
 		}
 		kvps, err = up.Process(&model.KVPair{
 			Key:   v2NodeKey1,
@@ -183,8 +208,9 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		})
 		Expect(err).To(HaveOccurred())
 		expected := map[string]interface{}{
-			hostIPMarker:       nil,
-			"IpInIpTunnelAddr": "192.100.100.100",
+			hostIPMarker:          nil,
+			"IpInIpTunnelAddr":    "192.100.100.100",
+			"IPv4VXLANTunnelAddr": nil,
 		}
 		checkExpectedConfigs(
 			kvps,
@@ -207,8 +233,30 @@ var _ = Describe("Test the (Felix) Node update processor", func() {
 		Expect(err).To(HaveOccurred())
 		ip := net.MustParseIP("1.2.3.4")
 		expected = map[string]interface{}{
-			hostIPMarker:       &ip,
-			"IpInIpTunnelAddr": nil,
+			hostIPMarker:          &ip,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": nil,
+		}
+		checkExpectedConfigs(
+			kvps,
+			isNodeFelixConfig,
+			numFelixConfigs,
+			expected,
+		)
+
+		By("trying to convert with an invalid VXLAN tunnel address - expect delete for that key")
+		res = apiv2.NewNode()
+		res.Name = "mynode"
+		res.Spec.IPv4VXLANTunnelAddr = "192.200.200.200/24"
+		kvps, err = up.Process(&model.KVPair{
+			Key:   v2NodeKey1,
+			Value: res,
+		})
+		Expect(err).To(HaveOccurred())
+		expected = map[string]interface{}{
+			hostIPMarker:          nil,
+			"IpInIpTunnelAddr":    nil,
+			"IPv4VXLANTunnelAddr": nil,
 		}
 		checkExpectedConfigs(
 			kvps,
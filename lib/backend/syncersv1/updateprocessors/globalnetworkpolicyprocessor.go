@@ -16,6 +16,9 @@ package updateprocessors
 
 import (
 	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -25,7 +28,26 @@ import (
 // Create a new SyncerUpdateProcessor to sync GlobalNetworkPolicy data in v1 format for
 // consumption by Felix.
 func NewGlobalNetworkPolicyUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
-	return NewSimpleUpdateProcessor(apiv2.KindGlobalNetworkPolicy, convertGlobalNetworkPolicyV2ToV1Key, convertGlobalNetworkPolicyV2ToV1Value)
+	return NewGlobalNetworkPolicyUpdateProcessorWithClock(clock.RealClock{})
+}
+
+// Create a new SyncerUpdateProcessor to sync GlobalNetworkPolicy data in v1 format for
+// consumption by Felix, using the supplied clock to determine whether a policy or rule has
+// expired.  Expired policies are sent as deleted, and expired rules are removed.
+//
+// Expiry is only checked when a policy update is processed, so a policy that is not modified
+// continues to be applied after it (or one of its rules) expires.  A policyexpiry.Sweeper must
+// be running against the datastore - it deletes or updates the policy at the expiry time, and
+// that update causes the policy to be re-processed.
+func NewGlobalNetworkPolicyUpdateProcessorWithClock(clk clock.Clock) watchersyncer.SyncerUpdateProcessor {
+	return newTieredPolicyUpdateProcessor(
+		apiv2.KindGlobalNetworkPolicy,
+		convertGlobalNetworkPolicyV2ToV1Key,
+		func(val interface{}) (interface{}, error) {
+			return convertGlobalNetworkPolicyV2ToV1Value(val, clk.Now())
+		},
+		getGlobalNetworkPolicyTier,
+	)
 }
 
 func convertGlobalNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
@@ -38,19 +60,37 @@ func convertGlobalNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, er
 
 }
 
-func convertGlobalNetworkPolicyV2ToV1Value(val interface{}) (interface{}, error) {
+func getGlobalNetworkPolicyTier(val interface{}) (string, error) {
+	v2res, ok := val.(*apiv2.GlobalNetworkPolicy)
+	if !ok {
+		return "", errors.New("Value is not a valid GlobalNetworkPolicy resource value")
+	}
+	return v2res.Spec.Tier, nil
+}
+
+func convertGlobalNetworkPolicyV2ToV1Value(val interface{}, now time.Time) (interface{}, error) {
 	v2res, ok := val.(*apiv2.GlobalNetworkPolicy)
 	if !ok {
 		return nil, errors.New("Value is not a valid GlobalNetworkPolicy resource value")
 	}
-	return convertGlobalPolicyV2ToV1Spec(v2res.Spec)
+	v1value, err := convertGlobalPolicyV2ToV1Spec(v2res.Spec, now)
+	if v1value == nil {
+		// Return an untyped nil so that the policy is treated as deleted.
+		return nil, err
+	}
+	return v1value, err
 }
 
-func convertGlobalPolicyV2ToV1Spec(spec apiv2.GlobalNetworkPolicySpec) (*model.Policy, error) {
+// convertGlobalPolicyV2ToV1Spec converts the GlobalNetworkPolicySpec to a v1 policy, removing
+// any rules that have expired at the supplied time.  Returns nil if the policy has expired.
+func convertGlobalPolicyV2ToV1Spec(spec apiv2.GlobalNetworkPolicySpec, now time.Time) (*model.Policy, error) {
+	if isExpired(spec.ExpiresAt, now) {
+		return nil, nil
+	}
 	v1value := &model.Policy{
 		Order:          spec.Order,
-		InboundRules:   RulesAPIV2ToBackend(spec.IngressRules, ""),
-		OutboundRules:  RulesAPIV2ToBackend(spec.EgressRules, ""),
+		InboundRules:   RulesAPIV2ToBackend(unexpiredRules(spec.IngressRules, now), ""),
+		OutboundRules:  RulesAPIV2ToBackend(unexpiredRules(spec.EgressRules, now), ""),
 		Selector:       spec.Selector,
 		Types:          policyTypesAPIV2ToBackend(spec.Types),
 		DoNotTrack:     spec.DoNotTrack,
@@ -15,8 +15,12 @@
 package updateprocessors_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -38,9 +42,11 @@ var _ = Describe("Test the GlobalNetworkPolicy update processor", func() {
 		Name: name2,
 	}
 	v1GlobalNetworkPolicyKey1 := model.PolicyKey{
+		Tier: "default",
 		Name: name1,
 	}
 	v1GlobalNetworkPolicyKey2 := model.PolicyKey{
+		Tier: "default",
 		Name: name2,
 	}
 
@@ -198,6 +204,70 @@ var _ = Describe("Test the GlobalNetworkPolicy update processor", func() {
 		}))
 	})
 
+	It("should include the tier in the v1 key and handle tier changes", func() {
+		up := updateprocessors.NewGlobalNetworkPolicyUpdateProcessor()
+		v1GlobalNetworkPolicyKey1Tier1 := model.PolicyKey{
+			Tier: "tier1",
+			Name: name1,
+		}
+
+		By("converting a GlobalNetworkPolicy in a non-default tier")
+		res := apiv2.NewGlobalNetworkPolicy()
+		res.Spec.Tier = "tier1"
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key:      v1GlobalNetworkPolicyKey1Tier1,
+				Value:    &model.Policy{},
+				Revision: "abcde",
+			},
+		}))
+
+		By("moving the GlobalNetworkPolicy to the default tier")
+		res = apiv2.NewGlobalNetworkPolicy()
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1Tier1,
+			},
+			{
+				Key:      v1GlobalNetworkPolicyKey1,
+				Value:    &model.Policy{},
+				Revision: "abcdef",
+			},
+		}))
+
+		By("moving the GlobalNetworkPolicy back to tier1 and then deleting it")
+		res = apiv2.NewGlobalNetworkPolicy()
+		res.Spec.Tier = "tier1"
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdefg",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(2))
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2GlobalNetworkPolicyKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1Tier1,
+			},
+		}))
+	})
+
 	It("should fail to convert an invalid resource", func() {
 		up := updateprocessors.NewGlobalNetworkPolicyUpdateProcessor()
 
@@ -242,4 +312,128 @@ var _ = Describe("Test the GlobalNetworkPolicy update processor", func() {
 		})
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("should filter out expired GlobalNetworkPolicies and rules", func() {
+		now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+		clk := clock.NewFakeClock(now)
+		up := updateprocessors.NewGlobalNetworkPolicyUpdateProcessorWithClock(clk)
+		expiresAt := metav1.NewTime(now.Add(time.Hour))
+		ruleExpiresAt := metav1.NewTime(now.Add(time.Minute))
+		rule1 := apiv2.Rule{Action: apiv2.Allow, ExpiresAt: &ruleExpiresAt}
+		rule2 := apiv2.Rule{Action: apiv2.Deny}
+
+		res := apiv2.NewGlobalNetworkPolicy()
+		res.Spec.Selector = "has(foo)"
+		res.Spec.ExpiresAt = &expiresAt
+		res.Spec.IngressRules = []apiv2.Rule{rule1, rule2}
+
+		By("converting the policy before the rule has expired")
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1,
+				Value: &model.Policy{
+					Selector: "has(foo)",
+					InboundRules: []model.Rule{
+						updateprocessors.RuleAPIV2ToBackend(rule1, ""),
+						updateprocessors.RuleAPIV2ToBackend(rule2, ""),
+					},
+				},
+				Revision: "abcde",
+			},
+		}))
+
+		By("converting the policy after the rule has expired")
+		clk.Step(time.Minute)
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1,
+				Value: &model.Policy{
+					Selector:     "has(foo)",
+					InboundRules: []model.Rule{updateprocessors.RuleAPIV2ToBackend(rule2, "")},
+				},
+				Revision: "abcdef",
+			},
+		}))
+
+		By("converting the policy after the policy has expired")
+		clk.Step(time.Hour)
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdefg",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1,
+			},
+		}))
+	})
+
+	It("should keep the policy types when all of the rules in a direction have expired", func() {
+		now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+		clk := clock.NewFakeClock(now)
+		up := updateprocessors.NewGlobalNetworkPolicyUpdateProcessorWithClock(clk)
+		ingressExpiresAt := metav1.NewTime(now.Add(time.Minute))
+		egressExpiresAt := metav1.NewTime(now.Add(time.Hour))
+		ingressRule := apiv2.Rule{Action: apiv2.Allow, ExpiresAt: &ingressExpiresAt}
+		egressRule := apiv2.Rule{Action: apiv2.Allow, ExpiresAt: &egressExpiresAt}
+
+		res := apiv2.NewGlobalNetworkPolicy()
+		res.Spec.Selector = "has(foo)"
+		res.Spec.IngressRules = []apiv2.Rule{ingressRule}
+		res.Spec.EgressRules = []apiv2.Rule{egressRule}
+		res.Spec.Types = []apiv2.PolicyType{apiv2.PolicyTypeIngress, apiv2.PolicyTypeEgress}
+
+		By("converting the policy after the only ingress rule has expired")
+		clk.Step(time.Minute)
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1,
+				Value: &model.Policy{
+					Selector:      "has(foo)",
+					OutboundRules: []model.Rule{updateprocessors.RuleAPIV2ToBackend(egressRule, "")},
+					Types:         []string{"ingress", "egress"},
+				},
+				Revision: "abcde",
+			},
+		}))
+
+		By("converting the policy after all of the rules have expired, which still denies all traffic")
+		clk.Step(time.Hour)
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2GlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1GlobalNetworkPolicyKey1,
+				Value: &model.Policy{
+					Selector: "has(foo)",
+					Types:    []string{"ingress", "egress"},
+				},
+				Revision: "abcdef",
+			},
+		}))
+	})
 })
@@ -22,6 +22,7 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
 	"github.com/projectcalico/libcalico-go/lib/ipip"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/vxlan"
 )
 
 // Create a new SyncerUpdateProcessor to sync IPPool data in v1 format for
@@ -65,12 +66,23 @@ func convertIPPoolV2ToV1(kvp *model.KVPair) (*model.KVPair, error) {
 		ipipMode = ipip.Undefined
 	}
 
+	var vxlanMode vxlan.Mode
+	switch v2res.Spec.VXLANMode {
+	case apiv2.VXLANModeAlways:
+		vxlanMode = vxlan.Always
+	case apiv2.VXLANModeCrossSubnet:
+		vxlanMode = vxlan.CrossSubnet
+	default:
+		vxlanMode = vxlan.Undefined
+	}
+
 	return &model.KVPair{
 		Key: v1key,
 		Value: &model.IPPool{
 			CIDR:          *cidr,
 			IPIPInterface: ipipInterface,
 			IPIPMode:      ipipMode,
+			VXLANMode:     vxlanMode,
 			Masquerade:    v2res.Spec.NATOutgoing,
 			IPAM:          !v2res.Spec.Disabled,
 			Disabled:      v2res.Spec.Disabled,
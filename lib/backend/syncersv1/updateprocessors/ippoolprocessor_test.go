@@ -23,6 +23,7 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
 	"github.com/projectcalico/libcalico-go/lib/ipip"
 	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/vxlan"
 )
 
 var _ = Describe("Test the IPPool update processor", func() {
@@ -141,6 +142,57 @@ var _ = Describe("Test the IPPool update processor", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should convert the VXLAN mode of an IPPool", func() {
+		up := updateprocessors.NewIPPoolUpdateProcessor()
+
+		By("converting an IP Pool with VXLAN always")
+		res := apiv2.NewIPPool()
+		res.Name = v2PoolKey1.Name
+		res.Spec.CIDR = cidr1str
+		res.Spec.IPIPMode = apiv2.IPIPModeNever
+		res.Spec.VXLANMode = apiv2.VXLANModeAlways
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2PoolKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1PoolKeyCidr1,
+				Value: &model.IPPool{
+					CIDR:      v1PoolKeyCidr1.CIDR,
+					IPIPMode:  ipip.Undefined,
+					VXLANMode: vxlan.Always,
+					IPAM:      true,
+				},
+				Revision: "abcde",
+			},
+		}))
+
+		By("updating the IP Pool to VXLAN cross subnet")
+		res.Spec.VXLANMode = apiv2.VXLANModeCrossSubnet
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2PoolKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(1))
+		Expect(kvps[0].Value.(*model.IPPool).VXLANMode).To(Equal(vxlan.Mode(vxlan.CrossSubnet)))
+
+		By("updating the IP Pool to VXLAN never")
+		res.Spec.VXLANMode = apiv2.VXLANModeNever
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2PoolKey1,
+			Value:    res,
+			Revision: "abcdefg",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(1))
+		Expect(kvps[0].Value.(*model.IPPool).VXLANMode).To(Equal(vxlan.Undefined))
+	})
+
 	It("should fail to convert an invalid resource", func() {
 		up := updateprocessors.NewIPPoolUpdateProcessor()
 
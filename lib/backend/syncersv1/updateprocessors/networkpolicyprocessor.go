@@ -18,6 +18,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -27,7 +30,26 @@ import (
 // Create a new SyncerUpdateProcessor to sync NetworkPolicy data in v1 format for
 // consumption by Felix.
 func NewNetworkPolicyUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
-	return NewSimpleUpdateProcessor(apiv2.KindNetworkPolicy, convertNetworkPolicyV2ToV1Key, convertNetworkPolicyV2ToV1Value)
+	return NewNetworkPolicyUpdateProcessorWithClock(clock.RealClock{})
+}
+
+// Create a new SyncerUpdateProcessor to sync NetworkPolicy data in v1 format for
+// consumption by Felix, using the supplied clock to determine whether a policy or rule has
+// expired.  Expired policies are sent as deleted, and expired rules are removed.
+//
+// Expiry is only checked when a policy update is processed, so a policy that is not modified
+// continues to be applied after it (or one of its rules) expires.  A policyexpiry.Sweeper must
+// be running against the datastore - it deletes or updates the policy at the expiry time, and
+// that update causes the policy to be re-processed.
+func NewNetworkPolicyUpdateProcessorWithClock(clk clock.Clock) watchersyncer.SyncerUpdateProcessor {
+	return newTieredPolicyUpdateProcessor(
+		apiv2.KindNetworkPolicy,
+		convertNetworkPolicyV2ToV1Key,
+		func(val interface{}) (interface{}, error) {
+			return convertNetworkPolicyV2ToV1Value(val, clk.Now())
+		},
+		getNetworkPolicyTier,
+	)
 }
 
 func convertNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
@@ -40,11 +62,24 @@ func convertNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
 
 }
 
-func convertNetworkPolicyV2ToV1Value(val interface{}) (interface{}, error) {
+func getNetworkPolicyTier(val interface{}) (string, error) {
+	v2res, ok := val.(*apiv2.NetworkPolicy)
+	if !ok {
+		return "", errors.New("Value is not a valid NetworkPolicy resource value")
+	}
+	return v2res.Spec.Tier, nil
+}
+
+// convertNetworkPolicyV2ToV1Value converts the NetworkPolicy to a v1 policy, removing any
+// rules that have expired at the supplied time.  Returns nil if the policy has expired.
+func convertNetworkPolicyV2ToV1Value(val interface{}, now time.Time) (interface{}, error) {
 	v2res, ok := val.(*apiv2.NetworkPolicy)
 	if !ok {
 		return nil, errors.New("Value is not a valid NetworkPolicy resource value")
 	}
+	if isExpired(v2res.Spec.ExpiresAt, now) {
+		return nil, nil
+	}
 
 	// If this policy is namespaced, then add a namespace selector.
 	spec := v2res.Spec
@@ -60,8 +95,8 @@ func convertNetworkPolicyV2ToV1Value(val interface{}) (interface{}, error) {
 
 	v1value := &model.Policy{
 		Order:          spec.Order,
-		InboundRules:   RulesAPIV2ToBackend(spec.IngressRules, v2res.Namespace),
-		OutboundRules:  RulesAPIV2ToBackend(spec.EgressRules, v2res.Namespace),
+		InboundRules:   RulesAPIV2ToBackend(unexpiredRules(spec.IngressRules, now), v2res.Namespace),
+		OutboundRules:  RulesAPIV2ToBackend(unexpiredRules(spec.EgressRules, now), v2res.Namespace),
 		Selector:       selector,
 		Types:          policyTypesAPIV2ToBackend(spec.Types),
 		ApplyOnForward: true,
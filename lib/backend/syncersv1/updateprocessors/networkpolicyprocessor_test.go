@@ -15,8 +15,12 @@
 package updateprocessors_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -47,9 +51,11 @@ var _ = Describe("Test the NetworkPolicy update processor", func() {
 		Namespace: ns2,
 	}
 	v1NetworkPolicyKey1 := model.PolicyKey{
+		Tier: "default",
 		Name: ns1 + "/" + name1,
 	}
 	v1NetworkPolicyKey2 := model.PolicyKey{
+		Tier: "default",
 		Name: ns2 + "/" + name2,
 	}
 
@@ -250,4 +256,46 @@ var _ = Describe("Test the NetworkPolicy update processor", func() {
 		})
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("should filter out expired NetworkPolicies and rules", func() {
+		now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+		clk := clock.NewFakeClock(now)
+		up := updateprocessors.NewNetworkPolicyUpdateProcessorWithClock(clk)
+		expiresAt := metav1.NewTime(now.Add(time.Hour))
+		rule1 := apiv2.Rule{Action: apiv2.Allow, ExpiresAt: &expiresAt}
+
+		res := apiv2.NewNetworkPolicy()
+		res.Name = name1
+		res.Namespace = ns1
+		res.Spec.ExpiresAt = &expiresAt
+		res.Spec.EgressRules = []apiv2.Rule{rule1}
+
+		By("converting the policy before it has expired")
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2NetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(1))
+		Expect(kvps[0].Value).To(Equal(&model.Policy{
+			Selector:       "projectcalico.org/namespace == 'namespace1'",
+			OutboundRules:  []model.Rule{updateprocessors.RuleAPIV2ToBackend(rule1, ns1)},
+			ApplyOnForward: true,
+		}))
+
+		By("converting the policy when it expires")
+		clk.SetTime(expiresAt.Time)
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2NetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1NetworkPolicyKey1,
+			},
+		}))
+	})
 })
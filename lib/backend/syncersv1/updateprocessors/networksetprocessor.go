@@ -0,0 +1,78 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"errors"
+	"strings"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/k8s/conversion"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// Create a new SyncerUpdateProcessor to sync NetworkSet data in v1 format for
+// consumption by Felix.
+func NewNetworkSetUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
+	return NewSimpleUpdateProcessor(apiv2.KindNetworkSet, convertNetworkSetV2ToV1Key, convertNetworkSetV2ToV1Value)
+}
+
+func convertNetworkSetV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
+	if v2key.Name == "" || v2key.Namespace == "" {
+		return model.NetworkSetKey{}, errors.New("Missing Name or Namespace field to create a v1 NetworkSet Key")
+	}
+	return model.NetworkSetKey{
+		Name: v2key.Namespace + "/" + v2key.Name,
+	}, nil
+}
+
+func convertNetworkSetV2ToV1Value(val interface{}) (interface{}, error) {
+	v2res, ok := val.(*apiv2.NetworkSet)
+	if !ok {
+		return nil, errors.New("Value is not a valid NetworkSet resource value")
+	}
+
+	var nets []cnet.IPNet
+	for _, n := range v2res.Spec.Nets {
+		_, ipn, err := cnet.ParseCIDROrIP(n)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, *(ipn.Network()))
+	}
+
+	// Make sure there are no "namespace" labels on the network set we pass to felix, and
+	// add in the label of the owning namespace.  This prevents a network set from pretending
+	// it is in another namespace, and allows the (namespaced) selectors of the NetworkPolicy
+	// rules in the same namespace to match the set.
+	labels := map[string]string{}
+	for k, v := range v2res.GetLabels() {
+		if !strings.HasPrefix(k, conversion.NamespaceLabelPrefix) {
+			labels[k] = v
+		}
+	}
+	labels[apiv2.LabelNamespace] = v2res.Namespace
+
+	// The namespace profile supplies the namespace labels used to match NamespaceSelectors.
+	v1value := &model.NetworkSet{
+		Nets:       nets,
+		Labels:     labels,
+		ProfileIDs: []string{conversion.NamespaceProfileNamePrefix + v2res.Namespace},
+	}
+
+	return v1value, nil
+}
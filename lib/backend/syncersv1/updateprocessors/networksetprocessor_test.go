@@ -0,0 +1,159 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Test the NetworkSet update processor", func() {
+	ns1 := "namespace1"
+	name1 := "netset1"
+	v2NetworkSetKey1 := model.ResourceKey{
+		Kind:      apiv2.KindNetworkSet,
+		Name:      name1,
+		Namespace: ns1,
+	}
+	v1NetworkSetKey1 := model.NetworkSetKey{
+		Name: ns1 + "/" + name1,
+	}
+
+	It("should handle conversion of valid NetworkSets", func() {
+		up := updateprocessors.NewNetworkSetUpdateProcessor()
+
+		By("converting a NetworkSet with minimum configuration")
+		res := apiv2.NewNetworkSet()
+		res.Name = name1
+		res.Namespace = ns1
+
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2NetworkSetKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(1))
+		Expect(kvps[0]).To(Equal(&model.KVPair{
+			Key: v1NetworkSetKey1,
+			Value: &model.NetworkSet{
+				Labels:     map[string]string{apiv2.LabelNamespace: ns1},
+				ProfileIDs: []string{"kns." + ns1},
+			},
+			Revision: "abcde",
+		}))
+
+		By("converting a NetworkSet with nets and labels")
+		res = apiv2.NewNetworkSet()
+		res.Name = name1
+		res.Namespace = ns1
+		res.Labels = map[string]string{
+			"testLabel":          "label",
+			"pcns.spoofed":       "value",
+			apiv2.LabelNamespace: "spoofed-namespace",
+		}
+		res.Spec.Nets = []string{"10.100.10.1", "192.168.1.0/24", "aa:bb::1/120"}
+
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2NetworkSetKey1,
+			Value:    res,
+			Revision: "1234",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1NetworkSetKey1,
+				Value: &model.NetworkSet{
+					Nets: []net.IPNet{
+						net.MustParseCIDR("10.100.10.1/32"),
+						net.MustParseCIDR("192.168.1.0/24"),
+						net.MustParseCIDR("aa:bb::/120"),
+					},
+					Labels: map[string]string{
+						"testLabel":          "label",
+						apiv2.LabelNamespace: ns1,
+					},
+					ProfileIDs: []string{"kns." + ns1},
+				},
+				Revision: "1234",
+			},
+		}))
+
+		By("deleting the NetworkSet")
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2NetworkSetKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1NetworkSetKey1,
+			},
+		}))
+	})
+
+	It("should fail to convert an invalid resource", func() {
+		up := updateprocessors.NewNetworkSetUpdateProcessor()
+
+		By("trying to convert with the wrong key type")
+		res := apiv2.NewNetworkSet()
+
+		_, err := up.Process(&model.KVPair{
+			Key: model.GlobalBGPPeerKey{
+				PeerIP: net.MustParseIP("1.2.3.4"),
+			},
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).To(HaveOccurred())
+
+		By("trying to convert with the wrong value type")
+		wres := apiv2.NewHostEndpoint()
+
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2NetworkSetKey1,
+			Value:    wres,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1NetworkSetKey1,
+			},
+		}))
+
+		By("trying to convert with an invalid net")
+		res = apiv2.NewNetworkSet()
+		res.Name = name1
+		res.Namespace = ns1
+		res.Spec.Nets = []string{"not-a-cidr"}
+
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2NetworkSetKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1NetworkSetKey1,
+			},
+		}))
+	})
+})
@@ -109,6 +109,22 @@ func RuleAPIV2ToBackend(ar apiv2.Rule, ns string) model.Rule {
 		}
 	}
 
+	// Restrict the selectors to the matching service accounts, if specified.
+	if saSelector := parseServiceAccounts(ar.Source.ServiceAccounts); saSelector != "" {
+		if srcSelector != "" {
+			srcSelector = fmt.Sprintf("(%s) && (%s)", srcSelector, saSelector)
+		} else {
+			srcSelector = saSelector
+		}
+	}
+	if saSelector := parseServiceAccounts(ar.Destination.ServiceAccounts); saSelector != "" {
+		if dstSelector != "" {
+			dstSelector = fmt.Sprintf("(%s) && (%s)", dstSelector, saSelector)
+		} else {
+			dstSelector = saSelector
+		}
+	}
+
 	return model.Rule{
 		Action:      ruleActionAPIV2ToBackend(ar.Action),
 		IPVersion:   ar.IPVersion,
@@ -132,7 +148,51 @@ func RuleAPIV2ToBackend(ar apiv2.Rule, ns string) model.Rule {
 		NotDstNets:     normalizeIPNets(ar.Destination.NotNets),
 		NotDstSelector: ar.Destination.NotSelector,
 		NotDstPorts:    ar.Destination.NotPorts,
+
+		HTTPMatch: httpMatchAPIV2ToBackend(ar.HTTP),
+
+		LogPrefix:    logPrefixAPIV2ToBackend(ar.LogOptions),
+		LogRateLimit: logRateLimitAPIV2ToBackend(ar.LogOptions),
+	}
+}
+
+// httpMatchAPIV2ToBackend converts the rule HTTP match criteria from the API value to the
+// equivalent backend value.
+func httpMatchAPIV2ToBackend(m *apiv2.HTTPMatch) *model.HTTPMatch {
+	if m == nil {
+		return nil
+	}
+	bm := &model.HTTPMatch{
+		Methods: m.Methods,
+	}
+	for _, p := range m.Paths {
+		bm.Paths = append(bm.Paths, model.HTTPPath{Exact: p.Exact, Prefix: p.Prefix})
+	}
+	return bm
+}
+
+// logPrefixAPIV2ToBackend returns the log prefix from the API log options.
+func logPrefixAPIV2ToBackend(o *apiv2.LogOptions) string {
+	if o == nil {
+		return ""
 	}
+	return o.Prefix
+}
+
+// logRateLimitAPIV2ToBackend converts the rate limit in the API log options to the
+// equivalent backend value.
+func logRateLimitAPIV2ToBackend(o *apiv2.LogOptions) *model.LogRateLimit {
+	if o == nil || o.RateLimit == nil {
+		return nil
+	}
+	l := &model.LogRateLimit{
+		Rate: o.RateLimit.Rate,
+		Unit: strings.ToLower(string(o.RateLimit.Unit)),
+	}
+	if o.Burst != nil {
+		l.Burst = *o.Burst
+	}
+	return l
 }
 
 // parseNamespaceSelector takes a v2 namespace selector and returns the appropriate v1 representation
@@ -149,6 +209,44 @@ func parseNamespaceSelector(s string) string {
 	return updated
 }
 
+// parseServiceAccounts takes a v2 service account match and returns the equivalent v1 selector.
+// Names are matched against the service account label added to each workload endpoint, and the
+// selector is matched against the service account labels (prefixed with `pcsa.`) which are
+// inherited from the service account profile.  If the selector cannot be parsed, a selector
+// that matches nothing is returned so that the rule fails closed.
+func parseServiceAccounts(sam *apiv2.ServiceAccountMatch) string {
+	if sam == nil {
+		return ""
+	}
+
+	var selectors []string
+	if len(sam.Names) > 0 {
+		names := make([]string, len(sam.Names))
+		for i, n := range sam.Names {
+			names[i] = fmt.Sprintf("'%s'", n)
+		}
+		selectors = append(selectors, fmt.Sprintf("%s in {%s}", apiv2.LabelServiceAccount, strings.Join(names, ", ")))
+	}
+	if sam.Selector != "" {
+		parsedSelector, err := parser.Parse(sam.Selector)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to parse service account selector: %s", sam.Selector)
+			return "!all()"
+		}
+		parsedSelector.AcceptVisitor(parser.PrefixVisitor{Prefix: conversion.ServiceAccountLabelPrefix})
+		selectors = append(selectors, parsedSelector.String())
+	}
+
+	switch len(selectors) {
+	case 0:
+		return ""
+	case 1:
+		return selectors[0]
+	default:
+		return fmt.Sprintf("(%s) && (%s)", selectors[0], selectors[1])
+	}
+}
+
 // normalizeIPNet converts an IPNet to a network by ensuring the IP address is correctly masked.
 func normalizeIPNet(n string) *cnet.IPNet {
 	if n == "" {
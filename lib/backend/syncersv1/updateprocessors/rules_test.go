@@ -5,9 +5,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/selector"
 )
 
 var _ = Describe("Test the Rules Conversion Functions", func() {
@@ -188,6 +190,34 @@ var _ = Describe("Test the Rules Conversion Functions", func() {
 		Expect(rulev1.NotDstPorts).To(Equal([]numorstring.Port{port443}))
 	})
 
+	It("should convert the HTTP match criteria", func() {
+		tcp := numorstring.ProtocolFromString("tcp")
+		r := apiv2.Rule{
+			Action:   apiv2.Allow,
+			Protocol: &tcp,
+			HTTP: &apiv2.HTTPMatch{
+				Methods: []string{"GET", "POST"},
+				Paths: []apiv2.HTTPPath{
+					{Exact: "/foo"},
+					{Prefix: "/bar/"},
+				},
+			},
+		}
+
+		rulev1 := updateprocessors.RuleAPIV2ToBackend(r, "")
+		Expect(rulev1.HTTPMatch).To(Equal(&model.HTTPMatch{
+			Methods: []string{"GET", "POST"},
+			Paths: []model.HTTPPath{
+				{Exact: "/foo"},
+				{Prefix: "/bar/"},
+			},
+		}))
+
+		By("leaving the HTTP match criteria empty if not specified")
+		rulev1 = updateprocessors.RuleAPIV2ToBackend(apiv2.Rule{Action: apiv2.Allow}, "")
+		Expect(rulev1.HTTPMatch).To(BeNil())
+	})
+
 	It("should parse a profile rule with no namespace", func() {
 		r := apiv2.Rule{
 			Action: apiv2.Allow,
@@ -264,4 +294,73 @@ var _ = Describe("Test the Rules Conversion Functions", func() {
 		})
 	})
 
+	It("should parse a rule with service account matches", func() {
+		r := apiv2.Rule{
+			Action: apiv2.Allow,
+			Source: apiv2.EntityRule{
+				Selector: "has(label1)",
+				ServiceAccounts: &apiv2.ServiceAccountMatch{
+					Names: []string{"sa1", "sa2"},
+				},
+			},
+			Destination: apiv2.EntityRule{
+				ServiceAccounts: &apiv2.ServiceAccountMatch{
+					Names:    []string{"sa3"},
+					Selector: "role == 'db'",
+				},
+			},
+		}
+
+		// Process the rule and get the corresponding v1 representation.
+		rulev1 := updateprocessors.RuleAPIV2ToBackend(r, "namespace")
+
+		By("generating the correct source selector", func() {
+			Expect(rulev1.SrcSelector).To(Equal(
+				"((projectcalico.org/namespace == 'namespace') && (has(label1))) && " +
+					"(projectcalico.org/serviceaccount in {'sa1', 'sa2'})"))
+		})
+
+		By("generating the correct destination selector", func() {
+			Expect(rulev1.DstSelector).To(Equal(
+				"(projectcalico.org/namespace == 'namespace') && " +
+					"((projectcalico.org/serviceaccount in {'sa3'}) && (pcsa.role == \"db\"))"))
+		})
+	})
+
+	It("should not match any endpoints if the service account selector is invalid", func() {
+		r := apiv2.Rule{
+			Action: apiv2.Allow,
+			Source: apiv2.EntityRule{
+				ServiceAccounts: &apiv2.ServiceAccountMatch{
+					Names:    []string{"sa1"},
+					Selector: "role == ",
+				},
+			},
+		}
+
+		rulev1 := updateprocessors.RuleAPIV2ToBackend(r, "")
+		Expect(rulev1.SrcSelector).To(Equal("!all()"))
+		sel, err := selector.Parse(rulev1.SrcSelector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sel.Evaluate(map[string]string{"projectcalico.org/serviceaccount": "sa1"})).To(BeFalse())
+	})
+
+	It("should convert the log options", func() {
+		burst := uint32(5)
+		r := apiv2.Rule{
+			Action: apiv2.Log,
+			LogOptions: &apiv2.LogOptions{
+				Prefix:    "foo",
+				RateLimit: &apiv2.LogRateLimit{Rate: 10, Unit: apiv2.LogRateLimitUnitMinute},
+				Burst:     &burst,
+			},
+		}
+
+		rulev1 := updateprocessors.RuleAPIV2ToBackend(r, "")
+		Expect(rulev1.Action).To(Equal("log"))
+		Expect(rulev1.LogPrefix).To(Equal("foo"))
+		Expect(rulev1.LogRateLimit).To(Equal(&model.LogRateLimit{Rate: 10, Unit: "minute", Burst: 5}))
+		Expect(rulev1.String()).To(Equal("log log-prefix \"foo\" log-rate-limit 10/minute burst 5"))
+	})
+
 })
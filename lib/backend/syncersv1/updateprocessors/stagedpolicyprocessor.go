@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+)
+
+// Create a new SyncerUpdateProcessor to sync StagedGlobalNetworkPolicy data in v1 format for
+// consumption by Felix.  Staged policies use a StagedPolicyKey so that they are not enforced.
+func NewStagedGlobalNetworkPolicyUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
+	return newTieredPolicyUpdateProcessor(
+		apiv2.KindStagedGlobalNetworkPolicy,
+		convertStagedGlobalNetworkPolicyV2ToV1Key,
+		func(val interface{}) (interface{}, error) {
+			return convertStagedGlobalNetworkPolicyV2ToV1Value(val, time.Now())
+		},
+		getStagedGlobalNetworkPolicyTier,
+	)
+}
+
+// Create a new SyncerUpdateProcessor to sync StagedNetworkPolicy data in v1 format for
+// consumption by Felix.  Staged policies use a StagedPolicyKey so that they are not enforced.
+func NewStagedNetworkPolicyUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
+	return newTieredPolicyUpdateProcessor(
+		apiv2.KindStagedNetworkPolicy,
+		convertStagedNetworkPolicyV2ToV1Key,
+		func(val interface{}) (interface{}, error) {
+			return convertStagedNetworkPolicyV2ToV1Value(val, time.Now())
+		},
+		getStagedNetworkPolicyTier,
+	)
+}
+
+func convertStagedGlobalNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
+	if v2key.Name == "" {
+		return model.StagedPolicyKey{}, errors.New("Missing Name field to create a v1 StagedPolicy Key")
+	}
+	return model.StagedPolicyKey{
+		Name: v2key.Name,
+	}, nil
+}
+
+func convertStagedNetworkPolicyV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
+	if v2key.Name == "" || v2key.Namespace == "" {
+		return model.StagedPolicyKey{}, errors.New("Missing Name or Namespace field to create a v1 StagedPolicy Key")
+	}
+	return model.StagedPolicyKey{
+		Name: v2key.Namespace + "/" + v2key.Name,
+	}, nil
+}
+
+func getStagedGlobalNetworkPolicyTier(val interface{}) (string, error) {
+	v2res, ok := val.(*apiv2.StagedGlobalNetworkPolicy)
+	if !ok {
+		return "", errors.New("Value is not a valid StagedGlobalNetworkPolicy resource value")
+	}
+	return v2res.Spec.Tier, nil
+}
+
+func getStagedNetworkPolicyTier(val interface{}) (string, error) {
+	v2res, ok := val.(*apiv2.StagedNetworkPolicy)
+	if !ok {
+		return "", errors.New("Value is not a valid StagedNetworkPolicy resource value")
+	}
+	return v2res.Spec.Tier, nil
+}
+
+func convertStagedGlobalNetworkPolicyV2ToV1Value(val interface{}, now time.Time) (interface{}, error) {
+	v2res, ok := val.(*apiv2.StagedGlobalNetworkPolicy)
+	if !ok {
+		return nil, errors.New("Value is not a valid StagedGlobalNetworkPolicy resource value")
+	}
+	action, enforced := apiv2.ConvertStagedGlobalPolicyToEnforced(v2res)
+	policy, err := convertGlobalPolicyV2ToV1Spec(enforced.Spec, now)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	return stagedPolicy(action, policy), nil
+}
+
+func convertStagedNetworkPolicyV2ToV1Value(val interface{}, now time.Time) (interface{}, error) {
+	v2res, ok := val.(*apiv2.StagedNetworkPolicy)
+	if !ok {
+		return nil, errors.New("Value is not a valid StagedNetworkPolicy resource value")
+	}
+	action, enforced := apiv2.ConvertStagedPolicyToEnforced(v2res)
+	policy, err := convertNetworkPolicyV2ToV1Value(enforced, now)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	return stagedPolicy(action, policy.(*model.Policy)), nil
+}
+
+// stagedPolicy returns the v1 staged policy value for the supplied staged action and policy.
+// A staged delete only needs the action, so the policy is omitted.
+func stagedPolicy(action apiv2.StagedAction, policy *model.Policy) *model.StagedPolicy {
+	v1value := &model.StagedPolicy{
+		StagedAction: strings.ToLower(string(action)),
+	}
+	if action != apiv2.StagedActionDelete {
+		v1value.Policy = *policy
+	}
+	return v1value
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+)
+
+var _ = Describe("Test the staged policy update processors", func() {
+	order := float64(101)
+
+	v2StagedGlobalNetworkPolicyKey1 := model.ResourceKey{
+		Kind: apiv2.KindStagedGlobalNetworkPolicy,
+		Name: "name1",
+	}
+	v1StagedGlobalNetworkPolicyKey1 := model.StagedPolicyKey{
+		Tier: "default",
+		Name: "name1",
+	}
+	v2StagedNetworkPolicyKey1 := model.ResourceKey{
+		Kind:      apiv2.KindStagedNetworkPolicy,
+		Name:      "name1",
+		Namespace: "ns1",
+	}
+	v1StagedNetworkPolicyKey1 := model.StagedPolicyKey{
+		Tier: "default",
+		Name: "ns1/name1",
+	}
+
+	It("should convert a StagedGlobalNetworkPolicy to a v1 staged policy", func() {
+		up := updateprocessors.NewStagedGlobalNetworkPolicyUpdateProcessor()
+
+		By("converting a staged policy with no staged action")
+		res := apiv2.NewStagedGlobalNetworkPolicy()
+		res.Spec.Order = &order
+		res.Spec.Selector = "has(foo)"
+		res.Spec.Types = []apiv2.PolicyType{apiv2.PolicyTypeIngress}
+		res.Spec.PreDNAT = true
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2StagedGlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1StagedGlobalNetworkPolicyKey1,
+				Value: &model.StagedPolicy{
+					StagedAction: "set",
+					Policy: model.Policy{
+						Order:    &order,
+						Selector: "has(foo)",
+						Types:    []string{"ingress"},
+						PreDNAT:  true,
+					},
+				},
+				Revision: "abcde",
+			},
+		}))
+
+		By("converting a staged delete in a non-default tier")
+		res = apiv2.NewStagedGlobalNetworkPolicy()
+		res.Spec.StagedAction = apiv2.StagedActionDelete
+		res.Spec.Tier = "tier1"
+		res.Spec.Selector = "has(foo)"
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2StagedGlobalNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1StagedGlobalNetworkPolicyKey1,
+			},
+			{
+				Key:      model.StagedPolicyKey{Tier: "tier1", Name: "name1"},
+				Value:    &model.StagedPolicy{StagedAction: "delete"},
+				Revision: "abcdef",
+			},
+		}))
+
+		By("deleting the staged policy")
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2StagedGlobalNetworkPolicyKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: model.StagedPolicyKey{Tier: "tier1", Name: "name1"},
+			},
+		}))
+	})
+
+	It("should convert a StagedNetworkPolicy to a v1 staged policy", func() {
+		up := updateprocessors.NewStagedNetworkPolicyUpdateProcessor()
+
+		res := apiv2.NewStagedNetworkPolicy()
+		res.Name = "name1"
+		res.Namespace = "ns1"
+		res.Spec.Selector = "has(foo)"
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2StagedNetworkPolicyKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1StagedNetworkPolicyKey1,
+				Value: &model.StagedPolicy{
+					StagedAction: "set",
+					Policy: model.Policy{
+						Selector:       "(has(foo)) && projectcalico.org/namespace == 'ns1'",
+						ApplyOnForward: true,
+					},
+				},
+				Revision: "abcde",
+			},
+		}))
+	})
+
+	It("should fail to convert an invalid resource", func() {
+		up := updateprocessors.NewStagedGlobalNetworkPolicyUpdateProcessor()
+
+		By("trying to convert with the wrong key type")
+		_, err := up.Process(&model.KVPair{
+			Key:      model.ResourceKey{Kind: apiv2.KindGlobalNetworkPolicy, Name: "name1"},
+			Value:    apiv2.NewStagedGlobalNetworkPolicy(),
+			Revision: "abcde",
+		})
+		Expect(err).To(HaveOccurred())
+
+		By("trying to convert with the wrong value type")
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2StagedGlobalNetworkPolicyKey1,
+			Value:    apiv2.NewGlobalNetworkPolicy(),
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1StagedGlobalNetworkPolicyKey1,
+			},
+		}))
+	})
+})
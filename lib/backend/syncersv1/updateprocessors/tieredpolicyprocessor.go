@@ -0,0 +1,146 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+)
+
+// Function to extract the tier name from a v2 policy resource.
+type getV2PolicyTier func(interface{}) (string, error)
+
+// newTieredPolicyUpdateProcessor implements an update processor for the policy resource
+// types.  The v1 policy key includes the tier of the policy, but the tier is part of the
+// policy spec rather than the resource key, so we cannot build the v1 key from the v2 key
+// alone.  This processor caches the tier of each policy so that we are able to send the
+// correct v1 key on deletion, and so that we can remove the policy from its old tier if the
+// tier is modified.
+func newTieredPolicyUpdateProcessor(
+	v2Kind string, kConverter ConvertV2ToV1Key, vConverter ConvertV2ToV1Value, tConverter getV2PolicyTier,
+) watchersyncer.SyncerUpdateProcessor {
+	return &tieredPolicyUpdateProcessor{
+		v2Kind:         v2Kind,
+		keyConverter:   kConverter,
+		valueConverter: vConverter,
+		tierConverter:  tConverter,
+		tiers:          make(map[model.ResourceKey]string),
+	}
+}
+
+type tieredPolicyUpdateProcessor struct {
+	v2Kind         string
+	keyConverter   ConvertV2ToV1Key
+	valueConverter ConvertV2ToV1Value
+	tierConverter  getV2PolicyTier
+	tiers          map[model.ResourceKey]string
+}
+
+func (tup *tieredPolicyUpdateProcessor) Process(kvp *model.KVPair) ([]*model.KVPair, error) {
+	// Check the v2 resource is the correct type.
+	v2key, ok := kvp.Key.(model.ResourceKey)
+	if !ok || v2key.Kind != tup.v2Kind {
+		return nil, fmt.Errorf("Incorrect key type - expecting resource of kind %s", tup.v2Kind)
+	}
+
+	// Convert the v2 key to the v1 policy key (enforced or staged) and check that it is a
+	// valid policy key.  The tier is filled in below.
+	v1key, err := tup.keyConverter(v2key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policyKeyWithTier(v1key, ""); err != nil {
+		return nil, err
+	}
+
+	// Look up the tier we last sent for this policy.  If we have not seen this policy before
+	// then assume the default tier - a delete for a policy that we have not sent is harmless.
+	prevTier, known := tup.tiers[v2key]
+	if !known {
+		prevTier = apiv2.DefaultTierName
+	}
+
+	// Deletion events will have a value of nil.  Send a delete using the cached tier.
+	if kvp.Value == nil {
+		delete(tup.tiers, v2key)
+		v1key, _ = policyKeyWithTier(v1key, prevTier)
+		return []*model.KVPair{{Key: v1key}}, nil
+	}
+
+	tier, err := tup.tierConverter(kvp.Value)
+	if err != nil {
+		// Treat any values that fail to convert properly as a deletion event.
+		log.WithField("Resource", kvp.Key).Warn("Unable to process resource data - treating as deleted")
+		delete(tup.tiers, v2key)
+		v1key, _ = policyKeyWithTier(v1key, prevTier)
+		return []*model.KVPair{{Key: v1key}}, nil
+	}
+	if tier == "" {
+		tier = apiv2.DefaultTierName
+	}
+	tup.tiers[v2key] = tier
+	prevKey, _ := policyKeyWithTier(v1key, prevTier)
+	v1key, _ = policyKeyWithTier(v1key, tier)
+
+	// If the policy has moved tier then delete the policy from the previous tier.
+	var kvps []*model.KVPair
+	if known && prevTier != tier {
+		log.WithField("Resource", kvp.Key).Debugf("Policy moved from tier %s to tier %s", prevTier, tier)
+		kvps = append(kvps, &model.KVPair{Key: prevKey})
+	}
+
+	v1value, err := tup.valueConverter(kvp.Value)
+	if err != nil {
+		log.WithField("Resource", kvp.Key).Warn("Unable to process resource data - treating as deleted")
+		return append(kvps, &model.KVPair{Key: v1key}), nil
+	}
+	if v1value == nil {
+		log.WithField("Resource", kvp.Key).Debug("Filtering out resource - treating as deleted")
+		return append(kvps, &model.KVPair{Key: v1key}), nil
+	}
+
+	return append(kvps, &model.KVPair{
+		Key:      v1key,
+		Value:    v1value,
+		Revision: kvp.Revision,
+	}), nil
+}
+
+// OnSyncerStarting is called when syncer is starting a full sync for the associated
+// resource types.  Clear our tier cache since we will be getting a full resync.
+func (tup *tieredPolicyUpdateProcessor) OnSyncerStarting() {
+	log.Debug("Resetting the policy tier cache")
+	tup.tiers = make(map[model.ResourceKey]string)
+}
+
+// policyKeyWithTier returns a copy of the supplied v1 policy key (either a PolicyKey or a
+// StagedPolicyKey) with the tier set to the supplied tier.
+func policyKeyWithTier(key model.Key, tier string) (model.Key, error) {
+	switch k := key.(type) {
+	case model.PolicyKey:
+		k.Tier = tier
+		return k, nil
+	case model.StagedPolicyKey:
+		k.Tier = tier
+		return k, nil
+	}
+	return nil, errors.New("Key does not convert to a valid v1 policy key")
+}
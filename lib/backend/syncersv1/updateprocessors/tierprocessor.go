@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors
+
+import (
+	"errors"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+)
+
+// Create a new SyncerUpdateProcessor to sync Tier data in v1 format for
+// consumption by Felix.
+func NewTierUpdateProcessor() watchersyncer.SyncerUpdateProcessor {
+	return NewSimpleUpdateProcessor(apiv2.KindTier, convertTierV2ToV1Key, convertTierV2ToV1Value)
+}
+
+func convertTierV2ToV1Key(v2key model.ResourceKey) (model.Key, error) {
+	if v2key.Name == "" {
+		return model.TierKey{}, errors.New("Missing Name field to create a v1 Tier Key")
+	}
+	return model.TierKey{
+		Name: v2key.Name,
+	}, nil
+}
+
+func convertTierV2ToV1Value(val interface{}) (interface{}, error) {
+	v2res, ok := val.(*apiv2.Tier)
+	if !ok {
+		return nil, errors.New("Value is not a valid Tier resource value")
+	}
+	return &model.Tier{
+		Order: v2res.Spec.Order,
+	}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updateprocessors_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Test the Tier update processor", func() {
+	v2TierKey1 := model.ResourceKey{
+		Kind: apiv2.KindTier,
+		Name: "tier1",
+	}
+	v1TierKey1 := model.TierKey{
+		Name: "tier1",
+	}
+
+	It("should handle conversion of valid Tiers", func() {
+		up := updateprocessors.NewTierUpdateProcessor()
+
+		By("converting a Tier with minimum configuration")
+		res := apiv2.NewTier()
+		res.Name = v2TierKey1.Name
+
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v2TierKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key:      v1TierKey1,
+				Value:    &model.Tier{},
+				Revision: "abcde",
+			},
+		}))
+
+		By("updating the Tier with an order")
+		order := float64(10.5)
+		res = apiv2.NewTier()
+		res.Name = v2TierKey1.Name
+		res.Spec.Order = &order
+
+		kvps, err = up.Process(&model.KVPair{
+			Key:      v2TierKey1,
+			Value:    res,
+			Revision: "1234",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1TierKey1,
+				Value: &model.Tier{
+					Order: &order,
+				},
+				Revision: "1234",
+			},
+		}))
+
+		By("deleting the Tier")
+		kvps, err = up.Process(&model.KVPair{
+			Key: v2TierKey1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(Equal([]*model.KVPair{
+			{
+				Key: v1TierKey1,
+			},
+		}))
+	})
+
+	It("should fail to convert an invalid resource", func() {
+		up := updateprocessors.NewTierUpdateProcessor()
+
+		By("trying to convert with the wrong key type")
+		_, err := up.Process(&model.KVPair{
+			Key: model.GlobalBGPPeerKey{
+				PeerIP: net.MustParseIP("1.2.3.4"),
+			},
+			Value:    apiv2.NewTier(),
+			Revision: "abcde",
+		})
+		Expect(err).To(HaveOccurred())
+
+		By("trying to convert without enough information to create a v1 key")
+		_, err = up.Process(&model.KVPair{
+			Key:      model.ResourceKey{Kind: apiv2.KindTier},
+			Value:    apiv2.NewTier(),
+			Revision: "abcde",
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -26,6 +26,7 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -103,29 +104,47 @@ func (c *Client) BGPPeers() BGPPeerInterface {
 
 // IPAM returns an interface for managing IP address assignment and releasing.
 func (c *Client) IPAM() ipam.Interface {
-	return ipam.NewIPAMClient(c.Backend, poolAccessor{})
+	return ipam.NewIPAMClient(c.Backend, poolAccessor{client: c}, nil)
 }
 
 type poolAccessor struct {
 	client *Client
 }
 
-func (p poolAccessor) GetEnabledPools(ipVersion int) ([]net.IPNet, error) {
-	pools, err := p.client.IPPools().List(api.IPPoolMetadata{})
+func (p poolAccessor) GetEnabledPools(ipVersion int) ([]apiv2.IPPool, error) {
+	pools, err := p.GetAllPools()
 	if err != nil {
 		return nil, err
 	}
-	enabled := []net.IPNet{}
-	for _, pool := range pools.Items {
+	enabled := []apiv2.IPPool{}
+	for _, pool := range pools {
 		if pool.Spec.Disabled {
 			continue
-		} else {
-			enabled = append(enabled, pool.Metadata.CIDR)
+		} else if _, cidr, err := net.ParseCIDR(pool.Spec.CIDR); err == nil && cidr.Version() == ipVersion {
+			enabled = append(enabled, pool)
 		}
 	}
 	return enabled, nil
 }
 
+// GetAllPools returns all of the v1 IP pools, converted to the v2 IPPool format used by
+// the IPAM client.  The v1 IP pools do not have a configurable block size.
+func (p poolAccessor) GetAllPools() ([]apiv2.IPPool, error) {
+	pools, err := p.client.IPPools().List(api.IPPoolMetadata{})
+	if err != nil {
+		return nil, err
+	}
+	all := []apiv2.IPPool{}
+	for _, pool := range pools.Items {
+		v2pool := apiv2.NewIPPool()
+		v2pool.Name = pool.Metadata.CIDR.String()
+		v2pool.Spec.CIDR = pool.Metadata.CIDR.String()
+		v2pool.Spec.Disabled = pool.Spec.Disabled
+		all = append(all, *v2pool)
+	}
+	return all, nil
+}
+
 // Config returns an interface for managing system configuration..
 func (c *Client) Config() ConfigInterface {
 	return newConfigs(c)
@@ -53,6 +53,12 @@ var _ = testutils.E2eDatastoreDescribe("BGPConfiguration tests", testutils.Datas
 		LogSeverityScreen:     "Warning",
 		NodeToNodeMeshEnabled: &ptrFalse,
 		ASNumber:              &nodeASNumber2,
+		ServiceClusterIPs: []apiv2.ServiceClusterIPBlock{
+			{CIDR: "10.96.0.0/12"},
+		},
+		ServiceExternalIPs: []apiv2.ServiceExternalIPBlock{
+			{CIDR: "172.16.10.0/24"},
+		},
 	}
 	specInfo := apiv2.BGPConfigurationSpec{
 		LogSeverityScreen: "Info",
@@ -302,6 +308,15 @@ var _ = testutils.E2eDatastoreDescribe("BGPConfiguration tests", testutils.Datas
 				Spec:       specDefault1,
 			}, options.SetOptions{})
 			Expect(outError).To(HaveOccurred())
+
+			By("Attempting to create a non-default BGPConfiguration with service cluster IPs")
+			_, outError = c.BGPConfigurations().Create(ctx, &apiv2.BGPConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "not-default"},
+				Spec: apiv2.BGPConfigurationSpec{
+					ServiceClusterIPs: []apiv2.ServiceClusterIPBlock{{CIDR: "10.96.0.0/12"}},
+				},
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
 		},
 
 		// Test 1: Pass two fully populated BGPConfigurationSpecs and expect the series of operations to succeed.
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// BGPFilterInterface has methods to work with BGPFilter resources.
+type BGPFilterInterface interface {
+	Create(ctx context.Context, res *apiv2.BGPFilter, opts options.SetOptions) (*apiv2.BGPFilter, error)
+	Update(ctx context.Context, res *apiv2.BGPFilter, opts options.SetOptions) (*apiv2.BGPFilter, error)
+	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.BGPFilter, error)
+	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.BGPFilter, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.BGPFilterList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+}
+
+// bgpFilters implements BGPFilterInterface
+type bgpFilters struct {
+	client client
+}
+
+// Create takes the representation of a BGPFilter and creates it.  Returns the stored
+// representation of the BGPFilter, and an error, if there is any.
+func (r bgpFilters) Create(ctx context.Context, res *apiv2.BGPFilter, opts options.SetOptions) (*apiv2.BGPFilter, error) {
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindBGPFilter, res)
+	if out != nil {
+		return out.(*apiv2.BGPFilter), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a BGPFilter and updates it. Returns the stored
+// representation of the BGPFilter, and an error, if there is any.
+func (r bgpFilters) Update(ctx context.Context, res *apiv2.BGPFilter, opts options.SetOptions) (*apiv2.BGPFilter, error) {
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindBGPFilter, res)
+	if out != nil {
+		return out.(*apiv2.BGPFilter), err
+	}
+	return nil, err
+}
+
+// Delete takes name of the BGPFilter and deletes it. Returns an error if one occurs.
+func (r bgpFilters) Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.BGPFilter, error) {
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindBGPFilter, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.BGPFilter), err
+	}
+	return nil, err
+}
+
+// Get takes name of the BGPFilter, and returns the corresponding BGPFilter object,
+// and an error if there is any.
+func (r bgpFilters) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.BGPFilter, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindBGPFilter, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.BGPFilter), err
+	}
+	return nil, err
+}
+
+// List returns the list of BGPFilter objects that match the supplied options.
+func (r bgpFilters) List(ctx context.Context, opts options.ListOptions) (*apiv2.BGPFilterList, error) {
+	res := &apiv2.BGPFilterList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindBGPFilter, apiv2.KindBGPFilterList, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the BGPFilters that match the
+// supplied options.
+func (r bgpFilters) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindBGPFilter)
+}
@@ -20,6 +20,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/ipam"
@@ -34,6 +35,9 @@ type client struct {
 
 	// The resources client used internally.
 	resources resourceInterface
+
+	// The cache of IP reservations used by IPAM.
+	reservations *reservationCache
 }
 
 // New returns a connected client. The ClientConfig can either be created explicitly,
@@ -43,9 +47,11 @@ func New(config apiconfig.CalicoAPIConfig) (Interface, error) {
 	if err != nil {
 		return nil, err
 	}
+	res := &resources{backend: be}
 	return client{
-		backend:   be,
-		resources: &resources{backend: be},
+		backend:      be,
+		resources:    res,
+		reservations: newReservationCache(res),
 	}, nil
 }
 
@@ -75,6 +81,26 @@ func (c client) GlobalNetworkPolicies() GlobalNetworkPolicyInterface {
 	return globalnetworkpolicies{client: c}
 }
 
+// StagedGlobalNetworkPolicies returns an interface for managing staged global network policy resources.
+func (c client) StagedGlobalNetworkPolicies() StagedGlobalNetworkPolicyInterface {
+	return stagedGlobalNetworkPolicies{client: c}
+}
+
+// StagedNetworkPolicies returns an interface for managing staged namespaced network policy resources.
+func (c client) StagedNetworkPolicies() StagedNetworkPolicyInterface {
+	return stagedNetworkPolicies{client: c}
+}
+
+// Tiers returns an interface for managing policy tier resources.
+func (c client) Tiers() TierInterface {
+	return tiers{client: c}
+}
+
+// NetworkSets returns an interface for managing namespaced network set resources.
+func (c client) NetworkSets() NetworkSetInterface {
+	return networkSets{client: c}
+}
+
 // IPPools returns an interface for managing IP pool resources.
 func (c client) IPPools() IPPoolInterface {
 	return ipPools{client: c}
@@ -100,9 +126,30 @@ func (c client) BGPPeers() BGPPeerInterface {
 	return bgpPeers{client: c}
 }
 
+// BGPFilters returns an interface for managing BGP filter resources.
+func (c client) BGPFilters() BGPFilterInterface {
+	return bgpFilters{client: c}
+}
+
+// NodeStatuses returns an interface for managing node status resources.
+func (c client) NodeStatuses() NodeStatusInterface {
+	return nodeStatuses{client: c}
+}
+
+// IPReservations returns an interface for managing IP reservation resources.
+func (c client) IPReservations() IPReservationInterface {
+	return ipReservations{client: c}
+}
+
 // IPAM returns an interface for managing IP address assignment and releasing.
 func (c client) IPAM() ipam.Interface {
-	return ipam.NewIPAMClient(c.backend, poolAccessor{client: &c})
+	return ipam.NewIPAMClient(c.backend, poolAccessor{client: &c}, c.reservations)
+}
+
+// IPAMGarbageCollector returns a new garbage collector that releases IP addresses assigned
+// for workload endpoints and nodes that no longer exist.
+func (c client) IPAMGarbageCollector(opts ipam.GarbageCollectorOptions) *ipam.GarbageCollector {
+	return ipam.NewGarbageCollector(c.backend, poolAccessor{client: &c}, workloadAccessor{client: &c}, opts)
 }
 
 // BGPConfigurations returns an interface for managing the BGP configuration resources.
@@ -124,19 +171,19 @@ type poolAccessor struct {
 	client *client
 }
 
-func (p poolAccessor) GetEnabledPools(ipVersion int) ([]net.IPNet, error) {
+func (p poolAccessor) GetEnabledPools(ipVersion int) ([]apiv2.IPPool, error) {
 	pools, err := p.client.IPPools().List(context.Background(), options.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
 	log.Debugf("Got list of all IPPools: %v", pools)
-	enabled := []net.IPNet{}
+	enabled := []apiv2.IPPool{}
 	for _, pool := range pools.Items {
 		if pool.Spec.Disabled {
 			continue
 		} else if _, cidr, err := net.ParseCIDR(pool.Spec.CIDR); err == nil && cidr.Version() == ipVersion {
 			log.Debugf("Adding pool (%s) to the enabled IPPool list", cidr.String())
-			enabled = append(enabled, *cidr)
+			enabled = append(enabled, pool)
 		} else if err != nil {
 			log.Warnf("Failed to parse the IPPool: %s. Ignoring that IPPool", pool.Spec.CIDR)
 		} else {
@@ -146,6 +193,34 @@ func (p poolAccessor) GetEnabledPools(ipVersion int) ([]net.IPNet, error) {
 	return enabled, nil
 }
 
+func (p poolAccessor) GetAllPools() ([]apiv2.IPPool, error) {
+	pools, err := p.client.IPPools().List(context.Background(), options.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pools.Items, nil
+}
+
+type workloadAccessor struct {
+	client *client
+}
+
+func (w workloadAccessor) ListNodes(ctx context.Context) ([]apiv2.Node, error) {
+	nodes, err := w.client.Nodes().List(ctx, options.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+func (w workloadAccessor) ListWorkloadEndpoints(ctx context.Context) ([]apiv2.WorkloadEndpoint, error) {
+	weps, err := w.client.WorkloadEndpoints().List(ctx, options.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return weps.Items, nil
+}
+
 // EnsureInitialized is used to ensure the backend datastore is correctly
 // initialized for use by Calico.  This method may be called multiple times, and
 // will have no effect if the datastore is already correctly initialized.
@@ -0,0 +1,221 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/backend/syncersv1/updateprocessors"
+	"github.com/projectcalico/libcalico-go/lib/backend/watchersyncer"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// ConfigSource indicates which configuration resource an effective configuration value
+// was obtained from.
+type ConfigSource string
+
+const (
+	// The value is not set in the datastore.  The component will use its locally configured
+	// value or its built-in default.
+	ConfigSourceUnset ConfigSource = "Unset"
+	// The value is from the global "default" configuration resource.
+	ConfigSourceGlobal ConfigSource = "Global"
+	// The value is from the per-node "node.<nodename>" configuration resource.
+	ConfigSourceNode ConfigSource = "Node"
+	// The value is from the component's local configuration file.
+	ConfigSourceFile ConfigSource = "File"
+	// The value is from the component's environment.
+	ConfigSourceEnv ConfigSource = "Environment"
+)
+
+// LocalConfig contains the configuration parameters that are set locally for a component,
+// outside of the datastore.  The names are the configuration parameter names used by the
+// component (e.g. "LogSeverityScreen" for the FELIX_LOGSEVERITYSCREEN environment variable),
+// and are matched case-insensitively.
+type LocalConfig struct {
+	// The parameters from the component's configuration file.
+	File map[string]string
+	// The parameters from the component's environment.  These take precedence over the
+	// parameters from the configuration file.
+	Env map[string]string
+}
+
+// EffectiveConfigValue contains the effective value of a single configuration parameter.
+type EffectiveConfigValue struct {
+	// The name of the configuration parameter, as used by the component.  This is the
+	// confignamev1 name of the Spec field, or the field name if it does not have one.
+	Name string
+	// The value of the configuration parameter, in the string format used by the
+	// component.  This is empty if the value is not set.
+	Value string
+	// The configuration resource that the value was obtained from.
+	Source ConfigSource
+}
+
+// EffectiveFelixConfig returns the Felix configuration that applies to the specified node.
+// This merges the global "default" FelixConfiguration with the per-node "node.<nodename>"
+// FelixConfiguration in the same way as the Felix syncer: a value set on the per-node
+// resource (either in the Spec or through a config annotation) overrides the global value.
+//
+// A value is returned for every FelixConfigurationSpec field (whether or not it is set),
+// followed by any additional parameters that are only set through config annotations.
+//
+// Felix settings from its config file and environment are not visible to the client.  If
+// they are supplied in the optional local configuration, they are layered on top of the
+// datastore values in the same order as Felix applies them: config file values override the
+// datastore values, and environment values override everything else.  Local parameters that
+// are not otherwise known are returned after the datastore parameters.
+func (c client) EffectiveFelixConfig(ctx context.Context, nodeName string, local *LocalConfig) ([]EffectiveConfigValue, error) {
+	get := func(name string) (interface{}, error) {
+		res, err := c.FelixConfigurations().Get(ctx, name, options.GetOptions{})
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+			res, err = apiv2.NewFelixConfiguration(), nil
+			res.Name = name
+		}
+		return res, err
+	}
+	values, err := effectiveConfig(apiv2.KindFelixConfiguration, nodeName, updateprocessors.NewFelixConfigUpdateProcessor(), get)
+	if err != nil || local == nil {
+		return values, err
+	}
+	values = applyLocalConfig(values, local.File, ConfigSourceFile)
+	values = applyLocalConfig(values, local.Env, ConfigSourceEnv)
+	return values, nil
+}
+
+// EffectiveBGPConfig returns the BGP configuration that applies to the specified node.
+// This merges the global "default" BGPConfiguration with the per-node "node.<nodename>"
+// BGPConfiguration in the same way as the BGP syncer.  See EffectiveFelixConfig for details.
+func (c client) EffectiveBGPConfig(ctx context.Context, nodeName string) ([]EffectiveConfigValue, error) {
+	get := func(name string) (interface{}, error) {
+		res, err := c.BGPConfigurations().Get(ctx, name, options.GetOptions{})
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+			res, err = apiv2.NewBGPConfiguration(), nil
+			res.Name = name
+		}
+		return res, err
+	}
+	return effectiveConfig(apiv2.KindBGPConfiguration, nodeName, updateprocessors.NewBGPConfigUpdateProcessor(), get)
+}
+
+// configLayer is the name of a configuration resource and the source that it represents.
+type configLayer struct {
+	name   string
+	source ConfigSource
+}
+
+// effectiveConfig layers the per-node configuration resource on top of the global
+// configuration resource.  Each resource is converted using the supplied update processor
+// so that the resulting names and values are exactly those seen by the component.  A
+// missing resource is treated as an empty one.
+func effectiveConfig(
+	kind, nodeName string,
+	up watchersyncer.SyncerUpdateProcessor,
+	get func(name string) (interface{}, error),
+) ([]EffectiveConfigValue, error) {
+	layers := []configLayer{{name: "default", source: ConfigSourceGlobal}}
+	if nodeName != "" {
+		layers = append(layers, configLayer{name: "node." + nodeName, source: ConfigSourceNode})
+	}
+
+	values := []EffectiveConfigValue{}
+	indices := map[string]int{}
+	for _, layer := range layers {
+		res, err := get(layer.name)
+		if err != nil {
+			return nil, err
+		}
+		kvps, err := up.Process(&model.KVPair{
+			Key:   model.ResourceKey{Kind: kind, Name: layer.name},
+			Value: res,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, kvp := range kvps {
+			name := configKeyName(kvp.Key)
+			idx, ok := indices[name]
+			if !ok {
+				idx = len(values)
+				indices[name] = idx
+				values = append(values, EffectiveConfigValue{Name: name, Source: ConfigSourceUnset})
+			}
+			if kvp.Value == nil {
+				continue
+			}
+			log.WithFields(log.Fields{
+				"Name":   name,
+				"Source": layer.source,
+			}).Debug("Found configuration value")
+			values[idx].Value = kvp.Value.(string)
+			values[idx].Source = layer.source
+		}
+	}
+	return values, nil
+}
+
+// applyLocalConfig overrides the effective values with the supplied local parameters, which
+// are matched to the existing values case-insensitively.  Parameters that do not match an
+// existing value are appended in name order.
+func applyLocalConfig(values []EffectiveConfigValue, params map[string]string, source ConfigSource) []EffectiveConfigValue {
+	indices := map[string]int{}
+	for i, v := range values {
+		indices[strings.ToLower(v.Name)] = i
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		idx, ok := indices[strings.ToLower(name)]
+		if !ok {
+			idx = len(values)
+			indices[strings.ToLower(name)] = idx
+			values = append(values, EffectiveConfigValue{Name: name})
+		}
+		log.WithFields(log.Fields{
+			"Name":   name,
+			"Source": source,
+		}).Debug("Found local configuration value")
+		values[idx].Value = params[name]
+		values[idx].Source = source
+	}
+	return values
+}
+
+// configKeyName returns the config name from the v1 global or per-node config key.
+func configKeyName(key model.Key) string {
+	switch k := key.(type) {
+	case model.GlobalConfigKey:
+		return k.Name
+	case model.HostConfigKey:
+		return k.Name
+	case model.GlobalBGPConfigKey:
+		return k.Name
+	case model.NodeBGPConfigKey:
+		return k.Name
+	}
+	log.WithField("Key", key).Panic("Unexpected config key type")
+	return ""
+}
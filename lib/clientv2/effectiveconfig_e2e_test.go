@@ -0,0 +1,191 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+var _ = testutils.E2eDatastoreDescribe("Effective configuration tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	var c clientv2.Interface
+
+	BeforeEach(func() {
+		var err error
+		c, err = clientv2.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+	})
+
+	// Convert the effective values into a map of name to value and source, checking that
+	// each name only appears once.
+	toMap := func(values []clientv2.EffectiveConfigValue) map[string]clientv2.EffectiveConfigValue {
+		m := map[string]clientv2.EffectiveConfigValue{}
+		for _, v := range values {
+			Expect(m).NotTo(HaveKey(v.Name))
+			m[v.Name] = v
+		}
+		return m
+	}
+
+	It("should return unset values when there is no FelixConfiguration", func() {
+		values, err := c.EffectiveFelixConfig(ctx, "node1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).NotTo(BeEmpty())
+		for _, v := range values {
+			Expect(v.Value).To(Equal(""))
+			Expect(v.Source).To(Equal(clientv2.ConfigSourceUnset))
+		}
+		m := toMap(values)
+		Expect(m).To(HaveKey("RouteRefreshInterval"))
+		Expect(m).To(HaveKey("MetadataPort"))
+	})
+
+	It("should layer the per-node FelixConfiguration over the global FelixConfiguration", func() {
+		port1 := 1234
+		port2 := 5678
+		refresh := 30
+
+		By("Creating the global and per-node FelixConfigurations")
+		_, err := c.FelixConfigurations().Create(ctx, &apiv2.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{"config.projectcalico.org/ExtraOption": "foo"},
+			},
+			Spec: apiv2.FelixConfigurationSpec{
+				MetadataPort:             &port1,
+				RouteRefreshIntervalSecs: &refresh,
+				LogSeverityScreen:        "Info",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.FelixConfigurations().Create(ctx, &apiv2.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "node.node1"},
+			Spec: apiv2.FelixConfigurationSpec{
+				MetadataPort: &port2,
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Getting the effective configuration for node1")
+		values, err := c.EffectiveFelixConfig(ctx, "node1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		m := toMap(values)
+		Expect(m["MetadataPort"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "MetadataPort", Value: "5678", Source: clientv2.ConfigSourceNode,
+		}))
+		Expect(m["RouteRefreshInterval"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "RouteRefreshInterval", Value: "30", Source: clientv2.ConfigSourceGlobal,
+		}))
+		Expect(m["LogSeverityScreen"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "LogSeverityScreen", Value: "Info", Source: clientv2.ConfigSourceGlobal,
+		}))
+		Expect(m["ExtraOption"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "ExtraOption", Value: "foo", Source: clientv2.ConfigSourceGlobal,
+		}))
+		Expect(m["HealthPort"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "HealthPort", Source: clientv2.ConfigSourceUnset,
+		}))
+
+		By("Getting the effective configuration for node2 which has no per-node configuration")
+		values, err = c.EffectiveFelixConfig(ctx, "node2", nil)
+		Expect(err).NotTo(HaveOccurred())
+		m = toMap(values)
+		Expect(m["MetadataPort"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "MetadataPort", Value: "1234", Source: clientv2.ConfigSourceGlobal,
+		}))
+	})
+
+	It("should layer the local Felix configuration over the FelixConfigurations", func() {
+		port := 1234
+		_, err := c.FelixConfigurations().Create(ctx, &apiv2.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: apiv2.FelixConfigurationSpec{
+				MetadataPort:      &port,
+				LogSeverityScreen: "Info",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		values, err := c.EffectiveFelixConfig(ctx, "node1", &clientv2.LocalConfig{
+			File: map[string]string{"metadataport": "5678", "HealthPort": "9099", "LocalOnly": "a"},
+			Env:  map[string]string{"HEALTHPORT": "9098"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		m := toMap(values)
+		Expect(m["MetadataPort"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "MetadataPort", Value: "5678", Source: clientv2.ConfigSourceFile,
+		}))
+		Expect(m["HealthPort"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "HealthPort", Value: "9098", Source: clientv2.ConfigSourceEnv,
+		}))
+		Expect(m["LogSeverityScreen"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "LogSeverityScreen", Value: "Info", Source: clientv2.ConfigSourceGlobal,
+		}))
+		Expect(values[len(values)-1]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "LocalOnly", Value: "a", Source: clientv2.ConfigSourceFile,
+		}))
+	})
+
+	It("should layer the per-node BGPConfiguration over the global BGPConfiguration", func() {
+		ptrTrue := true
+
+		By("Creating the global and per-node BGPConfigurations")
+		_, err := c.BGPConfigurations().Create(ctx, &apiv2.BGPConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: apiv2.BGPConfigurationSpec{
+				LogSeverityScreen:     "Info",
+				NodeToNodeMeshEnabled: &ptrTrue,
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.BGPConfigurations().Create(ctx, &apiv2.BGPConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "node.node1"},
+			Spec: apiv2.BGPConfigurationSpec{
+				LogSeverityScreen: "Debug",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Getting the effective configuration for node1")
+		values, err := c.EffectiveBGPConfig(ctx, "node1")
+		Expect(err).NotTo(HaveOccurred())
+		m := toMap(values)
+		Expect(m["loglevel"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "loglevel", Value: "debug", Source: clientv2.ConfigSourceNode,
+		}))
+		Expect(m["node_mesh"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "node_mesh", Value: "{\"enabled\":true}", Source: clientv2.ConfigSourceGlobal,
+		}))
+		Expect(m["as_num"]).To(Equal(clientv2.EffectiveConfigValue{
+			Name: "as_num", Source: clientv2.ConfigSourceUnset,
+		}))
+	})
+})
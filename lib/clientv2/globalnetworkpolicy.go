@@ -41,6 +41,9 @@ type globalnetworkpolicies struct {
 // Create takes the representation of a GlobalNetworkPolicy and creates it.  Returns the stored
 // representation of the GlobalNetworkPolicy, and an error, if there is any.
 func (r globalnetworkpolicies) Create(ctx context.Context, res *apiv2.GlobalNetworkPolicy, opts options.SetOptions) (*apiv2.GlobalNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
 	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
 
 	// Properly prefix the name
@@ -60,6 +63,9 @@ func (r globalnetworkpolicies) Create(ctx context.Context, res *apiv2.GlobalNetw
 // Update takes the representation of a GlobalNetworkPolicy and updates it. Returns the stored
 // representation of the GlobalNetworkPolicy, and an error, if there is any.
 func (r globalnetworkpolicies) Update(ctx context.Context, res *apiv2.GlobalNetworkPolicy, opts options.SetOptions) (*apiv2.GlobalNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
 	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
 
 	// Properly prefix the name
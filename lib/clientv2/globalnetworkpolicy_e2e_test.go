@@ -70,6 +70,10 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkPolicy tests", testutils.Da
 	ingressTypesSpec1.Types = ingress
 	egressTypesSpec2 := spec2
 	egressTypesSpec2.Types = egress
+	// Spec with an ingress rule containing HTTP match criteria.
+	httpSpec1 := spec1
+	httpSpec1.IngressRules = []apiv2.Rule{testutils.InRuleHTTP}
+	httpSpec1.EgressRules = nil
 
 	DescribeTable("GlobalNetworkPolicy e2e CRUD tests",
 		func(name1, name2 string, spec1, spec2 apiv2.GlobalNetworkPolicySpec, types1, types2 []apiv2.PolicyType) {
@@ -293,6 +297,8 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkPolicy tests", testutils.Da
 		Entry("Ingress-only and egress-only policies", name1, name2, ingressSpec1, egressSpec2, ingress, egress),
 		// Check non-defaulting for policies with explicit Types value.
 		Entry("Policies with explicit ingress and egress Types", name1, name2, ingressTypesSpec1, egressTypesSpec2, ingress, egress),
+		// Check that HTTP match criteria are stored and returned.
+		Entry("Policies with HTTP match rules", name1, name2, httpSpec1, spec2, ingress, ingressEgress),
 	)
 
 	Describe("GlobalNetworkPolicy watch functionality", func() {
@@ -14,7 +14,11 @@
 
 package clientv2
 
-import "github.com/projectcalico/libcalico-go/lib/ipam"
+import (
+	"context"
+
+	"github.com/projectcalico/libcalico-go/lib/ipam"
+)
 
 type Interface interface {
 	// Nodes returns an interface for managing node resources.
@@ -23,6 +27,14 @@ type Interface interface {
 	GlobalNetworkPolicies() GlobalNetworkPolicyInterface
 	// NetworkPolicies returns an interface for managing namespaced network policy resources.
 	NetworkPolicies() NetworkPolicyInterface
+	// StagedGlobalNetworkPolicies returns an interface for managing staged global network policy resources.
+	StagedGlobalNetworkPolicies() StagedGlobalNetworkPolicyInterface
+	// StagedNetworkPolicies returns an interface for managing staged namespaced network policy resources.
+	StagedNetworkPolicies() StagedNetworkPolicyInterface
+	// Tiers returns an interface for managing policy tier resources.
+	Tiers() TierInterface
+	// NetworkSets returns an interface for managing namespaced network set resources.
+	NetworkSets() NetworkSetInterface
 	// IPPools returns an interface for managing IP pool resources.
 	IPPools() IPPoolInterface
 	// Profiles returns an interface for managing profile resources.
@@ -33,14 +45,32 @@ type Interface interface {
 	WorkloadEndpoints() WorkloadEndpointInterface
 	// BGPPeers returns an interface for managing BGP peer resources.
 	BGPPeers() BGPPeerInterface
+	// BGPFilters returns an interface for managing BGP filter resources.
+	BGPFilters() BGPFilterInterface
+	// NodeStatuses returns an interface for managing node status resources.
+	NodeStatuses() NodeStatusInterface
+	// IPReservations returns an interface for managing IP reservation resources.
+	IPReservations() IPReservationInterface
 	// IPAM returns an interface for managing IP address assignment and releasing.
 	IPAM() ipam.Interface
+	// IPAMGarbageCollector returns a new garbage collector that releases IP addresses assigned
+	// for workload endpoints and nodes that no longer exist.  The same collector should be
+	// used for each collection, since it tracks how long each address has been leaked.
+	IPAMGarbageCollector(opts ipam.GarbageCollectorOptions) *ipam.GarbageCollector
 	// BGPConfigurations returns an interface for managing the BGP configuration resources.
 	BGPConfigurations() BGPConfigurationInterface
 	// FelixConfigurations returns an interface for managing the Felix configuration resources.
 	FelixConfigurations() FelixConfigurationInterface
 	// ClusterInformation returns an interface for managing the cluster information resource.
 	ClusterInformation() ClusterInformationInterface
+	// EffectiveFelixConfig returns the merged global and per-node Felix configuration
+	// that applies to the specified node, with the source of each value.  The optional
+	// local configuration contains Felix's config file and environment settings, which
+	// override the datastore values.
+	EffectiveFelixConfig(ctx context.Context, nodeName string, local *LocalConfig) ([]EffectiveConfigValue, error)
+	// EffectiveBGPConfig returns the merged global and per-node BGP configuration
+	// that applies to the specified node, with the source of each value.
+	EffectiveBGPConfig(ctx context.Context, nodeName string) ([]EffectiveConfigValue, error)
 	// EnsureInitialized is used to ensure the backend datastore is correctly
 	// initialized for use by Calico.  This method may be called multiple times, and
 	// will have no effect if the datastore is already correctly initialized.
@@ -24,6 +24,7 @@ import (
 
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/ipam"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/watch"
@@ -100,9 +101,13 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 	// Deleting a pool requires a little care because of existing endpoints
 	// using IP addresses allocated in the pool.  We do the deletion in
 	// the following steps:
+	// -  check that no IPs are allocated from the pool, unless forced
 	// -  disable the pool so no more IPs are assigned from it
+	// -  check again that no IPs are allocated from the pool, unless forced
 	// -  remove all affinities associated with the pool
 	// -  delete the pool
+	//
+	// If IPs are allocated, the delete is refused and the pool is left as it was.
 
 	// Get the pool so that we can find the CIDR associated with it.
 	pool, err := r.Get(ctx, name, options.GetOptions{})
@@ -115,7 +120,21 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 		"Name": name,
 	})
 
+	// We've already validated the CIDR so we know it will parse.
+	_, cidrNet, cidrErr := cnet.ParseCIDR(pool.Spec.CIDR)
+
+	// Refuse to delete the pool while IPs remain allocated from it, unless forced.
+	if !opts.Force && cidrErr == nil {
+		if n, err := r.countAllocations(ctx, *cidrNet); err != nil {
+			return nil, err
+		} else if n > 0 {
+			logCxt.WithField("allocations", n).Info("Not deleting pool with allocations")
+			return nil, poolInUseError(name, n, "the pool has not been modified")
+		}
+	}
+
 	// If the pool is active, set the disabled flag to ensure we stop allocating from this pool.
+	disabled := false
 	if !pool.Spec.Disabled {
 		logCxt.Info("Disabling pool to release affinities")
 		pool.Spec.Disabled = true
@@ -127,9 +146,10 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 		if opts.ResourceVersion != "" {
 			pool.ResourceVersion = opts.ResourceVersion
 		}
-		if _, err := r.Update(ctx, pool, options.SetOptions{}); err != nil {
+		if pool, err = r.Update(ctx, pool, options.SetOptions{}); err != nil {
 			return nil, err
 		}
+		disabled = true
 
 		// Reset the resource version before the actual delete since the version of that resource
 		// will now have been updated.
@@ -138,13 +158,34 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 
 	// Release affinities associated with this pool.  We do this even if the pool was disabled
 	// (since it may have been enabled at one time, and if there are no affine blocks created
-	// then this will be a no-op).  We've already validated the CIDR so we know it will parse.
-	if _, cidrNet, err := cnet.ParseCIDR(pool.Spec.CIDR); err == nil {
+	// then this will be a no-op).
+	if cidrErr == nil {
 		logCxt.Info("Releasing pool affinities")
 
 		// Pause for a short period before releasing the affinities - this gives any in-progress
 		// allocations an opportunity to finish.
 		time.Sleep(500 * time.Millisecond)
+
+		// Check again for allocations made before the pool was disabled.  If there are any,
+		// re-enable the pool if we disabled it.
+		if !opts.Force {
+			if n, err := r.countAllocations(ctx, *cidrNet); err != nil {
+				return nil, err
+			} else if n > 0 {
+				logCxt.WithField("allocations", n).Info("Not deleting pool with allocations")
+				if !disabled {
+					return nil, poolInUseError(name, n, "the pool has not been modified")
+				}
+				logCxt.Info("Re-enabling pool")
+				pool.Spec.Disabled = false
+				if _, err := r.Update(ctx, pool, options.SetOptions{}); err != nil {
+					logCxt.WithError(err).Warning("Failed to re-enable pool")
+					return nil, poolInUseError(name, n, "the pool has been left disabled")
+				}
+				return nil, poolInUseError(name, n, "the pool has not been modified")
+			}
+		}
+
 		err = r.client.IPAM().ReleasePoolAffinities(ctx, *cidrNet)
 
 		// Depending on the datastore, IPAM may not be supported.  If we get a not supported
@@ -163,6 +204,24 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 	return nil, err
 }
 
+// countAllocations returns the number of IP addresses allocated from the pool.
+func (r ipPools) countAllocations(ctx context.Context, cidr cnet.IPNet) (int, error) {
+	allocs, err := r.client.IPAM().ListAllocations(ctx, ipam.AllocationFilter{Pools: []cnet.IPNet{cidr}})
+	if _, ok := err.(cerrors.ErrorOperationNotSupported); !ok && err != nil {
+		return 0, err
+	}
+	return len(allocs), nil
+}
+
+// poolInUseError returns the error for a refused delete of a pool with allocations.  The
+// supplied state describes what the delete has left the pool in.
+func poolInUseError(name string, allocations int, state string) error {
+	return cerrors.ErrorResourceInUse{
+		Identifier: name,
+		Reason:     fmt.Sprintf("%d IP addresses are still allocated from the pool - %s", allocations, state),
+	}
+}
+
 // Get takes name of the IPPool, and returns the corresponding IPPool object,
 // and an error if there is any.
 func (r ipPools) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.IPPool, error) {
@@ -273,22 +332,82 @@ func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv2.IPP
 		})
 	}
 
+	// Make sure VXLANMode is defaulted to "Never".
+	if len(new.Spec.VXLANMode) == 0 {
+		new.Spec.VXLANMode = apiv2.VXLANModeNever
+	}
+
+	// VXLAN cannot be enabled for IPv6.
+	if cidr.Version() == 6 && new.Spec.VXLANMode != apiv2.VXLANModeNever {
+		errFields = append(errFields, cerrors.ErroredField{
+			Name:   "IPPool.Spec.VXLANMode",
+			Reason: "VXLAN is not supported on an IPv6 IP pool",
+			Value:  new.Spec.VXLANMode,
+		})
+	}
+
+	// IPIP and VXLAN cannot both be enabled on the same pool.
+	if new.Spec.IPIPMode != apiv2.IPIPModeNever && new.Spec.VXLANMode != apiv2.VXLANModeNever {
+		errFields = append(errFields, cerrors.ErroredField{
+			Name:   "IPPool.Spec.VXLANMode",
+			Reason: "IPIP and VXLAN encapsulation cannot both be enabled on an IP pool",
+			Value:  new.Spec.VXLANMode,
+		})
+	}
+
+	// Default the block size if not specified, and check that it is within the
+	// range supported by Calico IPAM for the pool IP version.
+	if new.Spec.BlockSize == 0 {
+		new.Spec.BlockSize = defaultBlockSize(cidr.Version())
+	}
+	if cidr.Version() == 4 && (new.Spec.BlockSize < 20 || new.Spec.BlockSize > 32) {
+		errFields = append(errFields, cerrors.ErroredField{
+			Name:   "IPPool.Spec.BlockSize",
+			Reason: "IPv4 block size must be between 20 and 32",
+			Value:  new.Spec.BlockSize,
+		})
+	} else if cidr.Version() == 6 && (new.Spec.BlockSize < 116 || new.Spec.BlockSize > 128) {
+		errFields = append(errFields, cerrors.ErroredField{
+			Name:   "IPPool.Spec.BlockSize",
+			Reason: "IPv6 block size must be between 116 and 128",
+			Value:  new.Spec.BlockSize,
+		})
+	}
+
+	// The block size cannot be changed once the pool is in use, since existing
+	// blocks would no longer align with the pool.  Pools stored before the block
+	// size was configurable have no block size, and use the default.
+	if old != nil {
+		oldBlockSize := old.Spec.BlockSize
+		if oldBlockSize == 0 {
+			oldBlockSize = defaultBlockSize(cidr.Version())
+		}
+		if oldBlockSize != new.Spec.BlockSize {
+			errFields = append(errFields, cerrors.ErroredField{
+				Name:   "IPPool.Spec.BlockSize",
+				Reason: "IPPool BlockSize cannot be modified",
+				Value:  new.Spec.BlockSize,
+			})
+		}
+	}
+
 	// The Calico IPAM places restrictions on the minimum IP pool size.  If
-	// the ippool is enabled, check that the pool is at least the minimum size.
+	// the ippool is enabled, check that the pool is at least the size of a
+	// single block.
 	if !new.Spec.Disabled {
 		ones, bits := cidr.Mask.Size()
 		log.Debugf("Pool CIDR: %s, num bits: %d", cidr.String(), bits-ones)
-		if bits-ones < 6 {
+		if ones > new.Spec.BlockSize {
 			if cidr.Version() == 4 {
 				errFields = append(errFields, cerrors.ErroredField{
 					Name:   "IPPool.Spec.CIDR",
-					Reason: "IPv4 pool size is too small (min /26) for use with Calico IPAM",
+					Reason: fmt.Sprintf("IPv4 pool size is too small (min /%d) for use with Calico IPAM", new.Spec.BlockSize),
 					Value:  new.Spec.CIDR,
 				})
 			} else {
 				errFields = append(errFields, cerrors.ErroredField{
 					Name:   "IPPool.Spec.CIDR",
-					Reason: "IPv6 pool size is too small (min /122) for use with Calico IPAM",
+					Reason: fmt.Sprintf("IPv6 pool size is too small (min /%d) for use with Calico IPAM", new.Spec.BlockSize),
 					Value:  new.Spec.CIDR,
 				})
 			}
@@ -402,3 +521,12 @@ func (c ipPools) maybeEnableIPIP(ctx context.Context, pool *apiv2.IPPool) error
 	log.WithError(err).Info("Too many conflict failures attempting to update FelixConfiguration to enable IPIP")
 	return err
 }
+
+// defaultBlockSize returns the block size used by Calico IPAM for a pool of the given IP
+// version when the pool does not specify one.
+func defaultBlockSize(version int) int {
+	if version == 4 {
+		return 26
+	}
+	return 122
+}
@@ -27,8 +27,11 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
 	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/clientv2"
 	"github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/ipam"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/testutils"
 	"github.com/projectcalico/libcalico-go/lib/watch"
@@ -40,22 +43,30 @@ var _ = testutils.E2eDatastoreDescribe("IPPool tests", testutils.DatastoreAll, f
 	name1 := "ippool-1"
 	name2 := "ippool-2"
 	spec1 := apiv2.IPPoolSpec{
-		CIDR:     "1.2.3.0/24",
-		IPIPMode: apiv2.IPIPModeAlways,
+		CIDR:      "1.2.3.0/24",
+		IPIPMode:  apiv2.IPIPModeAlways,
+		VXLANMode: apiv2.VXLANModeNever,
+		BlockSize: 26,
 	}
 	spec1_2 := apiv2.IPPoolSpec{
 		CIDR:        "1.2.3.0/24",
 		NATOutgoing: true,
 		IPIPMode:    apiv2.IPIPModeNever,
+		VXLANMode:   apiv2.VXLANModeNever,
+		BlockSize:   26,
 	}
 	spec2 := apiv2.IPPoolSpec{
 		CIDR:        "2001::/120",
 		NATOutgoing: true,
 		IPIPMode:    apiv2.IPIPModeNever,
+		VXLANMode:   apiv2.VXLANModeNever,
+		BlockSize:   122,
 	}
 	spec2_1 := apiv2.IPPoolSpec{
-		CIDR:     "2001::/120",
-		IPIPMode: apiv2.IPIPModeNever,
+		CIDR:      "2001::/120",
+		IPIPMode:  apiv2.IPIPModeNever,
+		VXLANMode: apiv2.VXLANModeNever,
+		BlockSize: 122,
 	}
 
 	DescribeTable("IPPool e2e CRUD tests",
@@ -680,5 +691,255 @@ var _ = testutils.E2eDatastoreDescribe("IPPool tests", testutils.DatastoreAll, f
 			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
 			Expect(err.Error()).To(ContainSubstring("IPPool(ippool4) CIDR overlaps with IPPool(ippool1) CIDR 1.2.3.0/24"))
 		})
+
+		It("should default and validate the VXLAN mode", func() {
+			By("Creating a pool without a VXLAN mode and checking the default is set")
+			pool, err := c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool1"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR: "1.2.3.0/24",
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.VXLANMode).To(Equal(apiv2.VXLANModeNever))
+
+			By("Attempting to enable both IPIP and VXLAN")
+			pool.Spec.IPIPMode = apiv2.IPIPModeAlways
+			pool.Spec.VXLANMode = apiv2.VXLANModeAlways
+			_, err = c.IPPools().Update(ctx, pool, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring("IPIP and VXLAN encapsulation cannot both be enabled on an IP pool"))
+
+			By("Enabling VXLAN only")
+			pool.Spec.IPIPMode = apiv2.IPIPModeNever
+			pool, err = c.IPPools().Update(ctx, pool, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.VXLANMode).To(Equal(apiv2.VXLANMode(apiv2.VXLANModeAlways)))
+
+			By("Attempting to create a VXLAN IPv6 pool")
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool2"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR:      "aa:bb::/120",
+					VXLANMode: apiv2.VXLANModeCrossSubnet,
+				},
+			}, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("VXLAN is not supported on an IPv6 IP pool"))
+		})
+
+		It("should default and validate the block size", func() {
+			By("Creating a pool without a block size and checking the default is set")
+			pool, err := c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool1"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR: "1.2.3.0/24",
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.BlockSize).To(Equal(26))
+
+			By("Attempting to change the block size")
+			pool.Spec.BlockSize = 28
+			_, err = c.IPPools().Update(ctx, pool, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring("IPPool BlockSize cannot be modified"))
+
+			By("Storing a pool without a block size, as an older version of Calico would")
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			legacy := apiv2.NewIPPool()
+			legacy.Name = "legacy-pool"
+			legacy.Spec = apiv2.IPPoolSpec{CIDR: "1.2.6.0/24", IPIPMode: apiv2.IPIPModeNever, VXLANMode: apiv2.VXLANModeNever}
+			_, err = be.Create(ctx, &model.KVPair{
+				Key:   model.ResourceKey{Kind: apiv2.KindIPPool, Name: legacy.Name},
+				Value: legacy,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Attempting to change the block size of the pool from the default")
+			pool, err = c.IPPools().Get(ctx, legacy.Name, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			pool.Spec.BlockSize = 28
+			_, err = c.IPPools().Update(ctx, pool, options.SetOptions{})
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring("IPPool BlockSize cannot be modified"))
+
+			By("Updating the pool with the default block size")
+			pool.Spec.BlockSize = 26
+			pool, err = c.IPPools().Update(ctx, pool, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.BlockSize).To(Equal(26))
+
+			By("Creating a pool with a non-default block size")
+			pool, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool2"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR:      "1.2.4.0/28",
+					BlockSize: 30,
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.BlockSize).To(Equal(30))
+
+			By("Attempting to create a pool with a block size larger than the pool")
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool3"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR:      "1.2.5.0/24",
+					BlockSize: 22,
+				},
+			}, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring("IPv4 pool size is too small (min /22) for use with Calico IPAM"))
+
+			By("Attempting to create pools with out of range block sizes")
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool4"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR:      "10.0.0.0/8",
+					BlockSize: 19,
+				},
+			}, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("IPv4 block size must be between 20 and 32"))
+
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool5"},
+				Spec: apiv2.IPPoolSpec{
+					CIDR:      "2001::/64",
+					BlockSize: 112,
+				},
+			}, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("IPv6 block size must be between 116 and 128"))
+		})
+	})
+})
+
+var _ = testutils.E2eDatastoreDescribe("IPPool drain tests", testutils.DatastoreEtcdV3, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	cidr := cnet.MustParseNetwork("10.0.0.0/24")
+
+	Describe("IPPool deletion with allocations", func() {
+		It("should refuse to delete a pool with allocations until it is drained or forced", func() {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a pool and assigning addresses on two hosts")
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-1"},
+				Spec:       apiv2.IPPoolSpec{CIDR: cidr.String()},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			handle := "handle-1"
+			v4A, _, err := c.IPAM().AutoAssign(ctx, ipam.AutoAssignArgs{Num4: 2, Hostname: "host-A", HandleID: &handle})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4A).To(HaveLen(2))
+			v4B, _, err := c.IPAM().AutoAssign(ctx, ipam.AutoAssignArgs{Num4: 1, Hostname: "host-B"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4B).To(HaveLen(1))
+
+			By("Refusing to drain the pool while it is enabled")
+			_, err = c.IPAM().DrainPool(ctx, cidr)
+			Expect(err).To(HaveOccurred())
+
+			By("Refusing to delete the pool, which leaves it unmodified")
+			pool, err := c.IPPools().Get(ctx, "pool-1", options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.IPPools().Delete(ctx, "pool-1", options.DeleteOptions{})
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorResourceInUse{}))
+			Expect(err.Error()).To(ContainSubstring("the pool has not been modified"))
+			refused, err := c.IPPools().Get(ctx, "pool-1", options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refused.Spec.Disabled).To(BeFalse())
+			Expect(refused.ResourceVersion).To(Equal(pool.ResourceVersion))
+
+			By("Disabling the pool so that it can be drained")
+			refused.Spec.Disabled = true
+			_, err = c.IPPools().Update(ctx, refused, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Draining the pool once host-B's addresses are released")
+			_, err = c.IPAM().ReleaseIPs(ctx, v4B)
+			Expect(err).NotTo(HaveOccurred())
+			report, err := c.IPAM().DrainPool(ctx, cidr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.ReleasedBlocks).To(HaveLen(1))
+			Expect(report.Allocations).To(HaveLen(2))
+			Expect(report.AllocationsByHost).To(Equal(map[string]int{"host-A": 2}))
+			Expect(report.AllocationsByHandle).To(Equal(map[string]int{handle: 2}))
+
+			By("Deleting the pool once all addresses are released")
+			err = c.IPAM().ReleaseByHandle(ctx, handle)
+			Expect(err).NotTo(HaveOccurred())
+			report, err = c.IPAM().DrainPool(ctx, cidr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.ReleasedBlocks).To(HaveLen(1))
+			Expect(report.Allocations).To(BeEmpty())
+			_, err = c.IPPools().Delete(ctx, "pool-1", options.DeleteOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should refuse to drain a CIDR that overlaps an enabled pool", func() {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-1"},
+				Spec:       apiv2.IPPoolSpec{CIDR: cidr.String()},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-2"},
+				Spec:       apiv2.IPPoolSpec{CIDR: "10.1.0.0/24", Disabled: true},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Refusing to drain a supernet of the enabled pool")
+			_, err = c.IPAM().DrainPool(ctx, cnet.MustParseNetwork("10.0.0.0/8"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("10.0.0.0/24"))
+
+			By("Refusing to drain a subnet of the enabled pool")
+			_, err = c.IPAM().DrainPool(ctx, cnet.MustParseNetwork("10.0.0.0/26"))
+			Expect(err).To(HaveOccurred())
+
+			By("Draining a CIDR that only overlaps the disabled pool")
+			_, err = c.IPAM().DrainPool(ctx, cnet.MustParseNetwork("10.1.0.0/16"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should delete a pool with allocations when forced", func() {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			_, err = c.IPPools().Create(ctx, &apiv2.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-1"},
+				Spec:       apiv2.IPPoolSpec{CIDR: cidr.String()},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = c.IPAM().AutoAssign(ctx, ipam.AutoAssignArgs{Num4: 1, Hostname: "host-A"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = c.IPPools().Delete(ctx, "pool-1", options.DeleteOptions{Force: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
 	})
 })
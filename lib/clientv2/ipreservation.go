@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+const (
+	// The length of time for which the IP reservations are cached by the IPAM
+	// client.  Changes made through this client invalidate the cache immediately.
+	reservationCacheTTL = 30 * time.Second
+)
+
+// IPReservationInterface has methods to work with IPReservation resources.
+type IPReservationInterface interface {
+	Create(ctx context.Context, res *apiv2.IPReservation, opts options.SetOptions) (*apiv2.IPReservation, error)
+	Update(ctx context.Context, res *apiv2.IPReservation, opts options.SetOptions) (*apiv2.IPReservation, error)
+	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.IPReservation, error)
+	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.IPReservation, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.IPReservationList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+}
+
+// ipReservations implements IPReservationInterface
+type ipReservations struct {
+	client client
+}
+
+// Create takes the representation of a IPReservation and creates it.  Returns the stored
+// representation of the IPReservation, and an error, if there is any.
+func (r ipReservations) Create(ctx context.Context, res *apiv2.IPReservation, opts options.SetOptions) (*apiv2.IPReservation, error) {
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindIPReservation, res)
+	r.client.reservations.invalidate()
+	if out != nil {
+		return out.(*apiv2.IPReservation), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a IPReservation and updates it. Returns the stored
+// representation of the IPReservation, and an error, if there is any.
+func (r ipReservations) Update(ctx context.Context, res *apiv2.IPReservation, opts options.SetOptions) (*apiv2.IPReservation, error) {
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindIPReservation, res)
+	r.client.reservations.invalidate()
+	if out != nil {
+		return out.(*apiv2.IPReservation), err
+	}
+	return nil, err
+}
+
+// Delete takes name of the IPReservation and deletes it. Returns an error if one occurs.
+func (r ipReservations) Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.IPReservation, error) {
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindIPReservation, noNamespace, name)
+	r.client.reservations.invalidate()
+	if out != nil {
+		return out.(*apiv2.IPReservation), err
+	}
+	return nil, err
+}
+
+// Get takes name of the IPReservation, and returns the corresponding IPReservation object,
+// and an error if there is any.
+func (r ipReservations) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.IPReservation, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindIPReservation, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.IPReservation), err
+	}
+	return nil, err
+}
+
+// List returns the list of IPReservation objects that match the supplied options.
+func (r ipReservations) List(ctx context.Context, opts options.ListOptions) (*apiv2.IPReservationList, error) {
+	res := &apiv2.IPReservationList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindIPReservation, apiv2.KindIPReservationList, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the IPReservations that match the
+// supplied options.
+func (r ipReservations) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindIPReservation)
+}
+
+// reservationCache caches the reserved CIDRs from all of the IPReservation resources
+// so that IPAM does not need to read the reservations from the datastore for every
+// allocation.  It implements ipam.ReservationAccessorInterface.
+type reservationCache struct {
+	lock      sync.Mutex
+	resources resourceInterface
+	cidrs     []net.IPNet
+	expires   time.Time
+}
+
+func newReservationCache(resources resourceInterface) *reservationCache {
+	return &reservationCache{resources: resources}
+}
+
+// GetReservedCIDRs returns the reserved CIDRs, reading them from the datastore if the
+// cached set has been invalidated or has expired.
+func (rc *reservationCache) GetReservedCIDRs(ctx context.Context) ([]net.IPNet, error) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if rc.cidrs != nil && time.Now().Before(rc.expires) {
+		return rc.cidrs, nil
+	}
+
+	list := &apiv2.IPReservationList{}
+	err := rc.resources.List(ctx, options.ListOptions{}, apiv2.KindIPReservation, apiv2.KindIPReservationList, list)
+	if err != nil {
+		return nil, err
+	}
+	cidrs := []net.IPNet{}
+	for _, res := range list.Items {
+		for _, c := range res.Spec.ReservedCIDRs {
+			_, cidr, err := net.ParseCIDROrIP(c)
+			if err != nil {
+				log.Warnf("Failed to parse reserved CIDR %s in IPReservation %s. Ignoring", c, res.Name)
+				continue
+			}
+			cidrs = append(cidrs, *cidr)
+		}
+	}
+	log.Debugf("Loaded reserved CIDRs: %v", cidrs)
+	rc.cidrs = cidrs
+	rc.expires = time.Now().Add(reservationCacheTTL)
+	return rc.cidrs, nil
+}
+
+// invalidate forces the reserved CIDRs to be reloaded on the next access.
+func (rc *reservationCache) invalidate() {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	rc.cidrs = nil
+}
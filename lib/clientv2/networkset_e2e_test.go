@@ -0,0 +1,209 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+var _ = testutils.E2eDatastoreDescribe("NetworkSet tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	namespace1 := "namespace-1"
+	namespace2 := "namespace-2"
+	name1 := "networkset-1"
+	name2 := "networkset-2"
+	spec1 := apiv2.NetworkSetSpec{
+		Nets: []string{"10.0.0.1/32", "192.168.0.0/16"},
+	}
+	spec2 := apiv2.NetworkSetSpec{
+		Nets: []string{"aa:bb::/64"},
+	}
+
+	DescribeTable("NetworkSet e2e CRUD tests",
+		func(namespace1, namespace2, name1, name2 string, spec1, spec2 apiv2.NetworkSetSpec) {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Updating the NetworkSet before it is created")
+			_, outError := c.NetworkSets().Update(ctx, &apiv2.NetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1, Name: name1, ResourceVersion: "1234", CreationTimestamp: metav1.Now(), UID: "test-fail-networkset"},
+				Spec:       spec1,
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource does not exist: NetworkSet(" + namespace1 + "/" + name1 + ")"))
+
+			By("Creating a new NetworkSet with namespace1/name1/spec1")
+			res1, outError := c.NetworkSets().Create(ctx, &apiv2.NetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1, Name: name1},
+				Spec:       spec1,
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res1, apiv2.KindNetworkSet, namespace1, name1, spec1)
+
+			// Track the version of the original data for name1.
+			rv1_1 := res1.ResourceVersion
+
+			By("Attempting to create the same NetworkSet with name1 but with spec2")
+			_, outError = c.NetworkSets().Create(ctx, &apiv2.NetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1, Name: name1},
+				Spec:       spec2,
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource already exists: NetworkSet(" + namespace1 + "/" + name1 + ")"))
+
+			By("Getting NetworkSet (name1) and comparing the output against spec1")
+			res, outError := c.NetworkSets().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res, apiv2.KindNetworkSet, namespace1, name1, spec1)
+			Expect(res.ResourceVersion).To(Equal(res1.ResourceVersion))
+
+			By("Getting NetworkSet (name2) before it is created")
+			_, outError = c.NetworkSets().Get(ctx, namespace2, name2, options.GetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource does not exist: NetworkSet(" + namespace2 + "/" + name2 + ")"))
+
+			By("Creating a new NetworkSet with namespace2/name2/spec2")
+			res2, outError := c.NetworkSets().Create(ctx, &apiv2.NetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2, Name: name2},
+				Spec:       spec2,
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res2, apiv2.KindNetworkSet, namespace2, name2, spec2)
+
+			By("Listing all the NetworkSets using an empty namespace (all-namespaces), expecting two results")
+			outList, outError := c.NetworkSets().List(ctx, options.ListOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(2))
+			testutils.ExpectResource(&outList.Items[0], apiv2.KindNetworkSet, namespace1, name1, spec1)
+			testutils.ExpectResource(&outList.Items[1], apiv2.KindNetworkSet, namespace2, name2, spec2)
+
+			By("Listing all the NetworkSets in namespace2, expecting a single result with name2/spec2")
+			outList, outError = c.NetworkSets().List(ctx, options.ListOptions{Namespace: namespace2})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(1))
+			testutils.ExpectResource(&outList.Items[0], apiv2.KindNetworkSet, namespace2, name2, spec2)
+
+			By("Updating NetworkSet name1 with spec2")
+			res1.Spec = spec2
+			res1, outError = c.NetworkSets().Update(ctx, res1, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res1, apiv2.KindNetworkSet, namespace1, name1, spec2)
+
+			// Track the version of the updated name1 data.
+			rv1_2 := res1.ResourceVersion
+
+			By("Updating NetworkSet name1 using the previous resource version")
+			res1.Spec = spec1
+			res1.ResourceVersion = rv1_1
+			_, outError = c.NetworkSets().Update(ctx, res1, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: NetworkSet(" + namespace1 + "/" + name1 + ")"))
+
+			By("Deleting NetworkSet (name1) with the new resource version")
+			dres, outError := c.NetworkSets().Delete(ctx, namespace1, name1, options.DeleteOptions{ResourceVersion: rv1_2})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(dres, apiv2.KindNetworkSet, namespace1, name1, spec2)
+
+			By("Deleting NetworkSet (name2)")
+			dres, outError = c.NetworkSets().Delete(ctx, namespace2, name2, options.DeleteOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(dres, apiv2.KindNetworkSet, namespace2, name2, spec2)
+
+			By("Attempting to delete NetworkSet (name2) again")
+			_, outError = c.NetworkSets().Delete(ctx, namespace2, name2, options.DeleteOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource does not exist: NetworkSet(" + namespace2 + "/" + name2 + ")"))
+
+			By("Listing all NetworkSets and expecting no items")
+			outList, outError = c.NetworkSets().List(ctx, options.ListOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(0))
+		},
+
+		// Test 1: Pass two fully populated NetworkSetSpecs and expect the series of operations to succeed.
+		Entry("Two fully populated NetworkSetSpecs",
+			namespace1, namespace2,
+			name1, name2,
+			spec1, spec2,
+		),
+	)
+
+	Describe("NetworkSet watch functionality", func() {
+		It("should handle watch events for different resource versions and event types", func() {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Listing NetworkSets with no resource version and checking for no results")
+			outList, outError := c.NetworkSets().List(ctx, options.ListOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(0))
+			rev0 := outList.ResourceVersion
+
+			By("Configuring a NetworkSet namespace1/name1/spec1 and storing the response")
+			outRes1, err := c.NetworkSets().Create(
+				ctx,
+				&apiv2.NetworkSet{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace1, Name: name1},
+					Spec:       spec1,
+				},
+				options.SetOptions{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Deleting res1")
+			_, err = c.NetworkSets().Delete(ctx, namespace1, name1, options.DeleteOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Starting a watcher from rev0 - this should get all events")
+			w, err := c.NetworkSets().Watch(ctx, options.ListOptions{ResourceVersion: rev0})
+			Expect(err).NotTo(HaveOccurred())
+			testWatcher := testutils.NewTestResourceWatch(config.Spec.DatastoreType, w)
+			defer testWatcher.Stop()
+			testWatcher.ExpectEvents(apiv2.KindNetworkSet, []watch.Event{
+				{
+					Type:   watch.Added,
+					Object: outRes1,
+				},
+				{
+					Type:     watch.Deleted,
+					Previous: outRes1,
+				},
+			})
+			testWatcher.Stop()
+		})
+	})
+})
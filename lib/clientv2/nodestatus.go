@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// NodeStatusInterface has methods to work with NodeStatus resources.
+//
+// Agents report the status of their node by creating or updating the NodeStatus with a
+// TTL in the SetOptions.  The client sets the ExpiresAt field from the TTL, and Get and List
+// do not return a NodeStatus that has expired.  On etcdv3 the TTL is also implemented as a
+// lease, so the status is removed if the agent stops refreshing it.  Other datastores keep
+// the expired status until it is next written or deleted, and it is still returned by Watch.
+type NodeStatusInterface interface {
+	Create(ctx context.Context, res *apiv2.NodeStatus, opts options.SetOptions) (*apiv2.NodeStatus, error)
+	Update(ctx context.Context, res *apiv2.NodeStatus, opts options.SetOptions) (*apiv2.NodeStatus, error)
+	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.NodeStatus, error)
+	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.NodeStatus, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.NodeStatusList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+}
+
+// nodeStatuses implements NodeStatusInterface
+type nodeStatuses struct {
+	client client
+}
+
+// Create takes the representation of a NodeStatus and creates it.  Returns the stored
+// representation of the NodeStatus, and an error, if there is any.
+func (r nodeStatuses) Create(ctx context.Context, res *apiv2.NodeStatus, opts options.SetOptions) (*apiv2.NodeStatus, error) {
+	setNodeStatusExpiry(res, opts)
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindNodeStatus, res)
+	if out != nil {
+		return out.(*apiv2.NodeStatus), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a NodeStatus and updates it. Returns the stored
+// representation of the NodeStatus, and an error, if there is any.
+func (r nodeStatuses) Update(ctx context.Context, res *apiv2.NodeStatus, opts options.SetOptions) (*apiv2.NodeStatus, error) {
+	setNodeStatusExpiry(res, opts)
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindNodeStatus, res)
+	if out != nil {
+		return out.(*apiv2.NodeStatus), err
+	}
+	return nil, err
+}
+
+// Delete takes name of the NodeStatus and deletes it. Returns an error if one occurs.
+func (r nodeStatuses) Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.NodeStatus, error) {
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindNodeStatus, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.NodeStatus), err
+	}
+	return nil, err
+}
+
+// Get takes name of the NodeStatus, and returns the corresponding NodeStatus object,
+// and an error if there is any.  An expired NodeStatus is treated as not existing.
+func (r nodeStatuses) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.NodeStatus, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindNodeStatus, noNamespace, name)
+	if out != nil {
+		if isNodeStatusExpired(out.(*apiv2.NodeStatus), time.Now()) {
+			return nil, cerrors.ErrorResourceDoesNotExist{
+				Identifier: model.ResourceKey{Kind: apiv2.KindNodeStatus, Name: name},
+			}
+		}
+		return out.(*apiv2.NodeStatus), err
+	}
+	return nil, err
+}
+
+// List returns the list of NodeStatus objects that match the supplied options.  Expired
+// NodeStatus objects are not included.
+func (r nodeStatuses) List(ctx context.Context, opts options.ListOptions) (*apiv2.NodeStatusList, error) {
+	res := &apiv2.NodeStatusList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindNodeStatus, apiv2.KindNodeStatusList, res); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	items := res.Items[:0]
+	for _, ns := range res.Items {
+		if !isNodeStatusExpired(&ns, now) {
+			items = append(items, ns)
+		}
+	}
+	res.Items = items
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the NodeStatuses that match the
+// supplied options.
+func (r nodeStatuses) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindNodeStatus)
+}
+
+// setNodeStatusExpiry sets the ExpiresAt field of the NodeStatus from the TTL in the
+// SetOptions.  If no TTL is specified the status does not expire.
+func setNodeStatusExpiry(res *apiv2.NodeStatus, opts options.SetOptions) {
+	res.Spec.ExpiresAt = nil
+	if opts.TTL != 0 {
+		expiresAt := metav1.NewTime(time.Now().Add(opts.TTL))
+		res.Spec.ExpiresAt = &expiresAt
+	}
+}
+
+// isNodeStatusExpired returns true if the NodeStatus has an expiry time which has been
+// reached.
+func isNodeStatusExpired(res *apiv2.NodeStatus, now time.Time) bool {
+	return res.Spec.ExpiresAt != nil && !now.Before(res.Spec.ExpiresAt.Time)
+}
@@ -60,6 +60,15 @@ type resourceInterface interface {
 	Get(ctx context.Context, opts options.GetOptions, kind, ns, name string) (resource, error)
 	List(ctx context.Context, opts options.ListOptions, kind, listkind string, inout resourceList) error
 	Watch(ctx context.Context, opts options.ListOptions, kind string) (watch.Interface, error)
+	Txn(ctx context.Context, opts options.SetOptions, ops []resourceOp) ([]resource, error)
+}
+
+// resourceOp is a single create, update or delete of a resource, applied as part of a Txn.
+// A delete only uses the name, namespace and (optional) resource version of the resource.
+type resourceOp struct {
+	op   bapi.TxnOpType
+	kind string
+	res  resource
 }
 
 // resources implements resourceInterface.
@@ -69,30 +78,10 @@ type resources struct {
 
 // Create creates a resource in the backend datastore.
 func (c *resources) Create(ctx context.Context, opts options.SetOptions, kind string, in resource) (resource, error) {
-	// A ResourceVersion should never be specified on a Create.
-	if len(in.GetObjectMeta().GetResourceVersion()) != 0 {
-		logWithResource(in).Info("Rejecting Create request with non-empty resource version")
-		return nil, cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "Metadata.ResourceVersion",
-				Reason: "field must not be set for a Create request",
-				Value:  in.GetObjectMeta().GetResourceVersion(),
-			}},
-		}
-	}
-	if err := c.checkNamespace(in.GetObjectMeta().GetNamespace(), kind); err != nil {
+	if err := c.prepareCreate(kind, in); err != nil {
 		return nil, err
 	}
 
-	// Add in the UID and creation timestamp for the resource if needed.
-	creationTimestamp := in.GetObjectMeta().GetCreationTimestamp()
-	if creationTimestamp.IsZero() {
-		in.GetObjectMeta().SetCreationTimestamp(v1.Now())
-	}
-	if in.GetObjectMeta().GetUID() == "" {
-		in.GetObjectMeta().SetUID(uuid.NewUUID())
-	}
-
 	// Convert the resource to a KVPair and pass that to the backend datastore, converting
 	// the response (if we get one) back to a resource.
 	kvp, err := c.backend.Create(ctx, c.resourceToKVPair(opts, kind, in))
@@ -104,39 +93,9 @@ func (c *resources) Create(ctx context.Context, opts options.SetOptions, kind st
 
 // Update updates a resource in the backend datastore.
 func (c *resources) Update(ctx context.Context, opts options.SetOptions, kind string, in resource) (resource, error) {
-	// A ResourceVersion should always be specified on an Update.
-	if len(in.GetObjectMeta().GetResourceVersion()) == 0 {
-		logWithResource(in).Info("Rejecting Update request with empty resource version")
-		return nil, cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "Metadata.ResourceVersion",
-				Reason: "field must be set for an Update request",
-				Value:  in.GetObjectMeta().GetResourceVersion(),
-			}},
-		}
-	}
-	if err := c.checkNamespace(in.GetObjectMeta().GetNamespace(), kind); err != nil {
+	if err := c.prepareUpdate(kind, in); err != nil {
 		return nil, err
 	}
-	creationTimestamp := in.GetObjectMeta().GetCreationTimestamp()
-	if creationTimestamp.IsZero() {
-		return nil, cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "Metadata.CreationTimestamp",
-				Reason: "field must be set for an Update request",
-				Value:  in.GetObjectMeta().GetCreationTimestamp(),
-			}},
-		}
-	}
-	if in.GetObjectMeta().GetUID() == "" {
-		return nil, cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "Metadata.UID",
-				Reason: "field must be set for an Update request",
-				Value:  in.GetObjectMeta().GetUID(),
-			}},
-		}
-	}
 
 	// Convert the resource to a KVPair and pass that to the backend datastore, converting
 	// the response (if we get one) back to a resource.
@@ -244,6 +203,112 @@ func (c *resources) Watch(ctx context.Context, opts options.ListOptions, kind st
 	return w, nil
 }
 
+// prepareCreate validates the metadata of a resource that is being created, and fills in
+// the UID and creation timestamp if needed.
+func (c *resources) prepareCreate(kind string, in resource) error {
+	// A ResourceVersion should never be specified on a Create.
+	if len(in.GetObjectMeta().GetResourceVersion()) != 0 {
+		logWithResource(in).Info("Rejecting Create request with non-empty resource version")
+		return cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "Metadata.ResourceVersion",
+				Reason: "field must not be set for a Create request",
+				Value:  in.GetObjectMeta().GetResourceVersion(),
+			}},
+		}
+	}
+	if err := c.checkNamespace(in.GetObjectMeta().GetNamespace(), kind); err != nil {
+		return err
+	}
+
+	// Add in the UID and creation timestamp for the resource if needed.
+	creationTimestamp := in.GetObjectMeta().GetCreationTimestamp()
+	if creationTimestamp.IsZero() {
+		in.GetObjectMeta().SetCreationTimestamp(v1.Now())
+	}
+	if in.GetObjectMeta().GetUID() == "" {
+		in.GetObjectMeta().SetUID(uuid.NewUUID())
+	}
+	return nil
+}
+
+// prepareUpdate validates the metadata of a resource that is being updated.
+func (c *resources) prepareUpdate(kind string, in resource) error {
+	// A ResourceVersion should always be specified on an Update.
+	if len(in.GetObjectMeta().GetResourceVersion()) == 0 {
+		logWithResource(in).Info("Rejecting Update request with empty resource version")
+		return cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "Metadata.ResourceVersion",
+				Reason: "field must be set for an Update request",
+				Value:  in.GetObjectMeta().GetResourceVersion(),
+			}},
+		}
+	}
+	if err := c.checkNamespace(in.GetObjectMeta().GetNamespace(), kind); err != nil {
+		return err
+	}
+	creationTimestamp := in.GetObjectMeta().GetCreationTimestamp()
+	if creationTimestamp.IsZero() {
+		return cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "Metadata.CreationTimestamp",
+				Reason: "field must be set for an Update request",
+				Value:  in.GetObjectMeta().GetCreationTimestamp(),
+			}},
+		}
+	}
+	if in.GetObjectMeta().GetUID() == "" {
+		return cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "Metadata.UID",
+				Reason: "field must be set for an Update request",
+				Value:  in.GetObjectMeta().GetUID(),
+			}},
+		}
+	}
+	return nil
+}
+
+// Txn applies a set of creates, updates and deletes to the backend datastore as a single
+// transaction, so that either all or none of the operations are applied.  If the backend does
+// not support transactions (e.g. the Kubernetes datastore), an ErrorOperationNotSupported is
+// returned and none of the operations are applied.  Returns the resources returned by the
+// backend for each operation.
+func (c *resources) Txn(ctx context.Context, opts options.SetOptions, ops []resourceOp) ([]resource, error) {
+	t, ok := c.backend.(bapi.Transactor)
+	if !ok {
+		log.Warn("Transactions are not supported by the datastore")
+		return nil, cerrors.ErrorOperationNotSupported{Identifier: "datastore", Operation: "Txn"}
+	}
+
+	txnOps := make([]bapi.TxnOp, len(ops))
+	for i, op := range ops {
+		var err error
+		switch op.op {
+		case bapi.TxnCreate:
+			err = c.prepareCreate(op.kind, op.res)
+		case bapi.TxnUpdate:
+			err = c.prepareUpdate(op.kind, op.res)
+		default:
+			err = c.checkNamespace(op.res.GetObjectMeta().GetNamespace(), op.kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		txnOps[i] = bapi.TxnOp{Type: op.op, KVPair: c.resourceToKVPair(opts, op.kind, op.res)}
+	}
+
+	kvps, err := t.Txn(ctx, txnOps)
+	out := make([]resource, len(kvps))
+	for i, kvp := range kvps {
+		if kvp != nil {
+			out[i] = c.kvPairToResource(kvp)
+		}
+	}
+	return out, err
+}
+
 // resourceToKVPair converts the resource to a KVPair that can be consumed by the
 // backend datastore client.
 func (c *resources) resourceToKVPair(opts options.SetOptions, kind string, in resource) *model.KVPair {
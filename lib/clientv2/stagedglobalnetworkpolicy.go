@@ -0,0 +1,180 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// StagedGlobalNetworkPolicyInterface has methods to work with StagedGlobalNetworkPolicy resources.
+type StagedGlobalNetworkPolicyInterface interface {
+	Create(ctx context.Context, res *apiv2.StagedGlobalNetworkPolicy, opts options.SetOptions) (*apiv2.StagedGlobalNetworkPolicy, error)
+	Update(ctx context.Context, res *apiv2.StagedGlobalNetworkPolicy, opts options.SetOptions) (*apiv2.StagedGlobalNetworkPolicy, error)
+	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.StagedGlobalNetworkPolicy, error)
+	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.StagedGlobalNetworkPolicy, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.StagedGlobalNetworkPolicyList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+	Promote(ctx context.Context, name string, opts options.SetOptions) (*apiv2.GlobalNetworkPolicy, error)
+}
+
+// stagedGlobalNetworkPolicies implements StagedGlobalNetworkPolicyInterface
+type stagedGlobalNetworkPolicies struct {
+	client client
+}
+
+// Create takes the representation of a StagedGlobalNetworkPolicy and creates it.  Returns the stored
+// representation of the StagedGlobalNetworkPolicy, and an error, if there is any.
+func (r stagedGlobalNetworkPolicies) Create(ctx context.Context, res *apiv2.StagedGlobalNetworkPolicy, opts options.SetOptions) (*apiv2.StagedGlobalNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
+	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
+
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy, res)
+	if out != nil {
+		return out.(*apiv2.StagedGlobalNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a StagedGlobalNetworkPolicy and updates it. Returns the stored
+// representation of the StagedGlobalNetworkPolicy, and an error, if there is any.
+func (r stagedGlobalNetworkPolicies) Update(ctx context.Context, res *apiv2.StagedGlobalNetworkPolicy, opts options.SetOptions) (*apiv2.StagedGlobalNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
+	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
+
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy, res)
+	if out != nil {
+		return out.(*apiv2.StagedGlobalNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Delete takes name of the StagedGlobalNetworkPolicy and deletes it. Returns an error if one occurs.
+func (r stagedGlobalNetworkPolicies) Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.StagedGlobalNetworkPolicy, error) {
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.StagedGlobalNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Get takes name of the StagedGlobalNetworkPolicy, and returns the corresponding StagedGlobalNetworkPolicy object,
+// and an error if there is any.
+func (r stagedGlobalNetworkPolicies) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.StagedGlobalNetworkPolicy, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.StagedGlobalNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// List returns the list of StagedGlobalNetworkPolicy objects that match the supplied options.
+func (r stagedGlobalNetworkPolicies) List(ctx context.Context, opts options.ListOptions) (*apiv2.StagedGlobalNetworkPolicyList, error) {
+	res := &apiv2.StagedGlobalNetworkPolicyList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy, apiv2.KindStagedGlobalNetworkPolicyList, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the StagedGlobalNetworkPolicies that match the
+// supplied options.
+func (r stagedGlobalNetworkPolicies) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindStagedGlobalNetworkPolicy)
+}
+
+// Promote applies the staged change in the named StagedGlobalNetworkPolicy to the GlobalNetworkPolicy
+// of the same name, and removes the staged policy.  A staged Set creates or updates the
+// GlobalNetworkPolicy and returns it.  A staged Delete deletes the GlobalNetworkPolicy (if it exists)
+// and returns nil.
+//
+// Both the GlobalNetworkPolicy and the staged policy are modified using the revisions read at the
+// start of the promotion, so the promotion fails if either is modified concurrently.  Both
+// changes are made atomically in a single transaction.  Promotion requires a datastore that
+// supports transactions: on the Kubernetes datastore an ErrorOperationNotSupported is returned
+// and neither resource is modified.
+func (r stagedGlobalNetworkPolicies) Promote(ctx context.Context, name string, opts options.SetOptions) (*apiv2.GlobalNetworkPolicy, error) {
+	staged, err := r.Get(ctx, name, options.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.client.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			return nil, err
+		}
+		current = nil
+	}
+
+	action, enforced := apiv2.ConvertStagedGlobalPolicyToEnforced(staged)
+	var op resourceOp
+	switch {
+	case action == apiv2.StagedActionDelete && current == nil:
+		// There is no GlobalNetworkPolicy to delete, so just remove the staged policy.  This is still
+		// done as a transaction so that promotion is consistently refused if the datastore
+		// does not support transactions.
+		_, err := r.client.resources.Txn(ctx, opts, []resourceOp{
+			{op: bapi.TxnDelete, kind: apiv2.KindStagedGlobalNetworkPolicy, res: staged},
+		})
+		return nil, err
+	case action == apiv2.StagedActionDelete:
+		op = resourceOp{op: bapi.TxnDelete, kind: apiv2.KindGlobalNetworkPolicy, res: current}
+	case current == nil:
+		enforced.ObjectMeta = metav1.ObjectMeta{Name: staged.Name, Labels: staged.Labels, Annotations: staged.Annotations}
+		op = resourceOp{op: bapi.TxnCreate, kind: apiv2.KindGlobalNetworkPolicy, res: enforced}
+	default:
+		current.Labels = enforced.Labels
+		current.Annotations = enforced.Annotations
+		current.Spec = enforced.Spec
+		op = resourceOp{op: bapi.TxnUpdate, kind: apiv2.KindGlobalNetworkPolicy, res: current}
+	}
+	if action != apiv2.StagedActionDelete {
+		gnp := op.res.(*apiv2.GlobalNetworkPolicy)
+		if err := checkTierExists(ctx, r.client, gnp.Spec.Tier); err != nil {
+			return nil, err
+		}
+		defaultPolicyTypesField(gnp.Spec.IngressRules, gnp.Spec.EgressRules, &gnp.Spec.Types)
+	}
+
+	// Properly prefix the name, then apply the change to the GlobalNetworkPolicy and delete the
+	// staged policy in a single transaction.
+	op.res.GetObjectMeta().SetName(convertPolicyNameForStorage(name))
+	out, err := r.client.resources.Txn(ctx, opts, []resourceOp{
+		op,
+		{op: bapi.TxnDelete, kind: apiv2.KindStagedGlobalNetworkPolicy, res: staged},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if action == apiv2.StagedActionDelete {
+		return nil, nil
+	}
+
+	// Remove the prefix out of the returned policy name.
+	gnp := out[0].(*apiv2.GlobalNetworkPolicy)
+	gnp.GetObjectMeta().SetName(convertPolicyNameFromStorage(gnp.GetObjectMeta().GetName()))
+	return gnp, nil
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// StagedNetworkPolicyInterface has methods to work with StagedNetworkPolicy resources.
+type StagedNetworkPolicyInterface interface {
+	Create(ctx context.Context, res *apiv2.StagedNetworkPolicy, opts options.SetOptions) (*apiv2.StagedNetworkPolicy, error)
+	Update(ctx context.Context, res *apiv2.StagedNetworkPolicy, opts options.SetOptions) (*apiv2.StagedNetworkPolicy, error)
+	Delete(ctx context.Context, namespace, name string, opts options.DeleteOptions) (*apiv2.StagedNetworkPolicy, error)
+	Get(ctx context.Context, namespace, name string, opts options.GetOptions) (*apiv2.StagedNetworkPolicy, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.StagedNetworkPolicyList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+	Promote(ctx context.Context, namespace, name string, opts options.SetOptions) (*apiv2.NetworkPolicy, error)
+}
+
+// stagedNetworkPolicies implements StagedNetworkPolicyInterface
+type stagedNetworkPolicies struct {
+	client client
+}
+
+// Create takes the representation of a StagedNetworkPolicy and creates it.  Returns the stored
+// representation of the StagedNetworkPolicy, and an error, if there is any.
+func (r stagedNetworkPolicies) Create(ctx context.Context, res *apiv2.StagedNetworkPolicy, opts options.SetOptions) (*apiv2.StagedNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
+	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
+
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindStagedNetworkPolicy, res)
+	if out != nil {
+		return out.(*apiv2.StagedNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a StagedNetworkPolicy and updates it. Returns the stored
+// representation of the StagedNetworkPolicy, and an error, if there is any.
+func (r stagedNetworkPolicies) Update(ctx context.Context, res *apiv2.StagedNetworkPolicy, opts options.SetOptions) (*apiv2.StagedNetworkPolicy, error) {
+	if err := checkTierExists(ctx, r.client, res.Spec.Tier); err != nil {
+		return nil, err
+	}
+	defaultPolicyTypesField(res.Spec.IngressRules, res.Spec.EgressRules, &res.Spec.Types)
+
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindStagedNetworkPolicy, res)
+	if out != nil {
+		return out.(*apiv2.StagedNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Delete takes namespace and name of the StagedNetworkPolicy and deletes it. Returns an error if one occurs.
+func (r stagedNetworkPolicies) Delete(ctx context.Context, namespace, name string, opts options.DeleteOptions) (*apiv2.StagedNetworkPolicy, error) {
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindStagedNetworkPolicy, namespace, name)
+	if out != nil {
+		return out.(*apiv2.StagedNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// Get takes namespace and name of the StagedNetworkPolicy, and returns the corresponding StagedNetworkPolicy object,
+// and an error if there is any.
+func (r stagedNetworkPolicies) Get(ctx context.Context, namespace, name string, opts options.GetOptions) (*apiv2.StagedNetworkPolicy, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindStagedNetworkPolicy, namespace, name)
+	if out != nil {
+		return out.(*apiv2.StagedNetworkPolicy), err
+	}
+	return nil, err
+}
+
+// List returns the list of StagedNetworkPolicy objects that match the supplied options.
+func (r stagedNetworkPolicies) List(ctx context.Context, opts options.ListOptions) (*apiv2.StagedNetworkPolicyList, error) {
+	res := &apiv2.StagedNetworkPolicyList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindStagedNetworkPolicy, apiv2.KindStagedNetworkPolicyList, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the StagedNetworkPolicies that match the
+// supplied options.
+func (r stagedNetworkPolicies) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindStagedNetworkPolicy)
+}
+
+// Promote applies the staged change in the named StagedNetworkPolicy to the NetworkPolicy
+// of the same name and namespace, and removes the staged policy.  A staged Set creates or
+// updates the NetworkPolicy and returns it.  A staged Delete deletes the NetworkPolicy (if
+// it exists) and returns nil.
+//
+// Both the NetworkPolicy and the staged policy are modified using the revisions read at the
+// start of the promotion, so the promotion fails if either is modified concurrently.  Both
+// changes are made atomically in a single transaction.  Promotion requires a datastore that
+// supports transactions: on the Kubernetes datastore an ErrorOperationNotSupported is returned
+// and neither resource is modified.
+func (r stagedNetworkPolicies) Promote(ctx context.Context, namespace, name string, opts options.SetOptions) (*apiv2.NetworkPolicy, error) {
+	staged, err := r.Get(ctx, namespace, name, options.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.client.NetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			return nil, err
+		}
+		current = nil
+	}
+
+	action, enforced := apiv2.ConvertStagedPolicyToEnforced(staged)
+	var op resourceOp
+	switch {
+	case action == apiv2.StagedActionDelete && current == nil:
+		// There is no NetworkPolicy to delete, so just remove the staged policy.  This is still
+		// done as a transaction so that promotion is consistently refused if the datastore
+		// does not support transactions.
+		_, err := r.client.resources.Txn(ctx, opts, []resourceOp{
+			{op: bapi.TxnDelete, kind: apiv2.KindStagedNetworkPolicy, res: staged},
+		})
+		return nil, err
+	case action == apiv2.StagedActionDelete:
+		op = resourceOp{op: bapi.TxnDelete, kind: apiv2.KindNetworkPolicy, res: current}
+	case current == nil:
+		enforced.ObjectMeta = metav1.ObjectMeta{Name: staged.Name, Namespace: staged.Namespace, Labels: staged.Labels, Annotations: staged.Annotations}
+		op = resourceOp{op: bapi.TxnCreate, kind: apiv2.KindNetworkPolicy, res: enforced}
+	default:
+		current.Labels = enforced.Labels
+		current.Annotations = enforced.Annotations
+		current.Spec = enforced.Spec
+		op = resourceOp{op: bapi.TxnUpdate, kind: apiv2.KindNetworkPolicy, res: current}
+	}
+	if action != apiv2.StagedActionDelete {
+		np := op.res.(*apiv2.NetworkPolicy)
+		if err := checkTierExists(ctx, r.client, np.Spec.Tier); err != nil {
+			return nil, err
+		}
+		defaultPolicyTypesField(np.Spec.IngressRules, np.Spec.EgressRules, &np.Spec.Types)
+	}
+
+	// Properly prefix the name, then apply the change to the NetworkPolicy and delete the
+	// staged policy in a single transaction.
+	op.res.GetObjectMeta().SetName(convertPolicyNameForStorage(name))
+	out, err := r.client.resources.Txn(ctx, opts, []resourceOp{
+		op,
+		{op: bapi.TxnDelete, kind: apiv2.KindStagedNetworkPolicy, res: staged},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if action == apiv2.StagedActionDelete {
+		return nil, nil
+	}
+
+	// Remove the prefix out of the returned policy name.
+	np := out[0].(*apiv2.NetworkPolicy)
+	np.GetObjectMeta().SetName(convertPolicyNameFromStorage(np.GetObjectMeta().GetName()))
+	return np, nil
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+var _ = testutils.E2eDatastoreDescribe("Staged policy tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	order1 := 99.999
+	order2 := 22.222
+	namespace := "namespace-1"
+	name := "stagedp-1"
+	var c clientv2.Interface
+
+	BeforeEach(func() {
+		var err error
+		c, err = clientv2.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+	})
+
+	It("should create, get, update and delete a StagedGlobalNetworkPolicy", func() {
+		By("Creating a StagedGlobalNetworkPolicy in a tier that does not exist")
+		_, err := c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.StagedGlobalNetworkPolicySpec{Tier: "tier-1", Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).To(HaveOccurred())
+
+		By("Creating a StagedGlobalNetworkPolicy")
+		res, err := c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiv2.StagedGlobalNetworkPolicySpec{
+				Order:        &order1,
+				IngressRules: []apiv2.Rule{testutils.InRule1},
+				Selector:     "thing == 'value'",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Name).To(Equal(name))
+		Expect(res.Spec.Types).To(Equal([]apiv2.PolicyType{apiv2.PolicyTypeIngress}))
+
+		By("Getting and updating the StagedGlobalNetworkPolicy")
+		res, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		res.Spec.StagedAction = apiv2.StagedActionDelete
+		res, err = c.StagedGlobalNetworkPolicies().Update(ctx, res, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Spec.StagedAction).To(Equal(apiv2.StagedActionDelete))
+
+		By("Checking the staged policy is not an enforced policy")
+		_, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Listing and deleting the StagedGlobalNetworkPolicy")
+		list, err := c.StagedGlobalNetworkPolicies().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		_, err = c.StagedGlobalNetworkPolicies().Delete(ctx, name, options.DeleteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		list, err = c.StagedGlobalNetworkPolicies().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(0))
+	})
+
+	It("should promote StagedGlobalNetworkPolicies", func() {
+		if config.Spec.DatastoreType == apiconfig.Kubernetes {
+			Skip("Promotion requires a datastore that supports transactions")
+		}
+		By("Promoting a staged policy that does not exist")
+		_, err := c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Promoting a staged Set to create the GlobalNetworkPolicy")
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"version": "1"}},
+			Spec: apiv2.StagedGlobalNetworkPolicySpec{
+				Order:    &order1,
+				Selector: "thing == 'value'",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		gnp, err := c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Name).To(Equal(name))
+		Expect(gnp.Labels).To(Equal(map[string]string{"version": "1"}))
+		Expect(gnp.Spec.Order).To(Equal(&order1))
+		Expect(gnp.Spec.Selector).To(Equal("thing == 'value'"))
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Promoting a staged Set to update the GlobalNetworkPolicy")
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiv2.StagedGlobalNetworkPolicySpec{
+				StagedAction: apiv2.StagedActionSet,
+				Order:        &order2,
+				Selector:     "thing2 == 'value2'",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		gnp, err = c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Labels).To(BeEmpty())
+		Expect(gnp.Spec.Order).To(Equal(&order2))
+		gnp, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.Selector).To(Equal("thing2 == 'value2'"))
+
+		By("Promoting a staged Delete to delete the GlobalNetworkPolicy")
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.StagedGlobalNetworkPolicySpec{StagedAction: apiv2.StagedActionDelete},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		gnp, err = c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp).To(BeNil())
+		_, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+	})
+
+	It("should leave the staged policy in place if the promotion fails", func() {
+		By("Creating a tier and a staged policy in the tier")
+		_, err := c.Tiers().Create(ctx, &apiv2.Tier{
+			ObjectMeta: metav1.ObjectMeta{Name: "tier-1"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.StagedGlobalNetworkPolicySpec{Tier: "tier-1", Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Checking the tier cannot be deleted while the staged policy is in it")
+		_, err = c.Tiers().Delete(ctx, "tier-1", options.DeleteOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceInUse{}))
+		_, err = c.Tiers().Get(ctx, "tier-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Deleting the tier directly from the datastore and promoting the staged policy")
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = be.Delete(ctx, model.ResourceKey{Kind: apiv2.KindTier, Name: "tier-1"}, "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+
+		By("Checking the staged policy still exists and the GlobalNetworkPolicy does not")
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+	})
+
+	It("should promote StagedNetworkPolicies", func() {
+		if config.Spec.DatastoreType == apiconfig.Kubernetes {
+			Skip("Promotion requires a datastore that supports transactions")
+		}
+		By("Promoting a staged Set to create the NetworkPolicy")
+		_, err := c.StagedNetworkPolicies().Create(ctx, &apiv2.StagedNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: apiv2.StagedNetworkPolicySpec{
+				Order:       &order1,
+				EgressRules: []apiv2.Rule{testutils.EgressRule1},
+				Selector:    "thing == 'value'",
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		np, err := c.StagedNetworkPolicies().Promote(ctx, namespace, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(np.Name).To(Equal(name))
+		Expect(np.Namespace).To(Equal(namespace))
+		Expect(np.Spec.Types).To(Equal([]apiv2.PolicyType{apiv2.PolicyTypeEgress}))
+		_, err = c.StagedNetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Promoting a staged Delete to delete the NetworkPolicy")
+		_, err = c.StagedNetworkPolicies().Create(ctx, &apiv2.StagedNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       apiv2.StagedNetworkPolicySpec{StagedAction: apiv2.StagedActionDelete},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		np, err = c.StagedNetworkPolicies().Promote(ctx, namespace, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(np).To(BeNil())
+		_, err = c.NetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+	})
+})
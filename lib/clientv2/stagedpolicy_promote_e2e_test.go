@@ -0,0 +1,205 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+// failingBackend wraps a backend client and fails all writes to resources of a given kind.
+type failingBackend struct {
+	bapi.Client
+	kind string
+}
+
+func (b failingBackend) fails(key model.Key) error {
+	if rk, ok := key.(model.ResourceKey); ok && rk.Kind == b.kind {
+		return cerrors.ErrorDatastoreError{Err: errors.New("injected failure"), Identifier: key}
+	}
+	return nil
+}
+
+func (b failingBackend) Create(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	if err := b.fails(kvp.Key); err != nil {
+		return nil, err
+	}
+	return b.Client.Create(ctx, kvp)
+}
+
+func (b failingBackend) Update(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	if err := b.fails(kvp.Key); err != nil {
+		return nil, err
+	}
+	return b.Client.Update(ctx, kvp)
+}
+
+func (b failingBackend) Delete(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	if err := b.fails(key); err != nil {
+		return nil, err
+	}
+	return b.Client.Delete(ctx, key, revision)
+}
+
+// failingTxnBackend is a failingBackend that also supports transactions.
+type failingTxnBackend struct {
+	failingBackend
+}
+
+func (b failingTxnBackend) Txn(ctx context.Context, ops []bapi.TxnOp) ([]*model.KVPair, error) {
+	for _, op := range ops {
+		if err := b.fails(op.KVPair.Key); err != nil {
+			return nil, err
+		}
+	}
+	return b.Client.(bapi.Transactor).Txn(ctx, ops)
+}
+
+var _ = testutils.E2eDatastoreDescribe("Staged policy promotion failure tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	name := "stagedp-1"
+	namespace := "namespace-1"
+	order1 := 99.999
+	order2 := 22.222
+	var be bapi.Client
+
+	BeforeEach(func() {
+		var err error
+		be, err = backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+	})
+
+	// newClient returns a client that uses the given backend.
+	newClient := func(bc bapi.Client) client {
+		res := &resources{backend: bc}
+		return client{backend: bc, resources: res, reservations: newReservationCache(res)}
+	}
+
+	// newFailingClient returns a client whose writes to the given kind fail.
+	newFailingClient := func(kind string) client {
+		if _, ok := be.(bapi.Transactor); !ok {
+			Skip("Datastore does not support transactions")
+		}
+		return newClient(failingTxnBackend{failingBackend{Client: be, kind: kind}})
+	}
+
+	It("should refuse to promote if the datastore does not support transactions", func() {
+		c, err := New(config)
+		Expect(err).NotTo(HaveOccurred())
+		// A failingBackend with no kind does not fail any writes, but hides the Txn method
+		// of the backend.
+		nc := newClient(failingBackend{Client: be})
+
+		By("Refusing to promote a staged Set")
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.StagedGlobalNetworkPolicySpec{Order: &order1, Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = nc.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorOperationNotSupported{}))
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Refusing to promote a staged Delete of a NetworkPolicy that does not exist")
+		_, err = c.StagedNetworkPolicies().Create(ctx, &apiv2.StagedNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       apiv2.StagedNetworkPolicySpec{StagedAction: apiv2.StagedActionDelete},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = nc.StagedNetworkPolicies().Promote(ctx, namespace, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorOperationNotSupported{}))
+		_, err = c.StagedNetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should leave the staged policy in place if the GlobalNetworkPolicy write fails", func() {
+		c, err := New(config)
+		Expect(err).NotTo(HaveOccurred())
+		fc := newFailingClient(apiv2.KindGlobalNetworkPolicy)
+
+		By("Failing to promote a staged Set that creates the GlobalNetworkPolicy")
+		_, err = c.StagedGlobalNetworkPolicies().Create(ctx, &apiv2.StagedGlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.StagedGlobalNetworkPolicySpec{Order: &order1, Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fc.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorDatastoreError{}))
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Failing to promote a staged Set that updates the GlobalNetworkPolicy")
+		_, err = c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv2.GlobalNetworkPolicySpec{Order: &order2, Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fc.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorDatastoreError{}))
+		_, err = c.StagedGlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		gnp, err := c.GlobalNetworkPolicies().Get(ctx, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.Order).To(Equal(&order2))
+
+		By("Promoting the staged policy once the datastore is working")
+		gnp, err = c.StagedGlobalNetworkPolicies().Promote(ctx, name, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.Order).To(Equal(&order1))
+	})
+
+	It("should leave the staged policy in place if the NetworkPolicy delete fails", func() {
+		c, err := New(config)
+		Expect(err).NotTo(HaveOccurred())
+		fc := newFailingClient(apiv2.KindNetworkPolicy)
+
+		_, err = c.NetworkPolicies().Create(ctx, &apiv2.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       apiv2.NetworkPolicySpec{Selector: "all()"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.StagedNetworkPolicies().Create(ctx, &apiv2.StagedNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       apiv2.StagedNetworkPolicySpec{StagedAction: apiv2.StagedActionDelete},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fc.StagedNetworkPolicies().Promote(ctx, namespace, name, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorDatastoreError{}))
+		_, err = c.StagedNetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.NetworkPolicies().Get(ctx, namespace, name, options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2
+
+import (
+	"context"
+	"fmt"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// TierInterface has methods to work with Tier resources.
+type TierInterface interface {
+	Create(ctx context.Context, res *apiv2.Tier, opts options.SetOptions) (*apiv2.Tier, error)
+	Update(ctx context.Context, res *apiv2.Tier, opts options.SetOptions) (*apiv2.Tier, error)
+	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.Tier, error)
+	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.Tier, error)
+	List(ctx context.Context, opts options.ListOptions) (*apiv2.TierList, error)
+	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+}
+
+// tiers implements TierInterface
+type tiers struct {
+	client client
+}
+
+// Create takes the representation of a Tier and creates it.  Returns the stored
+// representation of the Tier, and an error, if there is any.
+func (r tiers) Create(ctx context.Context, res *apiv2.Tier, opts options.SetOptions) (*apiv2.Tier, error) {
+	out, err := r.client.resources.Create(ctx, opts, apiv2.KindTier, res)
+	if out != nil {
+		return out.(*apiv2.Tier), err
+	}
+	return nil, err
+}
+
+// Update takes the representation of a Tier and updates it. Returns the stored
+// representation of the Tier, and an error, if there is any.
+func (r tiers) Update(ctx context.Context, res *apiv2.Tier, opts options.SetOptions) (*apiv2.Tier, error) {
+	out, err := r.client.resources.Update(ctx, opts, apiv2.KindTier, res)
+	if out != nil {
+		return out.(*apiv2.Tier), err
+	}
+	return nil, err
+}
+
+// Delete takes name of the Tier and deletes it. Returns an error if one occurs.  A Tier
+// may not be deleted while any policies or staged policies are in the tier.
+func (r tiers) Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv2.Tier, error) {
+	n, err := countTierPolicies(ctx, r.client, name)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, cerrors.ErrorResourceInUse{
+			Identifier: name,
+			Reason:     fmt.Sprintf("%d policies are still in the tier", n),
+		}
+	}
+
+	out, err := r.client.resources.Delete(ctx, opts, apiv2.KindTier, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.Tier), err
+	}
+	return nil, err
+}
+
+// Get takes name of the Tier, and returns the corresponding Tier object,
+// and an error if there is any.
+func (r tiers) Get(ctx context.Context, name string, opts options.GetOptions) (*apiv2.Tier, error) {
+	out, err := r.client.resources.Get(ctx, opts, apiv2.KindTier, noNamespace, name)
+	if out != nil {
+		return out.(*apiv2.Tier), err
+	}
+	return nil, err
+}
+
+// List returns the list of Tier objects that match the supplied options.
+func (r tiers) List(ctx context.Context, opts options.ListOptions) (*apiv2.TierList, error) {
+	res := &apiv2.TierList{}
+	if err := r.client.resources.List(ctx, opts, apiv2.KindTier, apiv2.KindTierList, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watch returns a watch.Interface that watches the Tiers that match the
+// supplied options.
+func (r tiers) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
+	return r.client.resources.Watch(ctx, opts, apiv2.KindTier)
+}
+
+// checkTierExists returns a validation error if the named policy tier does not exist.  The
+// default tier does not need to be created, so always exists.
+func checkTierExists(ctx context.Context, c client, tier string) error {
+	if tier == "" || tier == apiv2.DefaultTierName {
+		return nil
+	}
+	if _, err := c.resources.Get(ctx, options.GetOptions{}, apiv2.KindTier, noNamespace, tier); err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+			return cerrors.ErrorValidation{
+				ErroredFields: []cerrors.ErroredField{{
+					Name:   "Spec.Tier",
+					Reason: "tier does not exist",
+					Value:  tier,
+				}},
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// countTierPolicies returns the number of policies and staged policies, of any kind and in
+// any namespace, that are in the named tier.  Policies that do not specify a tier are in the
+// default tier.
+func countTierPolicies(ctx context.Context, c client, tier string) (int, error) {
+	inTier := func(t string) bool {
+		if t == "" {
+			t = apiv2.DefaultTierName
+		}
+		return t == tier
+	}
+	n := 0
+	gnps := &apiv2.GlobalNetworkPolicyList{}
+	if err := c.resources.List(ctx, options.ListOptions{}, apiv2.KindGlobalNetworkPolicy, apiv2.KindGlobalNetworkPolicyList, gnps); err != nil {
+		return 0, err
+	}
+	for _, p := range gnps.Items {
+		if inTier(p.Spec.Tier) {
+			n++
+		}
+	}
+	nps := &apiv2.NetworkPolicyList{}
+	if err := c.resources.List(ctx, options.ListOptions{}, apiv2.KindNetworkPolicy, apiv2.KindNetworkPolicyList, nps); err != nil {
+		return 0, err
+	}
+	for _, p := range nps.Items {
+		if inTier(p.Spec.Tier) {
+			n++
+		}
+	}
+	sgnps := &apiv2.StagedGlobalNetworkPolicyList{}
+	if err := c.resources.List(ctx, options.ListOptions{}, apiv2.KindStagedGlobalNetworkPolicy, apiv2.KindStagedGlobalNetworkPolicyList, sgnps); err != nil {
+		return 0, err
+	}
+	for _, p := range sgnps.Items {
+		if inTier(p.Spec.Tier) {
+			n++
+		}
+	}
+	snps := &apiv2.StagedNetworkPolicyList{}
+	if err := c.resources.List(ctx, options.ListOptions{}, apiv2.KindStagedNetworkPolicy, apiv2.KindStagedNetworkPolicyList, snps); err != nil {
+		return 0, err
+	}
+	for _, p := range snps.Items {
+		if inTier(p.Spec.Tier) {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+var _ = testutils.E2eDatastoreDescribe("Tier tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	order1 := 100.0
+	order2 := 200.0
+	name1 := "tier-1"
+	name2 := "tier-2"
+	spec1 := apiv2.TierSpec{
+		Order: &order1,
+	}
+	spec2 := apiv2.TierSpec{
+		Order: &order2,
+	}
+
+	DescribeTable("Tier e2e CRUD tests",
+		func(name1, name2 string, spec1, spec2 apiv2.TierSpec) {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a new Tier with name1/spec1")
+			res1, outError := c.Tiers().Create(ctx, &apiv2.Tier{
+				ObjectMeta: metav1.ObjectMeta{Name: name1},
+				Spec:       spec1,
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res1, apiv2.KindTier, testutils.ExpectNoNamespace, name1, spec1)
+
+			By("Attempting to create the same Tier with name1 but with spec2")
+			_, outError = c.Tiers().Create(ctx, &apiv2.Tier{
+				ObjectMeta: metav1.ObjectMeta{Name: name1},
+				Spec:       spec2,
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource already exists: Tier(" + name1 + ")"))
+
+			By("Getting Tier (name1) and comparing the output against spec1")
+			res, outError := c.Tiers().Get(ctx, name1, options.GetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res, apiv2.KindTier, testutils.ExpectNoNamespace, name1, spec1)
+			Expect(res.ResourceVersion).To(Equal(res1.ResourceVersion))
+
+			By("Creating a new Tier with name2/spec2")
+			res2, outError := c.Tiers().Create(ctx, &apiv2.Tier{
+				ObjectMeta: metav1.ObjectMeta{Name: name2},
+				Spec:       spec2,
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res2, apiv2.KindTier, testutils.ExpectNoNamespace, name2, spec2)
+
+			By("Listing all the Tiers, expecting two results with name1/spec1 and name2/spec2")
+			outList, outError := c.Tiers().List(ctx, options.ListOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(2))
+			testutils.ExpectResource(&outList.Items[0], apiv2.KindTier, testutils.ExpectNoNamespace, name1, spec1)
+			testutils.ExpectResource(&outList.Items[1], apiv2.KindTier, testutils.ExpectNoNamespace, name2, spec2)
+
+			By("Updating Tier name1 with spec2")
+			res1.Spec = spec2
+			res1, outError = c.Tiers().Update(ctx, res1, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(res1, apiv2.KindTier, testutils.ExpectNoNamespace, name1, spec2)
+
+			By("Deleting Tier (name1)")
+			dres, outError := c.Tiers().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: res1.ResourceVersion})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(dres, apiv2.KindTier, testutils.ExpectNoNamespace, name1, spec2)
+
+			By("Deleting Tier (name2)")
+			dres, outError = c.Tiers().Delete(ctx, name2, options.DeleteOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			testutils.ExpectResource(dres, apiv2.KindTier, testutils.ExpectNoNamespace, name2, spec2)
+
+			By("Attempting to delete Tier (name2) again")
+			_, outError = c.Tiers().Delete(ctx, name2, options.DeleteOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource does not exist: Tier(" + name2 + ")"))
+
+			By("Listing all Tiers and expecting no items")
+			outList, outError = c.Tiers().List(ctx, options.ListOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(0))
+		},
+
+		// Test 1: Pass two fully populated TierSpecs and expect the series of operations to succeed.
+		Entry("Two fully populated TierSpecs", name1, name2, spec1, spec2),
+	)
+
+	Describe("Policy tier validation", func() {
+		It("should only allow policies in the default tier or an existing tier", func() {
+			c, err := clientv2.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a GlobalNetworkPolicy in the default tier without creating the tier")
+			_, outError := c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "gnp-1"},
+				Spec:       apiv2.GlobalNetworkPolicySpec{Tier: apiv2.DefaultTierName},
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+
+			By("Attempting to create a GlobalNetworkPolicy in a tier that does not exist")
+			_, outError = c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "gnp-2"},
+				Spec:       apiv2.GlobalNetworkPolicySpec{Tier: name1},
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("error with field Spec.Tier = '" + name1 + "' (tier does not exist)"))
+
+			By("Attempting to create a NetworkPolicy in a tier that does not exist")
+			_, outError = c.NetworkPolicies().Create(ctx, &apiv2.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1", Name: "np-1"},
+				Spec:       apiv2.NetworkPolicySpec{Tier: name1},
+			}, options.SetOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("error with field Spec.Tier = '" + name1 + "' (tier does not exist)"))
+
+			By("Creating the tier and then creating the policies in the tier")
+			_, outError = c.Tiers().Create(ctx, &apiv2.Tier{
+				ObjectMeta: metav1.ObjectMeta{Name: name1},
+				Spec:       spec1,
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			_, outError = c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "gnp-2"},
+				Spec:       apiv2.GlobalNetworkPolicySpec{Tier: name1},
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			_, outError = c.NetworkPolicies().Create(ctx, &apiv2.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1", Name: "np-1"},
+				Spec:       apiv2.NetworkPolicySpec{Tier: name1},
+			}, options.SetOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+
+			By("Attempting to delete the tier while it contains the policies")
+			_, outError = c.Tiers().Delete(ctx, name1, options.DeleteOptions{})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("resource is in use: " + name1 + ": 2 policies are still in the tier"))
+
+			By("Deleting the policies and then deleting the tier")
+			_, outError = c.GlobalNetworkPolicies().Delete(ctx, "gnp-2", options.DeleteOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			_, outError = c.NetworkPolicies().Delete(ctx, "namespace-1", "np-1", options.DeleteOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+			_, outError = c.Tiers().Delete(ctx, name1, options.DeleteOptions{})
+			Expect(outError).NotTo(HaveOccurred())
+		})
+	})
+})
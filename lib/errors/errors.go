@@ -49,6 +49,16 @@ func (e ErrorOperationNotSupported) Error() string {
 	return fmt.Sprintf("operation %s is not supported on %s", e.Operation, e.Identifier)
 }
 
+// Error indicating a resource cannot be deleted because it is still in use.
+type ErrorResourceInUse struct {
+	Identifier interface{}
+	Reason     string
+}
+
+func (e ErrorResourceInUse) Error() string {
+	return fmt.Sprintf("resource is in use: %s: %s", e.Identifier, e.Reason)
+}
+
 // Error indicating a resource already exists.  Used when attempting to create a
 // resource that already exists.
 type ErrorResourceAlreadyExists struct {
@@ -0,0 +1,118 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Allocation strategies", func() {
+
+	It("should generate blocks in order for the SequentialBlock strategy", func() {
+		pool := apiv2.IPPool{Spec: apiv2.IPPoolSpec{
+			CIDR:               "10.0.0.0/24",
+			AllocationStrategy: apiv2.AllocationStrategySequentialBlock,
+		}}
+		blocks := newBlockGenerator(pool, "testHost")
+		for _, expected := range []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"} {
+			b := blocks()
+			Expect(b).NotTo(BeNil())
+			Expect(b.String()).To(Equal(expected))
+		}
+		Expect(blocks()).To(BeNil())
+	})
+
+	It("should not generate blocks for a sequential pool smaller than the block size", func() {
+		pool := apiv2.IPPool{Spec: apiv2.IPPoolSpec{
+			CIDR:               "10.0.0.0/28",
+			AllocationStrategy: apiv2.AllocationStrategySequentialBlock,
+		}}
+		Expect(newBlockGenerator(pool, "testHost")()).To(BeNil())
+	})
+
+	It("should generate every block in the pool for the RandomBlock strategy", func() {
+		pool := apiv2.IPPool{Spec: apiv2.IPPoolSpec{CIDR: "10.0.0.0/24"}}
+		blocks := newBlockGenerator(pool, "testHost")
+		seen := []string{}
+		for b := blocks(); b != nil; b = blocks() {
+			seen = append(seen, b.String())
+		}
+		Expect(seen).To(ConsistOf("10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"))
+	})
+
+	// assignAndRelease assigns every address in a /29 block, then releases
+	// 10.0.0.5 followed by 10.0.0.2 and returns the block.
+	assignAndRelease := func(strategy apiv2.AllocationStrategy) *allocationBlock {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/29"))
+		_, err := b.autoAssign(8, nil, "testHost", nil, false, nil, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		for _, ip := range []string{"10.0.0.5", "10.0.0.2"} {
+			_, _, err = b.release([]cnet.IP{cnet.MustParseIP(ip)})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return &b
+	}
+
+	It("should assign the lowest free address for the SequentialBlock strategy", func() {
+		b := assignAndRelease(apiv2.AllocationStrategySequentialBlock)
+		ips, err := b.autoAssign(1, nil, "testHost", nil, false, nil, apiv2.AllocationStrategySequentialBlock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ips[0].String()).To(Equal("10.0.0.2"))
+		Expect(b.Unallocated).To(Equal([]int{5}))
+	})
+
+	It("should assign the least recently released address for the RandomBlock strategy", func() {
+		b := assignAndRelease(apiv2.AllocationStrategyRandomBlock)
+		ips, err := b.autoAssign(1, nil, "testHost", nil, false, nil, apiv2.AllocationStrategyRandomBlock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ips[0].String()).To(Equal("10.0.0.5"))
+		Expect(b.Unallocated).To(Equal([]int{2}))
+	})
+
+	It("should assign the least recently released address for the LRUAddress strategy", func() {
+		b := assignAndRelease(apiv2.AllocationStrategyLRUAddress)
+		ips, err := b.autoAssign(1, nil, "testHost", nil, false, nil, apiv2.AllocationStrategyLRUAddress)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ips[0].String()).To(Equal("10.0.0.5"))
+		Expect(b.Unallocated).To(Equal([]int{2}))
+	})
+
+	It("should skip reserved addresses for the LRUAddress strategy", func() {
+		b := assignAndRelease(apiv2.AllocationStrategyLRUAddress)
+		reserved := reservedAddresses{cnet.MustParseCIDR("10.0.0.5/32")}
+		ips, err := b.autoAssign(1, nil, "testHost", nil, false, reserved, apiv2.AllocationStrategyLRUAddress)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ips[0].String()).To(Equal("10.0.0.2"))
+		Expect(b.Unallocated).To(Equal([]int{5}))
+	})
+
+	It("should return the strategy of the pool containing a block", func() {
+		pools := []apiv2.IPPool{
+			{Spec: apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", AllocationStrategy: apiv2.AllocationStrategyLRUAddress}},
+			{Spec: apiv2.IPPoolSpec{CIDR: "10.1.0.0/24"}},
+		}
+		Expect(getAllocationStrategy(cnet.MustParseCIDR("10.0.0.64/26"), pools)).To(Equal(apiv2.AllocationStrategyLRUAddress))
+		Expect(getAllocationStrategy(cnet.MustParseCIDR("10.1.0.64/26"), pools)).To(Equal(apiv2.AllocationStrategyRandomBlock))
+		Expect(getAllocationStrategy(cnet.MustParseCIDR("10.2.0.64/26"), pools)).To(Equal(apiv2.AllocationStrategyRandomBlock))
+	})
+})
@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("Borrowing policy",
+	func(policy BorrowingPolicy, poolsExhausted, expected bool) {
+		Expect(borrowingAllowed(policy, poolsExhausted)).To(Equal(expected))
+	},
+	Entry("default, pools not exhausted", BorrowingPolicy(""), false, true),
+	Entry("default, pools exhausted", BorrowingPolicy(""), true, true),
+	Entry("always, pools not exhausted", BorrowingAlways, false, true),
+	Entry("always, pools exhausted", BorrowingAlways, true, true),
+	Entry("only when pool exhausted, pools not exhausted", BorrowingOnlyWhenPoolExhausted, false, false),
+	Entry("only when pool exhausted, pools exhausted", BorrowingOnlyWhenPoolExhausted, true, true),
+	Entry("never, pools not exhausted", BorrowingNever, false, false),
+	Entry("never, pools exhausted", BorrowingNever, true, false),
+)
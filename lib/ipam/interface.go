@@ -26,11 +26,13 @@ type Interface interface {
 	// must fall within a configured pool.  AssignIP will claim block affinity as needed
 	// in order to satisfy the assignment.  An error will be returned if the IP address
 	// is already assigned, or if StrictAffinity is enabled and the address is within
-	// a block that does not have affinity for the given host.
+	// a block that does not have affinity for the given host.  An error is also returned
+	// if the address is reserved by an IPReservation, unless IgnoreReservations is set.
 	AssignIP(ctx context.Context, args AssignIPArgs) error
 
 	// AutoAssign automatically assigns one or more IP addresses as specified by the
-	// provided AutoAssignArgs.  AutoAssign returns the list of the assigned IPv4 addresses,
+	// provided AutoAssignArgs.  Addresses reserved by an IPReservation are never
+	// auto-assigned.  AutoAssign returns the list of the assigned IPv4 addresses,
 	// and the list of the assigned IPv6 addresses.
 	AutoAssign(ctx context.Context, args AutoAssignArgs) ([]cnet.IP, []cnet.IP, error)
 
@@ -70,15 +72,36 @@ type Interface interface {
 	// the specified pool across all hosts.
 	ReleasePoolAffinities(ctx context.Context, pool cnet.IPNet) error
 
+	// ReclaimEmptyBlocks releases the affinity of all blocks that have been empty for at
+	// least the configured EmptyBlockIdlePeriod, and returns the CIDRs of the reclaimed
+	// blocks.  This is intended to be called periodically, and is safe against concurrent
+	// assignment.
+	ReclaimEmptyBlocks(ctx context.Context) ([]cnet.IPNet, error)
+
 	// GetIPAMConfig returns the global IPAM configuration.  If no IPAM configuration
 	// has been set, returns a default configuration with StrictAffinity disabled
 	// and AutoAllocateBlocks enabled.
 	GetIPAMConfig(ctx context.Context) (*IPAMConfig, error)
 
-	// SetIPAMConfig sets global IPAM configuration.  This can only
-	// be done when there are no allocated blocks and IP addresses.
+	// SetIPAMConfig sets global IPAM configuration.  StrictAffinity and AutoAllocateBlocks
+	// can only be changed when there are no allocated blocks and IP addresses.
 	SetIPAMConfig(ctx context.Context, cfg IPAMConfig) error
 
+	// GetUtilization returns the utilization of the requested IP pools, or of all pools
+	// if none are requested.  The utilization of each pool is broken down by block and by
+	// host, and includes the number of addresses borrowed from blocks affine to another host.
+	GetUtilization(ctx context.Context, args GetUtilizationArgs) ([]PoolUtilization, error)
+
+	// ListAllocations returns the IP address, handle, host and attributes of every
+	// allocation matching the given filter, ordered by IP address.
+	ListAllocations(ctx context.Context, filter AllocationFilter) ([]Allocation, error)
+
+	// DrainPool releases the affinities of the empty blocks in the given pool, and reports
+	// the allocations that remain in the pool by host and by handle.  The pool must be
+	// disabled, unless it has already been deleted.  Once no allocations remain, the pool
+	// may be deleted without forcing.
+	DrainPool(ctx context.Context, cidr cnet.IPNet) (*DrainPoolReport, error)
+
 	// RemoveIPAMHost releases affinity for all blocks on the given host,
 	// and removes all host-specific IPAM data from the datastore.
 	// RemoveIPAMHost does not release any IP addresses claimed on the given host.
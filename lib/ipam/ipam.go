@@ -19,13 +19,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
 
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/selector"
 )
 
 const (
@@ -38,10 +42,14 @@ const (
 // NewIPAMClient returns a new ipamClient, which implements Interface.
 // Consumers of the Calico API should not create this directly, but should
 // access IPAM through the main client IPAM accessor (e.g. clientv2.IPAM())
-func NewIPAMClient(client bapi.Client, pools PoolAccessorInterface) Interface {
+//
+// The reservations accessor may be nil, in which case no addresses are reserved.
+func NewIPAMClient(client bapi.Client, pools PoolAccessorInterface, reservations ReservationAccessorInterface) Interface {
 	return &ipamClient{
-		client: client,
-		pools:  pools,
+		client:       client,
+		pools:        pools,
+		reservations: reservations,
+		clock:        clock.RealClock{},
 		blockReaderWriter: blockReaderWriter{
 			client: client,
 			pools:  pools,
@@ -53,6 +61,8 @@ func NewIPAMClient(client bapi.Client, pools PoolAccessorInterface) Interface {
 type ipamClient struct {
 	client            bapi.Client
 	pools             PoolAccessorInterface
+	reservations      ReservationAccessorInterface
+	clock             clock.Clock
 	blockReaderWriter blockReaderWriter
 }
 
@@ -68,6 +78,12 @@ func (c ipamClient) AutoAssign(ctx context.Context, args AutoAssignArgs) ([]net.
 	var v4list, v6list []net.IP
 	var err error
 
+	// Look up the reserved addresses once for the whole request.
+	reserved, err := c.getReservedAddresses(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if args.Num4 != 0 {
 		// Assign IPv4 addresses.
 		log.Debugf("Assigning IPv4 addresses")
@@ -76,7 +92,7 @@ func (c ipamClient) AutoAssign(ctx context.Context, args AutoAssignArgs) ([]net.
 				return nil, nil, fmt.Errorf("provided IPv4 IPPools list contains one or more IPv6 IPPools")
 			}
 		}
-		v4list, err = c.autoAssign(ctx, args.Num4, args.HandleID, args.Attrs, args.IPv4Pools, ipv4, hostname)
+		v4list, err = c.autoAssign(ctx, args.Num4, args.HandleID, args.Attrs, args.IPv4Pools, ipv4, hostname, reserved)
 		if err != nil {
 			log.Errorf("Error assigning IPV4 addresses: %s", err)
 			return nil, nil, err
@@ -91,7 +107,7 @@ func (c ipamClient) AutoAssign(ctx context.Context, args AutoAssignArgs) ([]net.
 				return nil, nil, fmt.Errorf("provided IPv6 IPPools list contains one or more IPv4 IPPools")
 			}
 		}
-		v6list, err = c.autoAssign(ctx, args.Num6, args.HandleID, args.Attrs, args.IPv6Pools, ipv6, hostname)
+		v6list, err = c.autoAssign(ctx, args.Num6, args.HandleID, args.Attrs, args.IPv6Pools, ipv6, hostname, reserved)
 		if err != nil {
 			log.Errorf("Error assigning IPV6 addresses: %s", err)
 			return nil, nil, err
@@ -101,7 +117,24 @@ func (c ipamClient) AutoAssign(ctx context.Context, args AutoAssignArgs) ([]net.
 	return v4list, v6list, nil
 }
 
-func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, attrs map[string]string, pools []net.IPNet, version ipVersion, host string) ([]net.IP, error) {
+func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, attrs map[string]string, pools []net.IPNet, version ipVersion, host string, reserved reservedAddresses) ([]net.IP, error) {
+
+	// If no pools were explicitly requested, restrict the assignment to the enabled
+	// pools whose node selector matches this host.
+	if len(pools) == 0 {
+		var err error
+		if pools, err = c.getPoolsForHost(ctx, host, version); err != nil {
+			return nil, err
+		}
+	}
+
+	// Look up the pools so that each block is assigned from using the allocation strategy
+	// of its pool.
+	allPools, err := c.pools.GetAllPools()
+	if err != nil {
+		log.Errorf("Error reading configured pools: %s", err)
+		return nil, err
+	}
 
 	// Start by trying to assign from one of the host-affine blocks.  We
 	// always do strict checking at this stage, so it doesn't matter whether
@@ -120,7 +153,7 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 		}
 		cidr := affBlocks[0]
 		affBlocks = affBlocks[1:]
-		ips, _ = c.assignFromExistingBlock(ctx, cidr, num, handleID, attrs, host, true)
+		ips, _ = c.assignFromExistingBlock(ctx, cidr, num, handleID, attrs, host, true, reserved, getAllocationStrategy(cidr, allPools))
 		log.Debugf("Block '%s' provided addresses: %v", cidr.String(), ips)
 	}
 
@@ -133,6 +166,8 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 		return nil, err
 	}
 	log.Debugf("Allocate new blocks? Config: %+v", config)
+	var limitErr, reservedErr error
+	poolsExhausted := false
 	if config.AutoAllocateBlocks == true {
 		rem := num - len(ips)
 		retries := ipamEtcdRetries
@@ -140,21 +175,36 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 			// Claim a new block.
 			log.Infof("Need to allocate %d more addresses - allocate another block", rem)
 			retries = retries - 1
-			b, err := c.blockReaderWriter.claimNewAffineBlock(ctx, host, version, pools, *config)
+			b, err := c.blockReaderWriter.claimNewAffineBlock(ctx, host, version, pools, *config, reserved)
 			if err != nil {
 				// Error claiming new block.
 				if _, ok := err.(noFreeBlocksError); ok {
 					// No free blocks.  Break.
+					poolsExhausted = true
+					break
+				} else if _, ok := err.(blocksReservedError); ok {
+					// The only free blocks are fully reserved.  Break.
+					poolsExhausted = true
+					reservedErr = err
+					break
+				} else if _, ok := err.(maxBlocksPerHostError); ok {
+					// The host may not claim any more blocks.  Break.
+					limitErr = err
 					break
 				}
 				log.Errorf("Error claiming new block: %s", err)
-				return nil, err
+				return ips, err
 			} else {
 				// Claim successful.  Assign addresses from the new block.
 				log.Infof("Claimed new block %s - assigning %d addresses", b.String(), rem)
-				newIPs, err := c.assignFromExistingBlock(ctx, *b, rem, handleID, attrs, host, config.StrictAffinity)
+				newIPs, err := c.assignFromExistingBlock(ctx, *b, rem, handleID, attrs, host, config.StrictAffinity, reserved, getAllocationStrategy(*b, allPools))
 				if err != nil {
-					log.Warningf("Failed to assign IPs:", err)
+					log.Warningf("Failed to assign IPs: %s", err)
+					break
+				}
+				if len(newIPs) == 0 {
+					// Don't keep claiming blocks that provide no addresses.
+					log.Warningf("New block %s provided no addresses", b.String())
 					break
 				}
 				log.Debugf("Assigned IPs from new block: %s", newIPs)
@@ -164,14 +214,15 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 		}
 
 		if retries == 0 {
-			return nil, errors.New("Max retries hit - excessive concurrent IPAM requests")
+			return ips, errors.New("Max retries hit - excessive concurrent IPAM requests")
 		}
 	}
 
 	// If there are still addresses to allocate, we've now tried all blocks
 	// with some affinity to us, and tried (and failed) to allocate new
-	// ones.  If we do not require strict host affinity, our last option is
-	// a random hunt through any blocks we haven't yet tried.
+	// ones.  If we do not require strict host affinity, and the borrowing
+	// policy allows it, our last option is a random hunt through any blocks
+	// we haven't yet tried.
 	//
 	// Note that this processing simply takes all of the IP pools and breaks
 	// them up into block-sized CIDRs, then shuffles and searches through each
@@ -183,35 +234,37 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 	// blocks, then we should query the actual allocation blocks and assign
 	// from those.
 	rem := num - len(ips)
-	if config.StrictAffinity != true && rem != 0 {
+	borrow := config.StrictAffinity != true && borrowingAllowed(config.AllowBorrowing, poolsExhausted)
+	if borrow && rem != 0 {
 		log.Infof("Attempting to assign %d more addresses from non-affine blocks", rem)
-		// Figure out the pools to allocate from.
-		if len(pools) == 0 {
-			// Default to all configured pools.
-			pools, err = c.pools.GetEnabledPools(version.Number)
-			if err != nil {
-				log.Errorf("Error reading configured pools: %s", err)
-				return ips, nil
-			}
+		// Figure out the pools to allocate from.  Default to all configured pools if
+		// no pools were requested.
+		enabledPools, err := c.pools.GetEnabledPools(version.Number)
+		if err != nil {
+			log.Errorf("Error reading configured pools: %s", err)
+			return ips, nil
 		}
 
 		// Iterate over pools and assign addresses until we either run out of pools,
 		// or the request has been satisfied.
-		for _, p := range pools {
-			log.Debugf("Assigning from random blocks in pool %s", p.String())
-			newBlock := randomBlockGenerator(p, host)
+		for _, p := range enabledPools {
+			if !isPoolInRequestedPools(p, pools) {
+				continue
+			}
+			log.Debugf("Assigning from random blocks in pool %s", p.Spec.CIDR)
+			newBlock := newBlockGenerator(p, host)
 			for rem > 0 {
 				// Grab a new random block.
 				blockCIDR := newBlock()
 				if blockCIDR == nil {
-					log.Warningf("All addresses exhausted in pool %s", p.String())
+					log.Warningf("All addresses exhausted in pool %s", p.Spec.CIDR)
 					break
 				}
 
 				// Attempt to assign from the block.
-				newIPs, err := c.assignFromExistingBlock(ctx, *blockCIDR, rem, handleID, attrs, host, false)
+				newIPs, err := c.assignFromExistingBlock(ctx, *blockCIDR, rem, handleID, attrs, host, false, reserved, getAllocationStrategy(*blockCIDR, allPools))
 				if err != nil {
-					log.Warningf("Failed to assign IPs in pool %s: %s", p.String(), err)
+					log.Warningf("Failed to assign IPs in pool %s: %s", p.Spec.CIDR, err)
 					break
 				}
 				ips = append(ips, newIPs...)
@@ -221,23 +274,125 @@ func (c ipamClient) autoAssign(ctx context.Context, num int, handleID *string, a
 	}
 
 	log.Infof("Auto-assigned %d out of %d IPv%ds: %v", len(ips), num, version.Number, ips)
+	if len(ips) < num && limitErr != nil {
+		// Report the block limit as the reason the request could not be satisfied.
+		return ips, limitErr
+	}
+	if len(ips) < num && reservedErr != nil {
+		// Report the reservations as the reason the request could not be satisfied.
+		return ips, reservedErr
+	}
 	return ips, nil
 }
 
+// borrowingAllowed returns true if the given borrowing policy allows assigning addresses
+// from non-affine blocks, given whether the pools have run out of free blocks.
+func borrowingAllowed(policy BorrowingPolicy, poolsExhausted bool) bool {
+	switch policy {
+	case BorrowingNever:
+		return false
+	case BorrowingOnlyWhenPoolExhausted:
+		return poolsExhausted
+	default:
+		return true
+	}
+}
+
+// getPoolsForHost returns the CIDRs of the enabled pools of the requested IP version
+// that the host may be assigned addresses from, based on the pool node selectors and
+// the labels on the host's Node resource.  An empty list is returned if there is no
+// restriction, i.e. when none of the enabled pools has a node selector.
+func (c ipamClient) getPoolsForHost(ctx context.Context, host string, version ipVersion) ([]net.IPNet, error) {
+	enabledPools, err := c.pools.GetEnabledPools(version.Number)
+	if err != nil {
+		log.Errorf("Error reading configured pools: %s", err)
+		return nil, err
+	}
+
+	// Only look up the node if at least one pool restricts the nodes it applies to.
+	restricted := false
+	for _, p := range enabledPools {
+		if p.Spec.NodeSelector != "" {
+			restricted = true
+			break
+		}
+	}
+	if !restricted {
+		return nil, nil
+	}
+
+	// Get the labels for the host.  If there is no Node resource for the host then
+	// treat it as having no labels.
+	var labels map[string]string
+	kvp, err := c.client.Get(ctx, model.ResourceKey{Kind: apiv2.KindNode, Name: host}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.WithError(err).Errorf("Error reading node for host '%s'", host)
+			return nil, err
+		}
+		log.Debugf("No node resource for host '%s', assuming no labels", host)
+	} else {
+		labels = kvp.Value.(*apiv2.Node).Labels
+	}
+
+	pools := []net.IPNet{}
+	for _, p := range enabledPools {
+		if p.Spec.NodeSelector != "" {
+			sel, err := selector.Parse(p.Spec.NodeSelector)
+			if err != nil {
+				log.WithError(err).Errorf("IPPool %s has an invalid node selector", p.Name)
+				continue
+			}
+			if !sel.Evaluate(labels) {
+				log.Debugf("IPPool %s node selector does not match host '%s'", p.Name, host)
+				continue
+			}
+		}
+		_, cidr, err := net.ParseCIDR(p.Spec.CIDR)
+		if err != nil {
+			log.WithError(err).Errorf("IPPool %s is configured with an invalid CIDR", p.Name)
+			continue
+		}
+		pools = append(pools, *cidr)
+	}
+
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("no enabled IPv%d pools match the node selector for host '%s'", version.Number, host)
+	}
+	log.Debugf("IPv%d pools for host '%s': %v", version.Number, host, pools)
+	return pools, nil
+}
+
 // AssignIP assigns the provided IP address to the provided host.  The IP address
 // must fall within a configured pool.  AssignIP will claim block affinity as needed
 // in order to satisfy the assignment.  An error will be returned if the IP address
 // is already assigned, or if StrictAffinity is enabled and the address is within
-// a block that does not have affinity for the given host.
+// a block that does not have affinity for the given host.  An error is also returned
+// if the address is reserved, unless IgnoreReservations is set.
 func (c ipamClient) AssignIP(ctx context.Context, args AssignIPArgs) error {
 	hostname := decideHostname(args.Hostname)
 	log.Infof("Assigning IP %s to host: %s", args.IP, hostname)
 
-	if !c.blockReaderWriter.withinConfiguredPools(args.IP) {
+	pool, err := c.blockReaderWriter.getEnabledPoolForIP(args.IP)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
 		return errors.New("The provided IP address is not in a configured pool\n")
 	}
 
-	blockCIDR := getBlockCIDRForAddress(args.IP)
+	if !args.IgnoreReservations {
+		reserved, err := c.getReservedAddresses(ctx)
+		if err != nil {
+			return err
+		}
+		if reserved.contains(args.IP) {
+			log.Errorf("IP address %s is reserved", args.IP)
+			return reservedAddressError{IP: args.IP}
+		}
+	}
+
+	blockCIDR := getBlockCIDRForAddress(args.IP, pool)
 	log.Debugf("IP %s is in block '%s'", args.IP.String(), blockCIDR.String())
 	for i := 0; i < ipamEtcdRetries; i++ {
 		obj, err := c.client.Get(ctx, model.BlockKey{blockCIDR}, "")
@@ -300,9 +455,32 @@ func (c ipamClient) AssignIP(ctx context.Context, args AssignIPArgs) error {
 	return errors.New("Max retries hit - excessive concurrent IPAM requests")
 }
 
+// getReservedAddresses returns the reserved addresses, or an empty set if the client
+// has no reservations accessor.
+func (c ipamClient) getReservedAddresses(ctx context.Context) (reservedAddresses, error) {
+	if c.reservations == nil {
+		return nil, nil
+	}
+	cidrs, err := c.reservations.GetReservedCIDRs(ctx)
+	if err != nil {
+		log.Errorf("Error reading IP reservations: %s", err)
+		return nil, err
+	}
+	return reservedAddresses(cidrs), nil
+}
+
 // ReleaseIPs releases any of the given IP addresses that are currently assigned,
 // so that they are available to be used in another assignment.
 func (c ipamClient) ReleaseIPs(ctx context.Context, ips []net.IP) ([]net.IP, error) {
+	return c.releaseIPs(ctx, ips, nil)
+}
+
+// releaseIPs releases the given IP addresses.  If handles is not nil, each address is only
+// released if it is still assigned with the handle stored in handles against the address
+// string, or with no handle if that is nil.  This ensures that an address that has been
+// released and assigned again since it was read is not released.  The addresses that are
+// not released are returned.
+func (c ipamClient) releaseIPs(ctx context.Context, ips []net.IP, handles map[string]*string) ([]net.IP, error) {
 	log.Infof("Releasing IP addresses: %v", ips)
 	unallocated := []net.IP{}
 
@@ -311,7 +489,11 @@ func (c ipamClient) ReleaseIPs(ctx context.Context, ips []net.IP) ([]net.IP, err
 	ipsByBlock := map[string][]net.IP{}
 	for _, ip := range ips {
 		// Check if we've already got an entry for this block.
-		blockCIDR := getBlockCIDRForAddress(ip)
+		blockCIDR, err := c.blockReaderWriter.getBlockCIDRForAddress(ctx, ip)
+		if err != nil {
+			log.Errorf("Error reading configured pools: %s", err)
+			return nil, err
+		}
 		cidrStr := blockCIDR.String()
 		if _, exists := ipsByBlock[cidrStr]; !exists {
 			// Entry does not exist, create it.
@@ -325,7 +507,7 @@ func (c ipamClient) ReleaseIPs(ctx context.Context, ips []net.IP) ([]net.IP, err
 	// Release IPs for each block.
 	for cidrStr, ips := range ipsByBlock {
 		_, cidr, _ := net.ParseCIDR(cidrStr)
-		unalloc, err := c.releaseIPsFromBlock(ctx, ips, *cidr)
+		unalloc, err := c.releaseIPsFromBlock(ctx, ips, *cidr, handles)
 		if err != nil {
 			log.Errorf("Error releasing IPs: %s", err)
 			return nil, err
@@ -335,7 +517,7 @@ func (c ipamClient) ReleaseIPs(ctx context.Context, ips []net.IP) ([]net.IP, err
 	return unallocated, nil
 }
 
-func (c ipamClient) releaseIPsFromBlock(ctx context.Context, ips []net.IP, blockCIDR net.IPNet) ([]net.IP, error) {
+func (c ipamClient) releaseIPsFromBlock(ctx context.Context, ips []net.IP, blockCIDR net.IPNet, handles map[string]*string) ([]net.IP, error) {
 	for i := 0; i < ipamEtcdRetries; i++ {
 		obj, err := c.client.Get(ctx, model.BlockKey{CIDR: blockCIDR}, "")
 		if err != nil {
@@ -351,11 +533,29 @@ func (c ipamClient) releaseIPsFromBlock(ctx context.Context, ips []net.IP, block
 		// Block exists - get the allocationBlock from the KVPair.
 		b := allocationBlock{obj.Value.(*model.AllocationBlock)}
 
+		// If the handles are specified, skip the addresses that are no longer assigned
+		// with the expected handle.  The block is written using CAS, so these cannot be
+		// reassigned before the update.
+		toRelease := ips
+		skipped := []net.IP{}
+		if handles != nil {
+			toRelease = []net.IP{}
+			for _, ip := range ips {
+				if b.assignedWithHandle(ip, handles[ip.String()]) {
+					toRelease = append(toRelease, ip)
+				} else {
+					log.Infof("Address %s is no longer assigned with the expected handle - not releasing", ip)
+					skipped = append(skipped, ip)
+				}
+			}
+		}
+
 		// Release the IPs.
-		unallocated, handles, err2 := b.release(ips)
+		unallocated, countByHandle, err2 := b.release(toRelease)
 		if err2 != nil {
 			return nil, err2
 		}
+		unallocated = append(unallocated, skipped...)
 		if len(ips) == len(unallocated) {
 			// All the given IP addresses are already unallocated.
 			// Just return.
@@ -388,8 +588,8 @@ func (c ipamClient) releaseIPsFromBlock(ctx context.Context, ips []net.IP, block
 		}
 
 		// Success - decrement handles.
-		log.Debugf("Decrementing handles: %v", handles)
-		for handleID, amount := range handles {
+		log.Debugf("Decrementing handles: %v", countByHandle)
+		for handleID, amount := range countByHandle {
 			c.decrementHandle(ctx, handleID, blockCIDR, amount)
 		}
 		return unallocated, nil
@@ -399,7 +599,8 @@ func (c ipamClient) releaseIPsFromBlock(ctx context.Context, ips []net.IP, block
 
 func (c ipamClient) assignFromExistingBlock(
 	ctx context.Context, blockCIDR net.IPNet, num int, handleID *string,
-	attrs map[string]string, host string, affCheck bool,
+	attrs map[string]string, host string, affCheck bool, reserved reservedAddresses,
+	strategy apiv2.AllocationStrategy,
 ) ([]net.IP, error) {
 	// Limit number of retries.
 	var ips []net.IP
@@ -415,7 +616,7 @@ func (c ipamClient) assignFromExistingBlock(
 		b := allocationBlock{obj.Value.(*model.AllocationBlock)}
 
 		log.Debugf("Got block: %+v", b)
-		ips, err = b.autoAssign(num, handleID, host, attrs, affCheck)
+		ips, err = b.autoAssign(num, handleID, host, attrs, affCheck, reserved, strategy)
 		if err != nil {
 			log.Errorf("Error in auto assign: %s", err)
 			return nil, err
@@ -425,9 +626,10 @@ func (c ipamClient) assignFromExistingBlock(
 			return []net.IP{}, nil
 		}
 
-		// Increment handle count.
+		// Increment handle count by the number of addresses assigned, which may be
+		// fewer than requested.
 		if handleID != nil {
-			c.incrementHandle(ctx, *handleID, blockCIDR, num)
+			c.incrementHandle(ctx, *handleID, blockCIDR, len(ips))
 		}
 
 		// Update the block using CAS by passing back the original
@@ -437,7 +639,7 @@ func (c ipamClient) assignFromExistingBlock(
 		if err != nil {
 			log.Infof("Failed to update block '%s' - try again", b.CIDR.String())
 			if handleID != nil {
-				c.decrementHandle(ctx, *handleID, blockCIDR, num)
+				c.decrementHandle(ctx, *handleID, blockCIDR, len(ips))
 			}
 			continue
 		}
@@ -452,8 +654,14 @@ func (c ipamClient) assignFromExistingBlock(
 // list of blocks that were claimed by another host.
 // If an empty string is passed as the host, then the value of os.Hostname is used.
 func (c ipamClient) ClaimAffinity(ctx context.Context, cidr net.IPNet, host string) ([]net.IPNet, []net.IPNet, error) {
+	// Look up the pool containing the requested CIDR.
+	pool, err := c.blockReaderWriter.getEnabledPoolForIP(net.IP{cidr.IP})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Validate that the given CIDR is at least as big as a block.
-	if !largerThanOrEqualToBlock(cidr) {
+	if !largerThanOrEqualToBlock(cidr, pool) {
 		estr := fmt.Sprintf("The requested CIDR (%s) is smaller than the minimum.", cidr.String())
 		return nil, nil, invalidSizeError(estr)
 	}
@@ -464,7 +672,7 @@ func (c ipamClient) ClaimAffinity(ctx context.Context, cidr net.IPNet, host stri
 	claimed := []net.IPNet{}
 
 	// Verify the requested CIDR falls within a configured pool.
-	if !c.blockReaderWriter.withinConfiguredPools(net.IP{cidr.IP}) {
+	if pool == nil {
 		estr := fmt.Sprintf("The requested CIDR (%s) is not within any configured pools.", cidr.String())
 		return nil, nil, errors.New(estr)
 	}
@@ -477,7 +685,7 @@ func (c ipamClient) ClaimAffinity(ctx context.Context, cidr net.IPNet, host stri
 	}
 
 	// Claim all blocks within the given cidr.
-	blocks := blockGenerator(cidr)
+	blocks := blockGenerator(pool, cidr)
 	for blockCIDR := blocks(); blockCIDR != nil; blockCIDR = blocks() {
 		err := c.blockReaderWriter.claimBlockAffinity(ctx, *blockCIDR, hostname, *cfg)
 		if err != nil {
@@ -501,8 +709,15 @@ func (c ipamClient) ClaimAffinity(ctx context.Context, cidr net.IPNet, host stri
 // its affinity will not be released and no error will be returned.
 // If an empty string is passed as the host, then the value of os.Hostname is used.
 func (c ipamClient) ReleaseAffinity(ctx context.Context, cidr net.IPNet, host string) error {
+	// Look up the pool containing the requested CIDR.  The pool may have been disabled,
+	// or removed, in which case the default block size is assumed.
+	pool, err := c.blockReaderWriter.getAnyPoolForIP(net.IP{cidr.IP})
+	if err != nil {
+		return err
+	}
+
 	// Validate that the given CIDR is at least as big as a block.
-	if !largerThanOrEqualToBlock(cidr) {
+	if !largerThanOrEqualToBlock(cidr, pool) {
 		estr := fmt.Sprintf("The requested CIDR (%s) is smaller than the minimum.", cidr.String())
 		return invalidSizeError(estr)
 	}
@@ -511,7 +726,7 @@ func (c ipamClient) ReleaseAffinity(ctx context.Context, cidr net.IPNet, host st
 	hostname := decideHostname(host)
 
 	// Release all blocks within the given cidr.
-	blocks := blockGenerator(cidr)
+	blocks := blockGenerator(pool, cidr)
 	for blockCIDR := blocks(); blockCIDR != nil; blockCIDR = blocks() {
 		err := c.blockReaderWriter.releaseBlockAffinity(ctx, hostname, *blockCIDR)
 		if err != nil {
@@ -541,11 +756,15 @@ func (c ipamClient) ReleaseHostAffinities(ctx context.Context, host string) erro
 			return err
 		}
 
+		// Release each block directly, rather than through ReleaseAffinity, since the block
+		// size is known from the block itself even if its pool no longer exists.
 		for _, blockCIDR := range blockCIDRs {
-			err := c.ReleaseAffinity(ctx, blockCIDR, hostname)
+			err := c.blockReaderWriter.releaseBlockAffinity(ctx, hostname, blockCIDR)
 			if err != nil {
 				if _, ok := err.(affinityClaimedError); ok {
 					// Claimed by a different host.
+				} else if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+					// Block does not exist - ignore.
 				} else {
 					return err
 				}
@@ -595,6 +814,48 @@ func (c ipamClient) ReleasePoolAffinities(ctx context.Context, pool net.IPNet) e
 	return errors.New("Max retries hit - excessive concurrent IPAM requests")
 }
 
+// ReclaimEmptyBlocks releases the affinity of all blocks that have been empty for at least
+// the configured EmptyBlockIdlePeriod, so that they may be claimed by other hosts.  The
+// idle period of a block starts when ReclaimEmptyBlocks first sees it empty, so this is
+// intended to be called periodically.  A block is only reclaimed if it has not been
+// modified since it was listed, so this is safe against concurrent assignment.
+// Returns the CIDRs of the reclaimed blocks.
+func (c ipamClient) ReclaimEmptyBlocks(ctx context.Context) ([]net.IPNet, error) {
+	cfg, err := c.GetIPAMConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EmptyBlockIdlePeriod == 0 {
+		log.Debugf("Empty block reclamation is disabled")
+		return nil, nil
+	}
+
+	objs, err := c.client.List(ctx, model.BlockListOptions{}, "")
+	if err != nil {
+		log.Errorf("Error listing IPAM blocks: %s", err)
+		return nil, err
+	}
+
+	// Attempt to reclaim each block, continuing past failures so that one bad block
+	// does not prevent the others from being reclaimed.
+	var firstErr error
+	reclaimed := []net.IPNet{}
+	now := c.clock.Now()
+	for _, kvp := range objs.KVPairs {
+		ok, err := c.blockReaderWriter.reclaimEmptyBlock(ctx, kvp, now, cfg.EmptyBlockIdlePeriod)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			reclaimed = append(reclaimed, kvp.Key.(model.BlockKey).CIDR)
+		}
+	}
+	return reclaimed, firstErr
+}
+
 // RemoveIPAMHost releases affinity for all blocks on the given host,
 // and removes all host-specific IPAM data from the datastore.
 // RemoveIPAMHost does not release any IP addresses claimed on the given host.
@@ -787,13 +1048,20 @@ func (c ipamClient) decrementHandle(ctx context.Context, handleID string, blockC
 	for i := 0; i < ipamEtcdRetries; i++ {
 		obj, err := c.client.Get(ctx, model.IPAMHandleKey{HandleID: handleID}, "")
 		if err != nil {
-			log.Fatalf("Can't decrement block because it doesn't exist")
+			if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+				// The handle may have been lost, for example when releasing leaked
+				// addresses.  There is nothing to decrement.
+				log.Warningf("Can't decrement handle '%s' because it doesn't exist", handleID)
+				return nil
+			}
+			return err
 		}
 		handle := allocationHandle{obj.Value.(*model.IPAMHandle)}
 
 		_, err = handle.decrementBlock(blockCIDR, num)
 		if err != nil {
-			log.Fatalf("Can't decrement block - too few allocated")
+			log.Errorf("Can't decrement handle '%s': %s", handleID, err)
+			return err
 		}
 
 		// Update / Delete as appropriate.  Since we have been manipulating the
@@ -819,7 +1087,10 @@ func (c ipamClient) decrementHandle(ctx context.Context, handleID string, blockC
 // GetAssignmentAttributes returns the attributes stored with the given IP address
 // upon assignment.
 func (c ipamClient) GetAssignmentAttributes(ctx context.Context, addr net.IP) (map[string]string, error) {
-	blockCIDR := getBlockCIDRForAddress(addr)
+	blockCIDR, err := c.blockReaderWriter.getBlockCIDRForAddress(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
 	obj, err := c.client.Get(ctx, model.BlockKey{blockCIDR}, "")
 	if err != nil {
 		log.Errorf("Error reading block %s: %s", blockCIDR, err)
@@ -846,8 +1117,8 @@ func (c ipamClient) GetIPAMConfig(ctx context.Context) (*IPAMConfig, error) {
 	return c.convertBackendToIPAMConfig(obj.Value.(*model.IPAMConfig)), nil
 }
 
-// SetIPAMConfig sets global IPAM configuration.  This can only
-// be done when there are no allocated blocks and IP addresses.
+// SetIPAMConfig sets global IPAM configuration.  StrictAffinity and AutoAllocateBlocks
+// can only be changed when there are no allocated blocks and IP addresses.
 func (c ipamClient) SetIPAMConfig(ctx context.Context, cfg IPAMConfig) error {
 	current, err := c.GetIPAMConfig(ctx)
 	if err != nil {
@@ -862,9 +1133,29 @@ func (c ipamClient) SetIPAMConfig(ctx context.Context, cfg IPAMConfig) error {
 		return errors.New("Cannot disable 'StrictAffinity' and 'AutoAllocateBlocks' at the same time")
 	}
 
-	allObjs, err := c.client.List(ctx, model.BlockListOptions{}, "")
-	if len(allObjs.KVPairs) != 0 {
-		return errors.New("Cannot change IPAM config while allocations exist")
+	if cfg.EmptyBlockIdlePeriod < 0 {
+		return errors.New("'EmptyBlockIdlePeriod' cannot be negative")
+	}
+
+	if cfg.MaxBlocksPerHost < 0 {
+		return errors.New("'MaxBlocksPerHost' cannot be negative")
+	}
+
+	switch cfg.AllowBorrowing {
+	case "", BorrowingAlways, BorrowingOnlyWhenPoolExhausted, BorrowingNever:
+	default:
+		return fmt.Errorf("Invalid 'AllowBorrowing' value: %s", cfg.AllowBorrowing)
+	}
+
+	if cfg.StrictAffinity != current.StrictAffinity || cfg.AutoAllocateBlocks != current.AutoAllocateBlocks {
+		allObjs, err := c.client.List(ctx, model.BlockListOptions{}, "")
+		if err != nil {
+			log.Errorf("Error listing IPAM blocks: %s", err)
+			return err
+		}
+		if len(allObjs.KVPairs) != 0 {
+			return errors.New("Cannot change IPAM config while allocations exist")
+		}
 	}
 
 	// Write to datastore.
@@ -882,15 +1173,21 @@ func (c ipamClient) SetIPAMConfig(ctx context.Context, cfg IPAMConfig) error {
 
 func (c ipamClient) convertIPAMConfigToBackend(cfg *IPAMConfig) *model.IPAMConfig {
 	return &model.IPAMConfig{
-		StrictAffinity:     cfg.StrictAffinity,
-		AutoAllocateBlocks: cfg.AutoAllocateBlocks,
+		StrictAffinity:        cfg.StrictAffinity,
+		AutoAllocateBlocks:    cfg.AutoAllocateBlocks,
+		EmptyBlockIdleSeconds: int(cfg.EmptyBlockIdlePeriod / time.Second),
+		MaxBlocksPerHost:      cfg.MaxBlocksPerHost,
+		AllowBorrowing:        string(cfg.AllowBorrowing),
 	}
 }
 
 func (c ipamClient) convertBackendToIPAMConfig(cfg *model.IPAMConfig) *IPAMConfig {
 	return &IPAMConfig{
-		StrictAffinity:     cfg.StrictAffinity,
-		AutoAllocateBlocks: cfg.AutoAllocateBlocks,
+		StrictAffinity:       cfg.StrictAffinity,
+		AutoAllocateBlocks:   cfg.AutoAllocateBlocks,
+		EmptyBlockIdlePeriod: time.Duration(cfg.EmptyBlockIdleSeconds) * time.Second,
+		MaxBlocksPerHost:     cfg.MaxBlocksPerHost,
+		AllowBorrowing:       BorrowingPolicy(cfg.AllowBorrowing),
 	}
 }
 
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// ListAllocations returns every allocation matching the given filter, ordered by IP address.
+func (c ipamClient) ListAllocations(ctx context.Context, filter AllocationFilter) ([]Allocation, error) {
+	objs, err := c.client.List(ctx, model.BlockListOptions{}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.Errorf("Error listing blocks: %s", err)
+			return nil, err
+		}
+		objs = &model.KVPairList{}
+	}
+	blocks := []allocationBlock{}
+	for _, kvp := range objs.KVPairs {
+		blocks = append(blocks, allocationBlock{kvp.Value.(*model.AllocationBlock)})
+	}
+
+	return filterAllocations(blocks, filter), nil
+}
+
+// filterAllocations returns the allocations in the given blocks that match the given filter,
+// ordered by IP address.
+func filterAllocations(blocks []allocationBlock, filter AllocationFilter) []Allocation {
+	allocs := []Allocation{}
+	for _, b := range blocks {
+		if !filter.overlapsBlock(b) {
+			log.Debugf("Block %s is not in any requested pool", b.CIDR)
+			continue
+		}
+		for o, attrIdx := range b.Allocations {
+			if attrIdx == nil {
+				continue
+			}
+			attr := b.Attributes[*attrIdx]
+			a := Allocation{
+				IP:    ordinalToIP(o, b),
+				Block: b.CIDR,
+				Host:  b.attributeHost(attr),
+				Attrs: attr.AttrSecondary,
+			}
+			if attr.AttrPrimary != nil {
+				a.HandleID = *attr.AttrPrimary
+			}
+			if filter.matches(a) {
+				allocs = append(allocs, a)
+			}
+		}
+	}
+	sort.Slice(allocs, func(i, j int) bool {
+		return ipToInt(allocs[i].IP).Cmp(ipToInt(allocs[j].IP)) < 0
+	})
+	return allocs
+}
+
+// overlapsBlock returns true if the block may contain allocations within the filter's pools.
+func (f AllocationFilter) overlapsBlock(b allocationBlock) bool {
+	if len(f.Pools) == 0 {
+		return true
+	}
+	for _, p := range f.Pools {
+		if p.Contains(b.CIDR.IP) || b.CIDR.Contains(p.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the allocation matches all of the filter's criteria.
+func (f AllocationFilter) matches(a Allocation) bool {
+	if f.Host != "" && a.Host != f.Host {
+		return false
+	}
+	for k, v := range f.Attrs {
+		if actual, ok := a.Attrs[k]; !ok || actual != v {
+			return false
+		}
+	}
+	if len(f.Pools) == 0 {
+		return true
+	}
+	for _, p := range f.Pools {
+		if p.Contains(a.IP.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Allocation filtering", func() {
+	var blocks []allocationBlock
+
+	// assign assigns the given address in the given block with the given attributes.
+	assign := func(b allocationBlock, ip string, handle string, host string, attrs map[string]string) {
+		err := b.assign(cnet.MustParseIP(ip), &handle, attrs, host)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		b1 := newBlock(cnet.MustParseCIDR("10.0.0.0/26"))
+		b2 := newBlock(cnet.MustParseCIDR("10.1.0.0/26"))
+		assign(b1, "10.0.0.5", "h1", "host-A", map[string]string{"namespace": "default", "pod": "pod-1"})
+		assign(b1, "10.0.0.2", "h2", "host-B", map[string]string{"namespace": "kube-system", "pod": "pod-2"})
+		assign(b2, "10.1.0.1", "h3", "host-A", map[string]string{"namespace": "default", "pod": "pod-3"})
+		blocks = []allocationBlock{b2, b1}
+	})
+
+	ips := func(allocs []Allocation) []string {
+		s := []string{}
+		for _, a := range allocs {
+			s = append(s, a.IP.String())
+		}
+		return s
+	}
+
+	It("should list every allocation ordered by IP when no filter is given", func() {
+		allocs := filterAllocations(blocks, AllocationFilter{})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.2", "10.0.0.5", "10.1.0.1"}))
+		Expect(allocs[1].HandleID).To(Equal("h1"))
+		Expect(allocs[1].Host).To(Equal("host-A"))
+		Expect(allocs[1].Block.String()).To(Equal("10.0.0.0/26"))
+		Expect(allocs[1].Attrs).To(Equal(map[string]string{"namespace": "default", "pod": "pod-1"}))
+	})
+
+	It("should filter by attribute", func() {
+		allocs := filterAllocations(blocks, AllocationFilter{Attrs: map[string]string{"namespace": "default"}})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.5", "10.1.0.1"}))
+		allocs = filterAllocations(blocks, AllocationFilter{Attrs: map[string]string{"namespace": "default", "pod": "pod-3"}})
+		Expect(ips(allocs)).To(Equal([]string{"10.1.0.1"}))
+		allocs = filterAllocations(blocks, AllocationFilter{Attrs: map[string]string{"node": "default"}})
+		Expect(allocs).To(BeEmpty())
+	})
+
+	It("should filter by host", func() {
+		allocs := filterAllocations(blocks, AllocationFilter{Host: "host-B"})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.2"}))
+	})
+
+	It("should treat addresses assigned without a host as assigned for the affine host", func() {
+		hostA := "host:host-A"
+		b := newBlock(cnet.MustParseCIDR("10.2.0.0/26"))
+		b.Affinity = &hostA
+		assign(b, "10.2.0.1", "h4", "", nil)
+		blocks = append(blocks, b)
+
+		allocs := filterAllocations(blocks, AllocationFilter{Host: "host-A"})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.5", "10.1.0.1", "10.2.0.1"}))
+		Expect(allocs[2].Host).To(Equal("host-A"))
+	})
+
+	It("should filter by pool", func() {
+		allocs := filterAllocations(blocks, AllocationFilter{Pools: []cnet.IPNet{cnet.MustParseNetwork("10.1.0.0/16")}})
+		Expect(ips(allocs)).To(Equal([]string{"10.1.0.1"}))
+		allocs = filterAllocations(blocks, AllocationFilter{Pools: []cnet.IPNet{cnet.MustParseNetwork("10.0.0.4/30")}})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.5"}))
+	})
+
+	It("should combine criteria", func() {
+		allocs := filterAllocations(blocks, AllocationFilter{
+			Host:  "host-A",
+			Pools: []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/16")},
+			Attrs: map[string]string{"namespace": "default"},
+		})
+		Expect(ips(allocs)).To(Equal([]string{"10.0.0.5"}))
+	})
+})
@@ -20,36 +20,35 @@ import (
 	"math/big"
 	"net"
 	"reflect"
+	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
-const (
-	blockSize = 64
-)
-
 type ipVersion struct {
-	Number            int
-	TotalBits         int
-	BlockPrefixLength int
-	BlockPrefixMask   net.IPMask
+	Number    int
+	TotalBits int
+
+	// The DefaultBlockPrefixLength is used for pools that do not specify a block size.  This
+	// is also the block size of any blocks allocated before the block size was configurable.
+	DefaultBlockPrefixLength int
 }
 
 var ipv4 ipVersion = ipVersion{
-	Number:            4,
-	TotalBits:         32,
-	BlockPrefixLength: 26,
-	BlockPrefixMask:   net.CIDRMask(26, 32),
+	Number:                   4,
+	TotalBits:                32,
+	DefaultBlockPrefixLength: 26,
 }
 
 var ipv6 ipVersion = ipVersion{
-	Number:            6,
-	TotalBits:         128,
-	BlockPrefixLength: 122,
-	BlockPrefixMask:   net.CIDRMask(122, 128),
+	Number:                   6,
+	TotalBits:                128,
+	DefaultBlockPrefixLength: 122,
 }
 
 // Wrap the backend AllocationBlock struct so that we can
@@ -59,14 +58,16 @@ type allocationBlock struct {
 }
 
 func newBlock(cidr cnet.IPNet) allocationBlock {
+	ones, size := cidr.Mask.Size()
+	numAddresses := 1 << uint(size-ones)
 	b := model.AllocationBlock{}
-	b.Allocations = make([]*int, blockSize)
-	b.Unallocated = make([]int, blockSize)
+	b.Allocations = make([]*int, numAddresses)
+	b.Unallocated = make([]int, numAddresses)
 	b.StrictAffinity = false
 	b.CIDR = cidr
 
 	// Initialize unallocated ordinals.
-	for i := 0; i < blockSize; i++ {
+	for i := 0; i < numAddresses; i++ {
 		b.Unallocated[i] = i
 	}
 
@@ -74,7 +75,8 @@ func newBlock(cidr cnet.IPNet) allocationBlock {
 }
 
 func (b *allocationBlock) autoAssign(
-	num int, handleID *string, host string, attrs map[string]string, affinityCheck bool) ([]cnet.IP, error) {
+	num int, handleID *string, host string, attrs map[string]string, affinityCheck bool, reserved reservedAddresses,
+	strategy apiv2.AllocationStrategy) ([]cnet.IP, error) {
 
 	// Determine if we need to check for affinity.
 	checkAffinity := b.StrictAffinity || affinityCheck
@@ -84,20 +86,52 @@ func (b *allocationBlock) autoAssign(
 		return nil, errors.New(s)
 	}
 
-	// Walk the allocations until we find enough addresses.
+	// The unallocated list is kept in the order in which the addresses were released, so
+	// the least recently released address is first.  Only the SequentialBlock strategy
+	// assigns the lowest free address first.
+	candidates := b.Unallocated
+	if strategy == apiv2.AllocationStrategySequentialBlock {
+		candidates = append([]int(nil), b.Unallocated...)
+		sort.Ints(candidates)
+	}
+
+	// Walk the candidates until we find enough addresses.  Reserved addresses are
+	// skipped, but remain in the unallocated list so that they may still be assigned
+	// explicitly.
+	reserved = reserved.withinBlock(b.CIDR)
 	ordinals := []int{}
-	for len(b.Unallocated) > 0 && len(ordinals) < num {
-		ordinals = append(ordinals, b.Unallocated[0])
-		b.Unallocated = b.Unallocated[1:]
+	chosen := map[int]bool{}
+	for _, o := range candidates {
+		if len(ordinals) == num {
+			break
+		}
+		if len(reserved) > 0 && reserved.contains(ordinalToIP(o, *b)) {
+			log.Debugf("Skipping reserved ordinal %d in block %s", o, b.CIDR.String())
+			continue
+		}
+		ordinals = append(ordinals, o)
+		chosen[o] = true
+	}
+
+	// Remove the chosen ordinals from the unallocated list, preserving the order of the rest.
+	unallocated := make([]int, 0, len(b.Unallocated)-len(ordinals))
+	for _, o := range b.Unallocated {
+		if !chosen[o] {
+			unallocated = append(unallocated, o)
+		}
 	}
+	b.Unallocated = unallocated
 
 	// Create slice of IPs and perform the allocations.
 	ips := []cnet.IP{}
 	for _, o := range ordinals {
-		attrIndex := b.findOrAddAttribute(handleID, attrs)
+		attrIndex := b.findOrAddAttribute(handleID, attrs, host)
 		b.Allocations[o] = &attrIndex
 		ips = append(ips, incrementIP(cnet.IP{b.CIDR.IP}, big.NewInt(int64(o))))
 	}
+	if len(ordinals) > 0 {
+		b.EmptySince = nil
+	}
 
 	log.Debugf("Block %s returned ips: %v", b.CIDR.String(), ips)
 	return ips, nil
@@ -111,7 +145,7 @@ func (b *allocationBlock) assign(address cnet.IP, handleID *string, attrs map[st
 
 	// Convert to an ordinal.
 	ordinal := ipToOrdinal(address, *b)
-	if (ordinal < 0) || (ordinal > blockSize) {
+	if (ordinal < 0) || (ordinal >= b.numAddresses()) {
 		return errors.New("IP address not in block")
 	}
 
@@ -121,8 +155,9 @@ func (b *allocationBlock) assign(address cnet.IP, handleID *string, attrs map[st
 	}
 
 	// Set up attributes.
-	attrIndex := b.findOrAddAttribute(handleID, attrs)
+	attrIndex := b.findOrAddAttribute(handleID, attrs, host)
 	b.Allocations[ordinal] = &attrIndex
+	b.EmptySince = nil
 
 	// Remove from unallocated.
 	for i, unallocated := range b.Unallocated {
@@ -143,8 +178,14 @@ func (b allocationBlock) numFreeAddresses() int {
 	return len(b.Unallocated)
 }
 
+// numAddresses returns the number of addresses in the block.  This is derived from the
+// block itself so that blocks of any size are handled.
+func (b allocationBlock) numAddresses() int {
+	return len(b.Allocations)
+}
+
 func (b allocationBlock) empty() bool {
-	return b.numFreeAddresses() == blockSize
+	return b.numFreeAddresses() == b.numAddresses()
 }
 
 func (b *allocationBlock) release(addresses []cnet.IP) ([]cnet.IP, map[string]int, error) {
@@ -162,7 +203,7 @@ func (b *allocationBlock) release(addresses []cnet.IP) ([]cnet.IP, map[string]in
 	for _, ip := range addresses {
 		// Convert to an ordinal.
 		ordinal := ipToOrdinal(ip, *b)
-		if (ordinal < 0) || (ordinal > blockSize) {
+		if (ordinal < 0) || (ordinal >= b.numAddresses()) {
 			return nil, nil, errors.New("IP address not in block")
 		}
 
@@ -217,6 +258,20 @@ func (b *allocationBlock) release(addresses []cnet.IP) ([]cnet.IP, map[string]in
 	return unallocated, countByHandle, nil
 }
 
+// assignedWithHandle returns true if the address is assigned with the given handle, or with
+// no handle if the given handle is nil.
+func (b allocationBlock) assignedWithHandle(ip cnet.IP, handleID *string) bool {
+	ordinal := ipToOrdinal(ip, b)
+	if ordinal < 0 || ordinal >= b.numAddresses() || b.Allocations[ordinal] == nil {
+		return false
+	}
+	assigned := b.Attributes[*b.Allocations[ordinal]].AttrPrimary
+	if assigned == nil || handleID == nil {
+		return assigned == nil && handleID == nil
+	}
+	return *assigned == *handleID
+}
+
 func (b *allocationBlock) deleteAttributes(delIndexes, ordinals []int) {
 	newIndexes := make([]*int, len(b.Attributes))
 	newAttrs := []model.AllocationAttribute{}
@@ -235,7 +290,7 @@ func (b *allocationBlock) deleteAttributes(delIndexes, ordinals []int) {
 	b.Attributes = newAttrs
 
 	// Update attribute indexes for all allocations in this block.
-	for i := 0; i < blockSize; i++ {
+	for i := 0; i < b.numAddresses(); i++ {
 		if b.Allocations[i] != nil {
 			// Get the new index that corresponds to the old index
 			// and update the allocation.
@@ -284,7 +339,7 @@ func (b *allocationBlock) releaseByHandle(handleID string) int {
 	// There are addresses to release.
 	ordinals := []int{}
 	var o int
-	for o = 0; o < blockSize; o++ {
+	for o = 0; o < b.numAddresses(); o++ {
 		// Only check allocated ordinals.
 		if b.Allocations[o] != nil && intInSlice(*b.Allocations[o], attrIndexes) {
 			// Release this ordinal.
@@ -307,7 +362,7 @@ func (b allocationBlock) ipsByHandle(handleID string) []cnet.IP {
 	ips := []cnet.IP{}
 	attrIndexes := b.attributeIndexesByHandle(handleID)
 	var o int
-	for o = 0; o < blockSize; o++ {
+	for o = 0; o < b.numAddresses(); o++ {
 		if b.Allocations[o] != nil && intInSlice(*b.Allocations[o], attrIndexes) {
 			ip := ordinalToIP(o, b)
 			ips = append(ips, ip)
@@ -319,7 +374,7 @@ func (b allocationBlock) ipsByHandle(handleID string) []cnet.IP {
 func (b allocationBlock) attributesForIP(ip cnet.IP) (map[string]string, error) {
 	// Convert to an ordinal.
 	ordinal := ipToOrdinal(ip, b)
-	if (ordinal < 0) || (ordinal > blockSize) {
+	if (ordinal < 0) || (ordinal >= b.numAddresses()) {
 		return nil, errors.New(fmt.Sprintf("IP %s not in block %s", ip, b.AllocationBlock.CIDR))
 	}
 
@@ -331,10 +386,14 @@ func (b allocationBlock) attributesForIP(ip cnet.IP) (map[string]string, error)
 	return b.Attributes[*attrIndex].AttrSecondary, nil
 }
 
-func (b *allocationBlock) findOrAddAttribute(handleID *string, attrs map[string]string) int {
-	attr := model.AllocationAttribute{handleID, attrs}
+func (b *allocationBlock) findOrAddAttribute(handleID *string, attrs map[string]string, host string) int {
+	attr := model.AllocationAttribute{AttrPrimary: handleID, AttrSecondary: attrs, Host: host}
 	for idx, existing := range b.Attributes {
-		if reflect.DeepEqual(attr, existing) {
+		// Attributes written by older versions of Calico do not include the host, so compare
+		// the hosts allowing for the fallback to the block affinity.
+		if reflect.DeepEqual(attr.AttrPrimary, existing.AttrPrimary) &&
+			reflect.DeepEqual(attr.AttrSecondary, existing.AttrSecondary) &&
+			b.attributeHost(attr) == b.attributeHost(existing) {
 			log.Debugf("Attribute '%+v' already exists", attr)
 			return idx
 		}
@@ -347,19 +406,43 @@ func (b *allocationBlock) findOrAddAttribute(handleID *string, attrs map[string]
 	return attrIndex
 }
 
-func getBlockCIDRForAddress(addr cnet.IP) cnet.IPNet {
-	var mask net.IPMask
-	if addr.Version() == 6 {
-		// This is an IPv6 address.
-		mask = ipv6.BlockPrefixMask
-	} else {
-		// This is an IPv4 address.
-		mask = ipv4.BlockPrefixMask
+// attributeHost returns the host that the addresses with the given attribute were assigned
+// for.  Older versions of Calico did not record the host, in which case the host with affinity
+// to the block is assumed.
+func (b allocationBlock) attributeHost(attr model.AllocationAttribute) string {
+	if attr.Host != "" || b.Affinity == nil {
+		return attr.Host
 	}
+	return strings.TrimPrefix(*b.Affinity, "host:")
+}
+
+// getBlockCIDRForAddress returns the CIDR of the block containing the given address, using
+// the block size of the given pool.  If the pool is nil, the default block size is used.
+func getBlockCIDRForAddress(addr cnet.IP, pool *apiv2.IPPool) cnet.IPNet {
+	version := getIPVersion(addr)
+	mask := net.CIDRMask(getBlockPrefixLength(pool, version), version.TotalBits)
 	masked := addr.Mask(mask)
 	return cnet.IPNet{net.IPNet{IP: masked, Mask: mask}}
 }
 
+// getAllocationStrategy returns the allocation strategy of the pool from the supplied list
+// containing the given block.  If there is no such pool, the default strategy is returned.
+func getAllocationStrategy(blockCIDR cnet.IPNet, pools []apiv2.IPPool) apiv2.AllocationStrategy {
+	if p := getPoolForIP(cnet.IP{blockCIDR.IP}, pools); p != nil && p.Spec.AllocationStrategy != "" {
+		return p.Spec.AllocationStrategy
+	}
+	return apiv2.AllocationStrategyRandomBlock
+}
+
+// getBlockPrefixLength returns the block prefix length for the given pool.  If the pool is
+// nil or does not specify a block size, the default block size is used.
+func getBlockPrefixLength(pool *apiv2.IPPool, version ipVersion) int {
+	if pool == nil || pool.Spec.BlockSize == 0 {
+		return version.DefaultBlockPrefixLength
+	}
+	return pool.Spec.BlockSize
+}
+
 func getIPVersion(ip cnet.IP) ipVersion {
 	if ip.To4() == nil {
 		return ipv6
@@ -367,10 +450,10 @@ func getIPVersion(ip cnet.IP) ipVersion {
 	return ipv4
 }
 
-func largerThanOrEqualToBlock(blockCIDR cnet.IPNet) bool {
+func largerThanOrEqualToBlock(blockCIDR cnet.IPNet, pool *apiv2.IPPool) bool {
 	ones, _ := blockCIDR.Mask.Size()
 	ipVersion := getIPVersion(cnet.IP{blockCIDR.IP})
-	return ones <= ipVersion.BlockPrefixLength
+	return ones <= getBlockPrefixLength(pool, ipVersion)
 }
 
 func intInSlice(searchInt int, slice []int) bool {
@@ -404,7 +487,7 @@ func ipToOrdinal(ip cnet.IP, b allocationBlock) int {
 	ip_int := ipToInt(ip)
 	base_int := ipToInt(cnet.IP{b.CIDR.IP})
 	ord := big.NewInt(0).Sub(ip_int, base_int).Int64()
-	if ord < 0 || ord >= blockSize {
+	if ord < 0 || ord >= int64(b.numAddresses()) {
 		// IP address not in the given block.
 		log.Fatalf("IP %s not in block %s", ip, b.CIDR)
 	}
@@ -22,9 +22,12 @@ import (
 	"math/big"
 	"math/rand"
 	"net"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
@@ -74,10 +77,14 @@ func (rw blockReaderWriter) getAffineBlocks(ctx context.Context, host string, ve
 	return ids, nil
 }
 
-func (rw blockReaderWriter) claimNewAffineBlock(ctx context.Context, host string, version ipVersion, requestedPools []cnet.IPNet, config IPAMConfig) (*cnet.IPNet, error) {
+// claimNewAffineBlock claims a free block for the given host.  Blocks in which every
+// address is reserved are not claimed, since no addresses could be assigned from them.
+func (rw blockReaderWriter) claimNewAffineBlock(
+	ctx context.Context, host string, version ipVersion, requestedPools []cnet.IPNet, config IPAMConfig, reserved reservedAddresses,
+) (*cnet.IPNet, error) {
 
 	// If requestedPools is not empty, use it.  Otherwise, default to all configured pools.
-	pools := []cnet.IPNet{}
+	pools := []apiv2.IPPool{}
 
 	// Get all the configured pools.
 	enabledPools, err := rw.pools.GetEnabledPools(version.Number)
@@ -101,7 +108,9 @@ func (rw blockReaderWriter) claimNewAffineBlock(ctx context.Context, host string
 	// Build a map so we can lookup existing pools.
 	pm := map[string]bool{}
 	for _, p := range enabledPools {
-		pm[p.String()] = true
+		if _, cidr, err := cnet.ParseCIDR(p.Spec.CIDR); err == nil {
+			pm[cidr.String()] = true
+		}
 	}
 
 	// Make sure each requested pool exists.
@@ -117,13 +126,32 @@ func (rw blockReaderWriter) claimNewAffineBlock(ctx context.Context, host string
 		return nil, errors.New("no configured Calico pools")
 	}
 
+	// Make sure the host is allowed another block.
+	if config.MaxBlocksPerHost > 0 {
+		affBlocks, err := rw.getAffineBlocks(ctx, host, version, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(affBlocks) >= config.MaxBlocksPerHost {
+			log.Infof("Host '%s' already has %d blocks - not claiming another", host, len(affBlocks))
+			return nil, maxBlocksPerHostError{Host: host, Max: config.MaxBlocksPerHost}
+		}
+	}
+
 	// Iterate through pools to find a new block.
 	log.Infof("Claiming a new affine block for host '%s'", host)
+	skippedReserved := false
 	for _, pool := range pools {
 		// Use a block generator to iterate through all of the blocks
 		// that fall within the pool.
-		blocks := randomBlockGenerator(pool, host)
+		blocks := newBlockGenerator(pool, host)
 		for subnet := blocks(); subnet != nil; subnet = blocks() {
+			if reserved.coversBlock(*subnet) {
+				log.Debugf("Skipping fully reserved block: %s", subnet.String())
+				skippedReserved = true
+				continue
+			}
+
 			// Check if a block already exists for this subnet.
 			log.Debugf("Getting block: %s", subnet.String())
 			key := model.BlockKey{CIDR: *subnet}
@@ -133,7 +161,13 @@ func (rw blockReaderWriter) claimNewAffineBlock(ctx context.Context, host string
 					// The block does not yet exist in etcd.  Try to grab it.
 					log.Debugf("Found free block: %+v", *subnet)
 					err = rw.claimBlockAffinity(ctx, *subnet, host, config)
-					return subnet, err
+					if err != nil {
+						return subnet, err
+					}
+					if err = rw.enforceMaxBlocksPerHost(ctx, host, version, *subnet, config); err != nil {
+						return nil, err
+					}
+					return subnet, nil
 				} else {
 					log.Errorf("Error getting block: %s", err)
 					return nil, err
@@ -141,19 +175,26 @@ func (rw blockReaderWriter) claimNewAffineBlock(ctx context.Context, host string
 			}
 		}
 	}
+	if skippedReserved {
+		return nil, blocksReservedError("No free blocks - the remaining free blocks are fully reserved")
+	}
 	return nil, noFreeBlocksError("No Free Blocks")
 }
 
 // isPoolInRequestedPools checks if the IP Pool that is passed in belongs to the list of IP Pools
 // that should be used for assigning IPs from.
-func isPoolInRequestedPools(pool cnet.IPNet, requestedPools []cnet.IPNet) bool {
+func isPoolInRequestedPools(pool apiv2.IPPool, requestedPools []cnet.IPNet) bool {
 	if len(requestedPools) == 0 {
 		return true
 	}
+	_, poolCIDR, err := cnet.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return false
+	}
 	// Compare the requested pools against the actual pool CIDR.  Note that we don't use deep equals
 	// because golang interchangeably seems to use 4-byte and 16-byte representations of IPv4 addresses.
 	for _, cidr := range requestedPools {
-		if pool.String() == cidr.String() {
+		if poolCIDR.String() == cidr.String() {
 			return true
 		}
 	}
@@ -222,6 +263,31 @@ func (rw blockReaderWriter) claimBlockAffinity(ctx context.Context, subnet cnet.
 	return nil
 }
 
+// enforceMaxBlocksPerHost re-checks the number of blocks affine to the host after the given
+// block has been claimed.  The check made before claiming is not atomic with the claim, so
+// concurrent claims for the same host may take it over the limit.  In that case the newly
+// claimed block is released again and a maxBlocksPerHostError is returned.  Each of the
+// concurrent claimers may release its block, so under contention the host may end up with
+// fewer blocks than the limit, but never more.
+func (rw blockReaderWriter) enforceMaxBlocksPerHost(ctx context.Context, host string, version ipVersion, subnet cnet.IPNet, config IPAMConfig) error {
+	if config.MaxBlocksPerHost <= 0 {
+		return nil
+	}
+	affBlocks, err := rw.getAffineBlocks(ctx, host, version, nil)
+	if err != nil {
+		return err
+	}
+	if len(affBlocks) <= config.MaxBlocksPerHost {
+		return nil
+	}
+	log.Infof("Host '%s' has %d blocks after claiming %s - releasing it", host, len(affBlocks), subnet)
+	if err = rw.releaseBlockAffinity(ctx, host, subnet); err != nil {
+		log.WithError(err).Errorf("Error releasing block %s claimed over the limit", subnet)
+		return err
+	}
+	return maxBlocksPerHostError{Host: host, Max: config.MaxBlocksPerHost}
+}
+
 func (rw blockReaderWriter) releaseBlockAffinity(ctx context.Context, host string, blockCIDR cnet.IPNet) error {
 	for i := 0; i < ipamEtcdRetries; i++ {
 		// Read the model.KVPair containing the block
@@ -293,32 +359,206 @@ func (rw blockReaderWriter) releaseBlockAffinity(ctx context.Context, host strin
 	return errors.New("Max retries hit")
 }
 
+// reclaimEmptyBlock releases the affinity of the given block, deleting it, if it is an
+// affine block that has been empty for at least the given idle period.  An empty block
+// that is not yet marked as empty is marked with the current time.  The block is updated
+// or deleted using the revision it was read with, so a block that has been modified since
+// is left alone.  Returns true if the block was reclaimed.
+func (rw blockReaderWriter) reclaimEmptyBlock(ctx context.Context, obj *model.KVPair, now time.Time, idlePeriod time.Duration) (bool, error) {
+	b := allocationBlock{obj.Value.(*model.AllocationBlock)}
+	if !b.empty() || b.Affinity == nil || !strings.HasPrefix(*b.Affinity, "host:") {
+		return false, nil
+	}
+	logCtx := log.WithFields(log.Fields{"block": b.CIDR.String(), "affinity": *b.Affinity})
+
+	if b.EmptySince == nil {
+		// Mark the block as empty.  The idle period starts now.
+		logCtx.Debug("Marking block as empty")
+		b.EmptySince = &now
+		if _, err := rw.client.Update(ctx, obj); err != nil {
+			if _, ok := err.(cerrors.ErrorResourceUpdateConflict); ok {
+				logCtx.Debug("Block modified while marking as empty")
+				return false, nil
+			} else if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+				return false, nil
+			}
+			logCtx.WithError(err).Error("Error marking block as empty")
+			return false, err
+		}
+		return false, nil
+	}
+
+	if now.Sub(*b.EmptySince) < idlePeriod {
+		logCtx.Debugf("Block has been empty since %s", b.EmptySince)
+		return false, nil
+	}
+
+	// The block has been idle for long enough.
+	logCtx.Infof("Reclaiming block that has been empty since %s", b.EmptySince)
+	return rw.deleteEmptyBlock(ctx, obj)
+}
+
+// deleteEmptyBlock deletes the given empty block and removes its host affinity, if any.
+// The block is only deleted if it has not been modified since it was read, so that
+// addresses assigned concurrently are not lost.  Returns true if the block was deleted.
+//
+// The affinity is removed before the block is deleted.  Otherwise, if the host claimed the
+// block again between the two deletes, the claim would be left without its affinity.  If
+// the block is then not deleted because it has been modified, the affinity is restored.
+func (rw blockReaderWriter) deleteEmptyBlock(ctx context.Context, obj *model.KVPair) (bool, error) {
+	b := allocationBlock{obj.Value.(*model.AllocationBlock)}
+	logCtx := log.WithField("block", b.CIDR.String())
+
+	// Remove the host's affinity to the block.
+	host := ""
+	if b.Affinity != nil && strings.HasPrefix(*b.Affinity, "host:") {
+		host = strings.TrimPrefix(*b.Affinity, "host:")
+		_, err := rw.client.Delete(ctx, model.BlockAffinityKey{Host: host, CIDR: b.CIDR}, "")
+		if err != nil {
+			// Return the error unless the affinity didn't exist.
+			if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+				logCtx.WithError(err).Error("Error deleting block affinity")
+				return false, err
+			}
+		}
+	}
+
+	if _, err := rw.client.Delete(ctx, obj.Key, obj.Revision); err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+			return false, nil
+		}
+		if _, ok := err.(cerrors.ErrorResourceUpdateConflict); ok {
+			logCtx.Info("Block modified while deleting - skipping")
+			err = nil
+		} else {
+			logCtx.WithError(err).Error("Error deleting block")
+		}
+		if host != "" {
+			if restoreErr := rw.restoreBlockAffinity(ctx, host, b.CIDR); restoreErr != nil && err == nil {
+				err = restoreErr
+			}
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreBlockAffinity recreates the host's affinity to the block, if the block still
+// exists and is affine to the host.
+func (rw blockReaderWriter) restoreBlockAffinity(ctx context.Context, host string, blockCIDR cnet.IPNet) error {
+	logCtx := log.WithFields(log.Fields{"block": blockCIDR.String(), "host": host})
+	obj, err := rw.client.Get(ctx, model.BlockKey{CIDR: blockCIDR}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+			return nil
+		}
+		logCtx.WithError(err).Error("Error reading block to restore affinity")
+		return err
+	}
+	b := obj.Value.(*model.AllocationBlock)
+	if b.Affinity == nil || !hostAffinityMatches(host, b) {
+		return nil
+	}
+	logCtx.Info("Restoring block affinity")
+	_, err = rw.client.Create(ctx, &model.KVPair{
+		Key:   model.BlockAffinityKey{Host: host, CIDR: blockCIDR},
+		Value: model.BlockAffinityValue,
+	})
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceAlreadyExists); !ok {
+			logCtx.WithError(err).Error("Error restoring block affinity")
+			return err
+		}
+	}
+	return nil
+}
+
 // withinConfiguredPools returns true if the given IP is within a configured
 // Calico pool, and false otherwise.
 func (rw blockReaderWriter) withinConfiguredPools(ip cnet.IP) bool {
-	enabledPools, _ := rw.pools.GetEnabledPools(ip.Version())
-	for _, p := range enabledPools {
-		// Compare any enabled pools.
-		if p.Contains(ip.IP) {
-			return true
+	pool, _ := rw.getEnabledPoolForIP(ip)
+	return pool != nil
+}
+
+// getEnabledPoolForIP returns the enabled pool containing the given IP, or nil if the IP is
+// not within an enabled pool.
+func (rw blockReaderWriter) getEnabledPoolForIP(ip cnet.IP) (*apiv2.IPPool, error) {
+	enabledPools, err := rw.pools.GetEnabledPools(ip.Version())
+	if err != nil {
+		return nil, err
+	}
+	return getPoolForIP(ip, enabledPools), nil
+}
+
+// getAnyPoolForIP returns the pool containing the given IP, or nil if the IP is not within
+// a pool.  Unlike getEnabledPoolForIP, this includes disabled pools.
+func (rw blockReaderWriter) getAnyPoolForIP(ip cnet.IP) (*apiv2.IPPool, error) {
+	allPools, err := rw.pools.GetAllPools()
+	if err != nil {
+		return nil, err
+	}
+	return getPoolForIP(ip, allPools), nil
+}
+
+// getBlockCIDRForAddress returns the CIDR of the block containing the given IP, using the
+// block size of the pool containing the IP.  If the IP is no longer within a pool, then the
+// block size is unknown, so the CIDR is taken from the existing block containing the IP.  If
+// there is no such block either, the default block size is used.
+func (rw blockReaderWriter) getBlockCIDRForAddress(ctx context.Context, ip cnet.IP) (cnet.IPNet, error) {
+	pool, err := rw.getAnyPoolForIP(ip)
+	if err != nil {
+		return cnet.IPNet{}, err
+	}
+	if pool != nil {
+		return getBlockCIDRForAddress(ip, pool), nil
+	}
+
+	log.Debugf("No pool contains IP %s, looking for an existing block", ip)
+	objs, err := rw.client.List(ctx, model.BlockListOptions{IPVersion: ip.Version()}, "")
+	if err != nil {
+		return cnet.IPNet{}, err
+	}
+	for _, o := range objs.KVPairs {
+		blockCIDR := o.Key.(model.BlockKey).CIDR
+		if blockCIDR.Contains(ip.IP) {
+			return blockCIDR, nil
 		}
 	}
-	return false
+	return getBlockCIDRForAddress(ip, nil), nil
+}
+
+// getPoolForIP returns the pool from the supplied list containing the given IP, or nil if
+// there is none.
+func getPoolForIP(ip cnet.IP, pools []apiv2.IPPool) *apiv2.IPPool {
+	for _, p := range pools {
+		_, cidr, err := cnet.ParseCIDR(p.Spec.CIDR)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to parse the IPPool CIDR: %s", p.Spec.CIDR)
+			continue
+		}
+		if cidr.Version() == ip.Version() && cidr.Contains(ip.IP) {
+			return &p
+		}
+	}
+	return nil
 }
 
 // Generator to get list of block CIDRs which
-// fall within the given pool. Returns nil when no more
+// fall within the given CIDR, using the block size of
+// the given pool. Returns nil when no more
 // blocks can be generated.
-func blockGenerator(pool cnet.IPNet) func() *cnet.IPNet {
-	// Determine the IP type to use.
-	version := getIPVersion(cnet.IP{pool.IP})
-	ip := cnet.IP{pool.IP}
+func blockGenerator(pool *apiv2.IPPool, cidr cnet.IPNet) func() *cnet.IPNet {
+	// Determine the IP type and block size to use.
+	version := getIPVersion(cnet.IP{cidr.IP})
+	blockMask := net.CIDRMask(getBlockPrefixLength(pool, version), version.TotalBits)
+	blockSize := blockNumAddresses(blockMask)
+	ip := cnet.IP{cidr.IP}
 	return func() *cnet.IPNet {
 		returnIP := ip
-		if pool.Contains(ip.IP) {
-			ipnet := net.IPNet{returnIP.IP, version.BlockPrefixMask}
+		if cidr.Contains(ip.IP) {
+			ipnet := net.IPNet{returnIP.IP, blockMask}
 			cidr := cnet.IPNet{ipnet}
-			ip = incrementIP(ip, big.NewInt(blockSize))
+			ip = incrementIP(ip, blockSize)
 			return &cidr
 		} else {
 			return nil
@@ -326,13 +566,40 @@ func blockGenerator(pool cnet.IPNet) func() *cnet.IPNet {
 	}
 }
 
+// Returns a generator that, when called, returns the next block from the
+// given pool in the order given by the pool's allocation strategy.  When there
+// are no blocks left, it returns nil.
+func newBlockGenerator(ipPool apiv2.IPPool, hostName string) func() *cnet.IPNet {
+	if ipPool.Spec.AllocationStrategy != apiv2.AllocationStrategySequentialBlock {
+		return randomBlockGenerator(ipPool, hostName)
+	}
+	_, pool, err := cnet.ParseCIDR(ipPool.Spec.CIDR)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to parse the IPPool CIDR: %s", ipPool.Spec.CIDR)
+		return func() *cnet.IPNet { return nil }
+	}
+	if !largerThanOrEqualToBlock(*pool, &ipPool) {
+		// The pool is smaller than a single block.
+		log.Warningf("IPPool %s is smaller than the block size", ipPool.Spec.CIDR)
+		return func() *cnet.IPNet { return nil }
+	}
+	return blockGenerator(&ipPool, *pool)
+}
+
 // Returns a generator that, when called, returns a random
 // block from the given pool.  When there are no blocks left,
 // the it returns nil.
-func randomBlockGenerator(pool cnet.IPNet, hostName string) func() *cnet.IPNet {
+func randomBlockGenerator(ipPool apiv2.IPPool, hostName string) func() *cnet.IPNet {
+	_, pool, err := cnet.ParseCIDR(ipPool.Spec.CIDR)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to parse the IPPool CIDR: %s", ipPool.Spec.CIDR)
+		return func() *cnet.IPNet { return nil }
+	}
 
-	// Determine the IP type to use.
+	// Determine the IP type and block size to use.
 	version := getIPVersion(cnet.IP{pool.IP})
+	blockMask := net.CIDRMask(getBlockPrefixLength(&ipPool, version), version.TotalBits)
+	blockSize := blockNumAddresses(blockMask)
 	baseIP := cnet.IP{pool.IP}
 
 	// Determine the number of blocks within this pool.
@@ -340,7 +607,12 @@ func randomBlockGenerator(pool cnet.IPNet, hostName string) func() *cnet.IPNet {
 	prefixLen := size - ones
 	numIP := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(prefixLen)), nil)
 	numBlocks := new(big.Int)
-	numBlocks.Div(numIP, big.NewInt(blockSize))
+	numBlocks.Div(numIP, blockSize)
+	if numBlocks.Sign() == 0 {
+		// The pool is smaller than a single block.
+		log.Warningf("IPPool %s is smaller than the block size", ipPool.Spec.CIDR)
+		return func() *cnet.IPNet { return nil }
+	}
 
 	// Create a random number generator seed based on the hostname.
 	// This is to avoid assigning multiple blocks when multiple
@@ -366,8 +638,8 @@ func randomBlockGenerator(pool cnet.IPNet, hostName string) func() *cnet.IPNet {
 	return func() *cnet.IPNet {
 		// The `big.NewInt(0)` part creates a temp variable and assigns the result of multiplication of `i` and `big.NewInt(blockSize)`
 		// Note: we are not using `i.Mul()` because that will assign the result of the multiplication to `i`, which will cause unexpected issues
-		ip := incrementIP(baseIP, big.NewInt(0).Mul(i, big.NewInt(blockSize)))
-		ipnet := net.IPNet{ip.IP, version.BlockPrefixMask}
+		ip := incrementIP(baseIP, big.NewInt(0).Mul(i, blockSize))
+		ipnet := net.IPNet{ip.IP, blockMask}
 
 		numDiff.Sub(numBlocks, i)
 
@@ -392,3 +664,9 @@ func randomBlockGenerator(pool cnet.IPNet, hostName string) func() *cnet.IPNet {
 		return &cnet.IPNet{ipnet}
 	}
 }
+
+// blockNumAddresses returns the number of addresses in a block with the given mask.
+func blockNumAddresses(mask net.IPMask) *big.Int {
+	ones, size := mask.Size()
+	return new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(size-ones)), nil)
+}
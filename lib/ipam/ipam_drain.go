@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// DrainPool deletes the empty blocks in the given pool, releasing their affinities, and
+// reports the allocations that remain in the pool.  Blocks are deleted using the revision
+// they were read with, so a block that is assigned from concurrently is left alone.  The
+// pool, and any other pool that overlaps the given CIDR, must be disabled so that no new
+// blocks are claimed from it, unless it has already been deleted.  DrainPool continues past failures to release a block, and returns the
+// report along with the first error encountered.
+func (c ipamClient) DrainPool(ctx context.Context, cidr cnet.IPNet) (*DrainPoolReport, error) {
+	allPools, err := c.pools.GetAllPools()
+	if err != nil {
+		log.Errorf("Error reading configured pools: %s", err)
+		return nil, err
+	}
+	for _, p := range allPools {
+		_, poolCIDR, err := cnet.ParseCIDR(p.Spec.CIDR)
+		if err != nil || p.Spec.Disabled || !poolCIDR.IsNetOverlap(cidr.IPNet) {
+			continue
+		}
+		if poolCIDR.String() == cidr.String() {
+			return nil, fmt.Errorf("IPPool %s must be disabled before it can be drained", cidr.String())
+		}
+		return nil, fmt.Errorf("IPPool %s overlaps %s and must be disabled before it can be drained", poolCIDR.String(), cidr.String())
+	}
+
+	objs, err := c.client.List(ctx, model.BlockListOptions{IPVersion: cidr.Version()}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.Errorf("Error listing blocks: %s", err)
+			return nil, err
+		}
+		objs = &model.KVPairList{}
+	}
+
+	report := &DrainPoolReport{
+		CIDR:                cidr,
+		AllocationsByHost:   map[string]int{},
+		AllocationsByHandle: map[string]int{},
+		ReleasedBlocks:      []cnet.IPNet{},
+	}
+	var firstErr error
+	blocks := []allocationBlock{}
+	for _, kvp := range objs.KVPairs {
+		b := allocationBlock{kvp.Value.(*model.AllocationBlock)}
+		if !cidr.Contains(b.CIDR.IP) {
+			continue
+		}
+		if !b.empty() {
+			blocks = append(blocks, b)
+			continue
+		}
+		deleted, err := c.blockReaderWriter.deleteEmptyBlock(ctx, kvp)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if deleted {
+			log.Infof("Released empty block %s in pool %s", b.CIDR.String(), cidr.String())
+			report.ReleasedBlocks = append(report.ReleasedBlocks, b.CIDR)
+		}
+	}
+
+	report.Allocations = filterAllocations(blocks, AllocationFilter{Pools: []cnet.IPNet{cidr}})
+	for _, a := range report.Allocations {
+		report.AllocationsByHost[a.Host]++
+		report.AllocationsByHandle[a.HandleID]++
+	}
+	log.Infof("%d allocations remain in pool %s", len(report.Allocations), cidr.String())
+	return report, firstErr
+}
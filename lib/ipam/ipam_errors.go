@@ -16,6 +16,8 @@ package ipam
 
 import (
 	"fmt"
+
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
 // invalidSizeError indicates that the requested IP network size is not valid.
@@ -41,6 +43,25 @@ func (e noFreeBlocksError) Error() string {
 	return string(e)
 }
 
+// blocksReservedError indicates an attempt to claim a block when the only
+// free blocks are those in which every address is reserved.
+type blocksReservedError string
+
+func (e blocksReservedError) Error() string {
+	return string(e)
+}
+
+// maxBlocksPerHostError indicates an attempt to claim a block for a host
+// that already has the maximum number of blocks allowed by the IPAM configuration.
+type maxBlocksPerHostError struct {
+	Host string
+	Max  int
+}
+
+func (e maxBlocksPerHostError) Error() string {
+	return fmt.Sprintf("Host '%s' has reached the maximum of %d blocks per host", e.Host, e.Max)
+}
+
 // affinityClaimedError indicates that a given block has already
 // been claimed by another host.
 type affinityClaimedError struct {
@@ -50,3 +71,12 @@ type affinityClaimedError struct {
 func (e affinityClaimedError) Error() string {
 	return fmt.Sprintf("%s already claimed by %s", e.Block.CIDR, e.Block.Affinity)
 }
+
+// reservedAddressError indicates an attempt to assign an address that is reserved.
+type reservedAddressError struct {
+	IP cnet.IP
+}
+
+func (e reservedAddressError) Error() string {
+	return fmt.Sprintf("The provided IP address (%s) is reserved", e.IP)
+}
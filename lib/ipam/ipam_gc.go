@@ -0,0 +1,450 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+const (
+	// Allocation attributes set by Calico components that identify the owner of an address.
+	AttributePod       = "pod"
+	AttributeNamespace = "namespace"
+	AttributeNode      = "node"
+	AttributeType      = "type"
+
+	// Values of AttributeType for node tunnel addresses.
+	AttributeTypeIPIP  = "ipipTunnelAddress"
+	AttributeTypeVXLAN = "vxlanTunnelAddress"
+)
+
+// DefaultGarbageCollectorHandlePrefixes are the prefixes of the handles that Calico uses
+// when assigning workload and tunnel addresses.
+var DefaultGarbageCollectorHandlePrefixes = []string{"k8s-pod-network.", "ipip-tunnel-addr-", "vxlan-tunnel-addr-"}
+
+// Interface used by the garbage collector to access the nodes and workload endpoints.
+type WorkloadAccessorInterface interface {
+	// Returns all of the Nodes.
+	ListNodes(ctx context.Context) ([]apiv2.Node, error)
+	// Returns all of the WorkloadEndpoints in all namespaces.
+	ListWorkloadEndpoints(ctx context.Context) ([]apiv2.WorkloadEndpoint, error)
+}
+
+// GarbageCollectorOptions contains the options for the IPAM garbage collector.
+type GarbageCollectorOptions struct {
+	// GracePeriod is the length of time that an allocation, or a handle with no allocations,
+	// must have been continuously detected as leaked before it is reported and released.
+	// This allows time for the workload endpoint to be created after its addresses are
+	// assigned.
+	GracePeriod time.Duration
+
+	// If DryRun is true, leaked addresses and empty handles are reported but not released.
+	DryRun bool
+
+	// HandlePrefixes are the prefixes of the handles that identify workload and tunnel
+	// addresses.  If not specified, DefaultGarbageCollectorHandlePrefixes is used.
+	HandlePrefixes []string
+}
+
+// LeakedIP describes an assigned IP address that is not in use by any workload endpoint
+// or node.
+type LeakedIP struct {
+	// The leaked IP address.
+	IP cnet.IP
+
+	// The handle used to assign the address, if any.
+	HandleID *string
+
+	// The attributes stored with the address upon assignment.
+	Attrs map[string]string
+
+	// The host that has affinity to the block containing the address, if any.
+	Host string
+
+	// The time at which the address was first detected as leaked.
+	LeakedSince time.Time
+}
+
+// GarbageCollectionReport contains the results of a garbage collection.  Only handles with
+// one of the configured prefixes are reported and deleted when empty.
+type GarbageCollectionReport struct {
+	// The addresses that have been leaked for at least the grace period.
+	LeakedIPs []LeakedIP
+
+	// The leaked addresses that were released.  This is empty in dry-run mode.
+	ReleasedIPs []cnet.IP
+
+	// The handles that have had no allocations for at least the grace period.
+	EmptyHandles []string
+
+	// The empty handles that were deleted.  This is empty in dry-run mode.
+	DeletedHandles []string
+}
+
+// GarbageCollector detects IP addresses that remain assigned after the workload they were
+// assigned to has gone, for example because the CNI plugin was not called to release the
+// address when the workload was deleted, and releases them.
+//
+// Only addresses that were assigned for a workload or a node tunnel are considered: those
+// whose handle has one of the configured prefixes, or whose attributes include AttributePod
+// or a tunnel AttributeType.  Addresses assigned by other tooling, for example for load
+// balancers or hosts, are never collected.
+//
+// Such an address is in use if it is one of the IPNetworks or NAT external IPs of a
+// WorkloadEndpoint on an existing Node, or if it is the IPIP or VXLAN tunnel address of an
+// existing Node.  Otherwise it is leaked.
+//
+// The garbage collector tracks when each leak was first detected, so the same collector
+// should be used for each collection.
+type GarbageCollector struct {
+	client    bapi.Client
+	workloads WorkloadAccessorInterface
+	ipam      *ipamClient
+	opts      GarbageCollectorOptions
+	clock     clock.Clock
+
+	// The time at which each leaked address (keyed by IP and handle, see leakKey) and each
+	// empty handle (keyed by handle ID) was first detected.
+	leakedIPs    map[string]time.Time
+	emptyHandles map[string]time.Time
+}
+
+// NewGarbageCollector creates a new GarbageCollector.  Consumers of the Calico API should
+// not create this directly, but should use the main client (e.g. clientv2.IPAMGarbageCollector()).
+func NewGarbageCollector(
+	client bapi.Client, pools PoolAccessorInterface, workloads WorkloadAccessorInterface, opts GarbageCollectorOptions,
+) *GarbageCollector {
+	return NewGarbageCollectorWithClock(client, pools, workloads, opts, clock.RealClock{})
+}
+
+// NewGarbageCollectorWithClock creates a new GarbageCollector that uses the supplied clock to
+// determine whether the grace period has passed.
+func NewGarbageCollectorWithClock(
+	client bapi.Client, pools PoolAccessorInterface, workloads WorkloadAccessorInterface, opts GarbageCollectorOptions, clk clock.Clock,
+) *GarbageCollector {
+	if opts.HandlePrefixes == nil {
+		opts.HandlePrefixes = DefaultGarbageCollectorHandlePrefixes
+	}
+	return &GarbageCollector{
+		client:       client,
+		workloads:    workloads,
+		ipam:         NewIPAMClient(client, pools, nil).(*ipamClient),
+		opts:         opts,
+		clock:        clk,
+		leakedIPs:    map[string]time.Time{},
+		emptyHandles: map[string]time.Time{},
+	}
+}
+
+// Collect performs a single garbage collection.  It reports the addresses and handles that
+// have been leaked for at least the grace period and, unless in dry-run mode, releases them.
+// The collection continues if an individual address or handle cannot be released, and
+// returns the first error encountered along with the report.
+func (gc *GarbageCollector) Collect(ctx context.Context) (*GarbageCollectionReport, error) {
+	now := gc.clock.Now()
+	report := &GarbageCollectionReport{}
+
+	inUse, err := gc.getIPsInUse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the leaked addresses, and count the allocations for each handle.
+	blocks, err := gc.client.List(ctx, model.BlockListOptions{}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.WithError(err).Error("Error listing IPAM blocks")
+			return nil, err
+		}
+		blocks = &model.KVPairList{}
+	}
+	allocsByHandle := map[string]int{}
+	leakedIPs := map[string]time.Time{}
+	for _, kvp := range blocks.KVPairs {
+		b := allocationBlock{kvp.Value.(*model.AllocationBlock)}
+		host := ""
+		if b.Affinity != nil {
+			host = strings.TrimPrefix(*b.Affinity, "host:")
+		}
+		for o, attrIdx := range b.Allocations {
+			if attrIdx == nil {
+				continue
+			}
+			attr := b.Attributes[*attrIdx]
+			if attr.AttrPrimary != nil {
+				allocsByHandle[*attr.AttrPrimary]++
+			}
+			ip := ordinalToIP(o, b)
+			if !gc.isCandidate(attr) {
+				log.Debugf("IP address %s is not owned by a workload or tunnel", ip)
+				continue
+			}
+			if inUse[ip.String()] {
+				continue
+			}
+
+			key := leakKey(ip, attr.AttrPrimary)
+			since, ok := gc.leakedIPs[key]
+			if !ok {
+				log.Infof("Detected leaked IP address %s", ip)
+				since = now
+			}
+			leakedIPs[key] = since
+			if now.Sub(since) >= gc.opts.GracePeriod {
+				report.LeakedIPs = append(report.LeakedIPs, LeakedIP{
+					IP:          ip,
+					HandleID:    attr.AttrPrimary,
+					Attrs:       attr.AttrSecondary,
+					Host:        host,
+					LeakedSince: since,
+				})
+			}
+		}
+	}
+	gc.leakedIPs = leakedIPs
+
+	// Find the handles that have no allocations.
+	handles, err := gc.client.List(ctx, model.IPAMHandleListOptions{}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.WithError(err).Error("Error listing IPAM handles")
+			return nil, err
+		}
+		handles = &model.KVPairList{}
+	}
+	emptyHandles := map[string]time.Time{}
+	emptyHandleKVPs := []*model.KVPair{}
+	for _, kvp := range handles.KVPairs {
+		handleID := kvp.Key.(model.IPAMHandleKey).HandleID
+		if allocsByHandle[handleID] > 0 || !gc.isCandidate(model.AllocationAttribute{AttrPrimary: &handleID}) {
+			continue
+		}
+		since, ok := gc.emptyHandles[handleID]
+		if !ok {
+			log.Infof("Detected IPAM handle %s with no allocations", handleID)
+			since = now
+		}
+		emptyHandles[handleID] = since
+		if now.Sub(since) >= gc.opts.GracePeriod {
+			report.EmptyHandles = append(report.EmptyHandles, handleID)
+			emptyHandleKVPs = append(emptyHandleKVPs, kvp)
+		}
+	}
+	gc.emptyHandles = emptyHandles
+
+	for _, leaked := range report.LeakedIPs {
+		log.WithFields(log.Fields{
+			"ip":          leaked.IP,
+			"handle":      leaked.HandleID,
+			"host":        leaked.Host,
+			"leakedSince": leaked.LeakedSince,
+		}).Warning("IP address has leaked")
+	}
+	if gc.opts.DryRun {
+		log.Infof("Dry-run: not releasing %d leaked IPs and %d empty handles", len(report.LeakedIPs), len(report.EmptyHandles))
+		return report, nil
+	}
+
+	var firstErr error
+	recordErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Release the leaked addresses.  If every address assigned with a handle has leaked, then
+	// release by handle so that the handle is tidied up too, otherwise release the individual
+	// addresses.  The individual addresses are only released if they are still assigned with
+	// the same handle, so that an address that has been reassigned since it was detected as
+	// leaked is not released.
+	leakedByHandle := map[string][]LeakedIP{}
+	toRelease := []LeakedIP{}
+	for _, leaked := range report.LeakedIPs {
+		if leaked.HandleID == nil {
+			toRelease = append(toRelease, leaked)
+			continue
+		}
+		leakedByHandle[*leaked.HandleID] = append(leakedByHandle[*leaked.HandleID], leaked)
+	}
+	for handleID, leaked := range leakedByHandle {
+		if len(leaked) != allocsByHandle[handleID] {
+			toRelease = append(toRelease, leaked...)
+			continue
+		}
+		log.Infof("Releasing leaked IPs with handle %s", handleID)
+		if err := gc.ipam.ReleaseByHandle(ctx, handleID); err != nil {
+			if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+				log.WithError(err).Errorf("Failed to release leaked IPs with handle %s", handleID)
+				recordErr(err)
+				continue
+			}
+			// The handle itself has been lost, so release the addresses individually.
+			toRelease = append(toRelease, leaked...)
+			continue
+		}
+		gc.released(report, leaked)
+	}
+	if len(toRelease) > 0 {
+		ips := []cnet.IP{}
+		handles := map[string]*string{}
+		for _, leaked := range toRelease {
+			ips = append(ips, leaked.IP)
+			handles[leaked.IP.String()] = leaked.HandleID
+		}
+		log.Infof("Releasing leaked IPs: %v", ips)
+		notReleased, err := gc.ipam.releaseIPs(ctx, ips, handles)
+		if err != nil {
+			log.WithError(err).Error("Failed to release leaked IPs")
+			recordErr(err)
+		} else {
+			released := []LeakedIP{}
+			for _, leaked := range toRelease {
+				if !ipInSlice(leaked.IP, notReleased) {
+					released = append(released, leaked)
+				}
+			}
+			gc.released(report, released)
+		}
+	}
+
+	// Delete the empty handles.  The revision ensures that a handle is not deleted if it
+	// has been updated by a new assignment since it was read.
+	for _, kvp := range emptyHandleKVPs {
+		handleID := kvp.Key.(model.IPAMHandleKey).HandleID
+		log.Infof("Deleting IPAM handle %s with no allocations", handleID)
+		if _, err := gc.client.Delete(ctx, kvp.Key, kvp.Revision); err != nil {
+			switch err.(type) {
+			case cerrors.ErrorResourceDoesNotExist, cerrors.ErrorResourceUpdateConflict:
+				log.Infof("IPAM handle %s has been modified - not deleting", handleID)
+			default:
+				log.WithError(err).Errorf("Failed to delete IPAM handle %s", handleID)
+				recordErr(err)
+			}
+			continue
+		}
+		delete(gc.emptyHandles, handleID)
+		report.DeletedHandles = append(report.DeletedHandles, handleID)
+	}
+
+	return report, firstErr
+}
+
+// released records that the given leaked addresses have been released.
+func (gc *GarbageCollector) released(report *GarbageCollectionReport, leaked []LeakedIP) {
+	for _, l := range leaked {
+		delete(gc.leakedIPs, leakKey(l.IP, l.HandleID))
+		report.ReleasedIPs = append(report.ReleasedIPs, l.IP)
+	}
+}
+
+// leakKey returns the key used to track a leaked address.  This includes the handle so that
+// an address that is released and assigned again with a different handle is detected as a
+// new leak, rather than inheriting the time at which the old assignment leaked.
+func leakKey(ip cnet.IP, handleID *string) string {
+	if handleID == nil {
+		return ip.String()
+	}
+	return ip.String() + "/" + *handleID
+}
+
+// isCandidate returns true if the allocation with the given attributes was made for a
+// workload or a node tunnel, and so may be collected.
+func (gc *GarbageCollector) isCandidate(attr model.AllocationAttribute) bool {
+	if attr.AttrPrimary != nil {
+		for _, prefix := range gc.opts.HandlePrefixes {
+			if strings.HasPrefix(*attr.AttrPrimary, prefix) {
+				return true
+			}
+		}
+	}
+	if _, ok := attr.AttrSecondary[AttributePod]; ok {
+		return true
+	}
+	switch attr.AttrSecondary[AttributeType] {
+	case AttributeTypeIPIP, AttributeTypeVXLAN:
+		return true
+	}
+	return false
+}
+
+// getIPsInUse returns the set of addresses that are in use by the workload endpoints on
+// existing nodes, and by the tunnels of existing nodes.
+func (gc *GarbageCollector) getIPsInUse(ctx context.Context) (map[string]bool, error) {
+	inUse := map[string]bool{}
+	addIP := func(s string) {
+		if s == "" {
+			return
+		}
+		ip, _, err := cnet.ParseCIDROrIP(s)
+		if err != nil {
+			log.Warnf("Failed to parse IP address %s. Ignoring", s)
+			return
+		}
+		inUse[ip.String()] = true
+	}
+
+	nodes, err := gc.workloads.ListNodes(ctx)
+	if err != nil {
+		log.WithError(err).Error("Error listing nodes")
+		return nil, err
+	}
+	nodeNames := map[string]bool{}
+	for _, node := range nodes {
+		nodeNames[node.Name] = true
+		if node.Spec.BGP != nil {
+			addIP(node.Spec.BGP.IPv4IPIPTunnelAddr)
+		}
+		addIP(node.Spec.IPv4VXLANTunnelAddr)
+	}
+
+	weps, err := gc.workloads.ListWorkloadEndpoints(ctx)
+	if err != nil {
+		log.WithError(err).Error("Error listing workload endpoints")
+		return nil, err
+	}
+	for _, wep := range weps {
+		if !nodeNames[wep.Spec.Node] {
+			log.Debugf("Workload endpoint %s/%s is on a node that does not exist", wep.Namespace, wep.Name)
+			continue
+		}
+		for _, n := range wep.Spec.IPNetworks {
+			addIP(n)
+		}
+		for _, nat := range wep.Spec.IPNATs {
+			addIP(nat.ExternalIP)
+		}
+	}
+	return inUse, nil
+}
+
+func ipInSlice(ip cnet.IP, ips []cnet.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+// Implement a workload accessor for the garbage collector that reads the nodes and workload
+// endpoints directly from the backend, rather than requiring the main client.
+type workloadAccessor struct {
+	client bapi.Client
+}
+
+func (w workloadAccessor) ListNodes(ctx context.Context) ([]apiv2.Node, error) {
+	kvps, err := w.client.List(ctx, model.ResourceListOptions{Kind: apiv2.KindNode}, "")
+	if err != nil {
+		return nil, err
+	}
+	nodes := []apiv2.Node{}
+	for _, kvp := range kvps.KVPairs {
+		nodes = append(nodes, *kvp.Value.(*apiv2.Node))
+	}
+	return nodes, nil
+}
+
+func (w workloadAccessor) ListWorkloadEndpoints(ctx context.Context) ([]apiv2.WorkloadEndpoint, error) {
+	kvps, err := w.client.List(ctx, model.ResourceListOptions{Kind: apiv2.KindWorkloadEndpoint}, "")
+	if err != nil {
+		return nil, err
+	}
+	weps := []apiv2.WorkloadEndpoint{}
+	for _, kvp := range kvps.KVPairs {
+		weps = append(weps, *kvp.Value.(*apiv2.WorkloadEndpoint))
+	}
+	return weps, nil
+}
+
+var _ = testutils.E2eDatastoreDescribe("IPAM garbage collector tests", testutils.DatastoreEtcdV3, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	gracePeriod := 5 * time.Minute
+	bc, err := backend.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+	ic := NewIPAMClient(bc, ipPools, nil)
+	workloads := workloadAccessor{client: bc}
+
+	handleInUse := "k8s-pod-network.in-use"
+	handleLeaked := "k8s-pod-network.leaked"
+	handleGoneNode := "k8s-pod-network.gone-node"
+	handleEmpty := "k8s-pod-network.empty"
+	handleTunnel := "ipip-tunnel-addr-node-1"
+	handleForeign := "load-balancer-1"
+	var ipInUse, ipGoneNode cnet.IP
+	var ipsLeaked []cnet.IP
+	tunnelIP := cnet.MustParseIP("10.0.0.100")
+	unhandledIP := cnet.MustParseIP("10.0.0.101")
+	foreignIP := cnet.MustParseIP("10.0.0.102")
+	unownedIP := cnet.MustParseIP("10.0.0.103")
+
+	createResource := func(res interface{}, key model.Key) {
+		_, err := bc.Create(ctx, &model.KVPair{Key: key, Value: res})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	createWorkloadEndpoint := func(name, node string, ip cnet.IP) {
+		wep := apiv2.NewWorkloadEndpoint()
+		wep.ObjectMeta = metav1.ObjectMeta{Name: name, Namespace: "default"}
+		wep.Spec = apiv2.WorkloadEndpointSpec{Node: node, IPNetworks: []string{ip.Network().String()}}
+		createResource(wep, model.ResourceKey{Kind: apiv2.KindWorkloadEndpoint, Namespace: "default", Name: name})
+	}
+
+	autoAssign := func(num int, handleID string) []cnet.IP {
+		v4, _, err := ic.AutoAssign(ctx, AutoAssignArgs{Num4: num, HandleID: &handleID, Hostname: "node-1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v4).To(HaveLen(num))
+		return v4
+	}
+
+	expectAssigned := func(ip cnet.IP, assigned bool) {
+		_, err := ic.GetAssignmentAttributes(ctx, ip)
+		if assigned {
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		} else {
+			ExpectWithOffset(1, err).To(HaveOccurred())
+		}
+	}
+
+	BeforeEach(func() {
+		bc.Clean()
+		deleteAllPools()
+		applyPool("10.0.0.0/24", true)
+
+		By("Creating a node with a tunnel address")
+		node := apiv2.NewNode()
+		node.Name = "node-1"
+		node.Spec.BGP = &apiv2.NodeBGPSpec{IPv4Address: "192.168.0.1/24", IPv4IPIPTunnelAddr: tunnelIP.String()}
+		createResource(node, model.ResourceKey{Kind: apiv2.KindNode, Name: "node-1"})
+		Expect(ic.AssignIP(ctx, AssignIPArgs{
+			IP:       tunnelIP,
+			HandleID: &handleTunnel,
+			Attrs:    map[string]string{AttributeNode: "node-1", AttributeType: AttributeTypeIPIP},
+			Hostname: "node-1",
+		})).NotTo(HaveOccurred())
+
+		By("Assigning addresses to workload endpoints on existing and deleted nodes")
+		ipInUse = autoAssign(1, handleInUse)[0]
+		createWorkloadEndpoint("wep-1", "node-1", ipInUse)
+		ipGoneNode = autoAssign(1, handleGoneNode)[0]
+		createWorkloadEndpoint("wep-2", "node-2", ipGoneNode)
+
+		By("Assigning addresses that are not used by any workload endpoint")
+		ipsLeaked = autoAssign(2, handleLeaked)
+		Expect(ic.AssignIP(ctx, AssignIPArgs{
+			IP:       unhandledIP,
+			Attrs:    map[string]string{AttributeNamespace: "default", AttributePod: "pod-1"},
+			Hostname: "node-1",
+		})).NotTo(HaveOccurred())
+
+		By("Assigning addresses that are not owned by a workload or tunnel")
+		Expect(ic.AssignIP(ctx, AssignIPArgs{IP: foreignIP, HandleID: &handleForeign, Hostname: "node-1"})).NotTo(HaveOccurred())
+		Expect(ic.AssignIP(ctx, AssignIPArgs{IP: unownedIP, Hostname: "node-1"})).NotTo(HaveOccurred())
+
+		By("Creating handles with no allocations")
+		for _, h := range []string{handleEmpty, "load-balancer-empty"} {
+			createResource(&model.IPAMHandle{
+				HandleID: h,
+				Block:    map[string]int{"10.0.0.0/26": 1},
+			}, model.IPAMHandleKey{HandleID: h})
+		}
+	})
+
+	It("should not report leaks until the grace period has passed", func() {
+		clk := clock.NewFakeClock(now)
+		gc := NewGarbageCollectorWithClock(bc, ipPools, workloads, GarbageCollectorOptions{GracePeriod: gracePeriod}, clk)
+
+		report, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.LeakedIPs).To(BeEmpty())
+		Expect(report.EmptyHandles).To(BeEmpty())
+
+		clk.Step(gracePeriod - time.Second)
+		report, err = gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.LeakedIPs).To(BeEmpty())
+		Expect(report.EmptyHandles).To(BeEmpty())
+	})
+
+	It("should report but not release leaks in dry-run mode", func() {
+		clk := clock.NewFakeClock(now)
+		gc := NewGarbageCollectorWithClock(bc, ipPools, workloads, GarbageCollectorOptions{GracePeriod: gracePeriod, DryRun: true}, clk)
+
+		_, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		clk.Step(gracePeriod)
+		report, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		leaked := []string{}
+		for _, l := range report.LeakedIPs {
+			leaked = append(leaked, l.IP.String())
+			Expect(l.LeakedSince).To(Equal(now))
+			Expect(l.Host).To(Equal("node-1"))
+		}
+		Expect(leaked).To(ConsistOf(ipGoneNode.String(), ipsLeaked[0].String(), ipsLeaked[1].String(), unhandledIP.String()))
+		Expect(report.EmptyHandles).To(ConsistOf(handleEmpty))
+		Expect(report.ReleasedIPs).To(BeEmpty())
+		Expect(report.DeletedHandles).To(BeEmpty())
+
+		expectAssigned(ipGoneNode, true)
+		expectAssigned(unhandledIP, true)
+		_, err = bc.Get(ctx, model.IPAMHandleKey{HandleID: handleEmpty}, "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should release leaked addresses and delete empty handles after the grace period", func() {
+		clk := clock.NewFakeClock(now)
+		gc := NewGarbageCollectorWithClock(bc, ipPools, workloads, GarbageCollectorOptions{GracePeriod: gracePeriod}, clk)
+
+		_, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		clk.Step(gracePeriod)
+		report, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.LeakedIPs).To(HaveLen(4))
+		released := []string{}
+		for _, ip := range report.ReleasedIPs {
+			released = append(released, ip.String())
+		}
+		Expect(released).To(ConsistOf(ipGoneNode.String(), ipsLeaked[0].String(), ipsLeaked[1].String(), unhandledIP.String()))
+		Expect(report.DeletedHandles).To(ConsistOf(handleEmpty))
+
+		By("Checking the leaked addresses and their handles have been released")
+		expectAssigned(ipGoneNode, false)
+		expectAssigned(ipsLeaked[0], false)
+		expectAssigned(ipsLeaked[1], false)
+		expectAssigned(unhandledIP, false)
+		for _, h := range []string{handleLeaked, handleGoneNode, handleEmpty} {
+			_, err = bc.Get(ctx, model.IPAMHandleKey{HandleID: h}, "")
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+		}
+
+		By("Checking the addresses in use have not been released")
+		expectAssigned(ipInUse, true)
+		expectAssigned(tunnelIP, true)
+
+		By("Checking the addresses assigned by other tooling have not been released")
+		expectAssigned(foreignIP, true)
+		expectAssigned(unownedIP, true)
+		_, err = bc.Get(ctx, model.IPAMHandleKey{HandleID: "load-balancer-empty"}, "")
+		Expect(err).NotTo(HaveOccurred())
+		ips, err := ic.IPsByHandle(ctx, handleInUse)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ips[0].String()).To(Equal(ipInUse.String()))
+
+		By("Collecting again and expecting nothing to be leaked")
+		report, err = gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.LeakedIPs).To(BeEmpty())
+		Expect(report.EmptyHandles).To(BeEmpty())
+	})
+
+	It("should forget a leak that is resolved within the grace period", func() {
+		clk := clock.NewFakeClock(now)
+		gc := NewGarbageCollectorWithClock(bc, ipPools, workloads, GarbageCollectorOptions{GracePeriod: gracePeriod}, clk)
+
+		_, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Creating the workload endpoint for the unhandled address")
+		createWorkloadEndpoint("wep-3", "node-1", unhandledIP)
+		clk.Step(gracePeriod)
+		_, err = gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		expectAssigned(unhandledIP, true)
+	})
+
+	It("should not release an address that has been reassigned with a different handle", func() {
+		clk := clock.NewFakeClock(now)
+		gc := NewGarbageCollectorWithClock(bc, ipPools, workloads, GarbageCollectorOptions{GracePeriod: gracePeriod}, clk)
+
+		_, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Reassigning the unhandled address with a handle")
+		_, err = ic.ReleaseIPs(ctx, []cnet.IP{unhandledIP})
+		Expect(err).NotTo(HaveOccurred())
+		handleNew := "k8s-pod-network.new"
+		Expect(ic.AssignIP(ctx, AssignIPArgs{IP: unhandledIP, HandleID: &handleNew, Hostname: "node-1"})).NotTo(HaveOccurred())
+
+		clk.Step(gracePeriod)
+		report, err := gc.Collect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		for _, l := range report.LeakedIPs {
+			Expect(l.IP.String()).NotTo(Equal(unhandledIP.String()))
+		}
+		expectAssigned(unhandledIP, true)
+	})
+
+	It("should only release an address that is still assigned with the expected handle", func() {
+		other := "k8s-pod-network.other"
+		notReleased, err := ic.(*ipamClient).releaseIPs(ctx, []cnet.IP{ipsLeaked[0], unhandledIP}, map[string]*string{
+			ipsLeaked[0].String(): &other,
+			unhandledIP.String():  nil,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notReleased).To(HaveLen(1))
+		Expect(notReleased[0].String()).To(Equal(ipsLeaked[0].String()))
+		expectAssigned(ipsLeaked[0], true)
+		expectAssigned(unhandledIP, false)
+	})
+})
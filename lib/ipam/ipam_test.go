@@ -20,13 +20,16 @@ import (
 	"fmt"
 	"net"
 	"sort"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -39,37 +42,67 @@ import (
 // of the accessor that we populate directly, rather than requiring the pool
 // data to be persisted in etcd.
 type ipPoolAccessor struct {
-	pools map[string]bool
+	pools map[string]pool
 }
 
-func (i *ipPoolAccessor) GetEnabledPools(ipVersion int) ([]cnet.IPNet, error) {
+type pool struct {
+	enabled      bool
+	blockSize    int
+	nodeSelector string
+}
+
+func (i *ipPoolAccessor) GetEnabledPools(ipVersion int) ([]apiv2.IPPool, error) {
+	return i.getPools(func(p pool) bool { return p.enabled }, ipVersion), nil
+}
+
+func (i *ipPoolAccessor) GetAllPools() ([]apiv2.IPPool, error) {
+	return i.getPools(func(p pool) bool { return true }, 0), nil
+}
+
+func (i *ipPoolAccessor) getPools(include func(pool) bool, ipVersion int) []apiv2.IPPool {
 	sorted := []string{}
-	// Get a sorted list of enabled pool CIDR strings.
+	// Get a sorted list of included pool CIDR strings.
 	for p, e := range i.pools {
-		if e {
+		if include(e) {
 			sorted = append(sorted, p)
 		}
 	}
 	sort.Strings(sorted)
 
-	// Convert to IPNets and sort out the correct IP versions.  Sorting the results
+	// Convert to IPPools and sort out the correct IP versions.  Sorting the results
 	// mimics more closely the behavior of etcd and allows the tests to be
 	// deterministic.
-	cidrs := []cnet.IPNet{}
+	pools := []apiv2.IPPool{}
 	for _, p := range sorted {
 		c := cnet.MustParseCIDR(p)
-		if c.Version() == ipVersion {
-			cidrs = append(cidrs, c)
+		if ipVersion == 0 || c.Version() == ipVersion {
+			pool := apiv2.NewIPPool()
+			pool.Name = p
+			pool.Spec.CIDR = c.String()
+			pool.Spec.Disabled = !i.pools[p].enabled
+			pool.Spec.BlockSize = i.pools[p].blockSize
+			pool.Spec.NodeSelector = i.pools[p].nodeSelector
+			pools = append(pools, *pool)
 		}
 	}
 
-	log.Infof("GetEnabledPools returns: %s", cidrs)
+	log.Infof("getPools returns: %v", sorted)
+
+	return pools
+}
 
-	return cidrs, nil
+// Implement a "mock" IP reservations accessor for the IPAM client.
+type ipReservationAccessor struct {
+	cidrs []cnet.IPNet
+}
+
+func (i *ipReservationAccessor) GetReservedCIDRs(ctx context.Context) ([]cnet.IPNet, error) {
+	return i.cidrs, nil
 }
 
 var (
-	ipPools = &ipPoolAccessor{pools: map[string]bool{}}
+	ipPools      = &ipPoolAccessor{pools: map[string]pool{}}
+	reservations = &ipReservationAccessor{}
 )
 
 type testArgsClaimAff struct {
@@ -89,7 +122,7 @@ var _ = testutils.E2eDatastoreDescribe("IPAM tests", testutils.DatastoreEtcdV3,
 	if err != nil {
 		panic(err)
 	}
-	ic := NewIPAMClient(bc, ipPools)
+	ic := NewIPAMClient(bc, ipPools, reservations)
 
 	// We're assigning one IP which should be from the only ipPool created at the time, second one
 	// should be from the same /26 block since they're both from the same host, then delete
@@ -146,7 +179,7 @@ var _ = testutils.E2eDatastoreDescribe("IPAM tests", testutils.DatastoreEtcdV3,
 
 				p, _ := ipPools.GetEnabledPools(4)
 				Expect(len(p)).To(Equal(1))
-				Expect(p[0].String()).To(Equal(pool2.String()))
+				Expect(p[0].Spec.CIDR).To(Equal(pool2.String()))
 				p, _ = ipPools.GetEnabledPools(6)
 				Expect(len(p)).To(BeZero())
 
@@ -173,6 +206,59 @@ var _ = testutils.E2eDatastoreDescribe("IPAM tests", testutils.DatastoreEtcdV3,
 		})
 	})
 
+	Describe("IPAM AutoAssign from pools with a node selector", func() {
+		It("should only assign from pools that select the host", func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPoolWithNodeSelector("10.0.0.0/24", true, "rack == 'rack1'")
+			applyPoolWithNodeSelector("20.0.0.0/24", true, "rack == 'rack2'")
+			applyPool("30.0.0.0/24", false)
+
+			for _, n := range []struct{ name, rack string }{{"host-rack1", "rack1"}, {"host-rack2", "rack2"}} {
+				node := apiv2.NewNode()
+				node.Name = n.name
+				node.Labels = map[string]string{"rack": n.rack}
+				_, err := bc.Create(context.Background(), &model.KVPair{
+					Key:   model.ResourceKey{Kind: apiv2.KindNode, Name: n.name},
+					Value: node,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("assigning to a host in rack1")
+			host := "host-rack1"
+			rack1 := cnet.MustParseNetwork("10.0.0.0/24")
+			rack2 := cnet.MustParseNetwork("20.0.0.0/24")
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: host})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			Expect(rack1.IPNet.Contains(v4[0].IP)).To(BeTrue())
+
+			By("assigning to a host in rack2")
+			host = "host-rack2"
+			v4, _, err = ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: host})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			Expect(rack2.IPNet.Contains(v4[0].IP)).To(BeTrue())
+
+			By("assigning to a host that is not selected by any pool")
+			host = "host-no-node"
+			_, _, err = ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: host})
+			Expect(err).To(HaveOccurred())
+
+			By("explicitly requesting a pool that does not select the host")
+			host = "host-rack1"
+			v4, _, err = ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4:      1,
+				Hostname:  host,
+				IPv4Pools: []cnet.IPNet{rack2},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			Expect(rack2.IPNet.Contains(v4[0].IP)).To(BeTrue())
+		})
+	})
+
 	Describe("IPAM AutoAssign from any pool", func() {
 		// Assign an IP address, don't pass a pool, make sure we can get an
 		// address.
@@ -372,6 +458,413 @@ var _ = testutils.E2eDatastoreDescribe("IPAM tests", testutils.DatastoreEtcdV3,
 		})
 	})
 
+	Describe("IPAM with reserved addresses", func() {
+		host := "host-A"
+
+		BeforeEach(func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPoolWithBlockSize("10.0.0.0/24", true, 29)
+			reservations.cidrs = []cnet.IPNet{
+				cnet.MustParseCIDR("10.0.0.0/30"),
+				cnet.MustParseCIDR("10.0.0.5/32"),
+			}
+		})
+
+		AfterEach(func() {
+			reservations.cidrs = nil
+		})
+
+		It("should not auto-assign reserved addresses", func() {
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 4, Hostname: host})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(4))
+			reserved := []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.5"}
+			for _, ip := range v4 {
+				Expect(reserved).NotTo(ContainElement(ip.String()))
+			}
+		})
+
+		It("should not claim blocks that are fully reserved", func() {
+			deleteAllPools()
+			applyPoolWithBlockSize("10.0.1.0/29", true, 29)
+			reservations.cidrs = []cnet.IPNet{cnet.MustParseCIDR("10.0.1.0/29")}
+			pools := []cnet.IPNet{cnet.MustParseNetwork("10.0.1.0/29")}
+
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: host, IPv4Pools: pools})
+			Expect(err).To(BeAssignableToTypeOf(blocksReservedError("")))
+			Expect(v4).To(BeEmpty())
+			Expect(getAffineBlocks(bc, host)).To(BeEmpty())
+		})
+
+		It("should return an error when the remaining blocks are reserved", func() {
+			deleteAllPools()
+			applyPoolWithBlockSize("10.0.1.0/28", true, 29)
+			reservations.cidrs = []cnet.IPNet{cnet.MustParseCIDR("10.0.1.8/29")}
+			pools := []cnet.IPNet{cnet.MustParseNetwork("10.0.1.0/28")}
+
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 9, Hostname: host, IPv4Pools: pools})
+			Expect(err).To(BeAssignableToTypeOf(blocksReservedError("")))
+			Expect(v4).To(BeEmpty())
+			Expect(getAffineBlocks(bc, host)).To(Equal([]cnet.IPNet{cnet.MustParseNetwork("10.0.1.0/29")}))
+		})
+
+		It("should reject assigning a reserved address", func() {
+			err := ic.AssignIP(context.Background(), AssignIPArgs{IP: cnet.MustParseIP("10.0.0.5"), Hostname: host})
+			Expect(err).To(BeAssignableToTypeOf(reservedAddressError{}))
+		})
+
+		It("should assign a reserved address when reservations are ignored", func() {
+			err := ic.AssignIP(context.Background(), AssignIPArgs{
+				IP:                 cnet.MustParseIP("10.0.0.5"),
+				Hostname:           host,
+				IgnoreReservations: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			attrs, err := ic.GetAssignmentAttributes(context.Background(), cnet.MustParseIP("10.0.0.5"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs).To(BeNil())
+		})
+	})
+
+	Describe("IPAM with a deleted pool", func() {
+		host := "host-A"
+
+		BeforeEach(func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPoolWithBlockSize("10.0.0.0/24", true, 29)
+		})
+
+		It("should find and release addresses using the existing block size", func() {
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4:     1,
+				Hostname: host,
+				Attrs:    map[string]string{"key": "value"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			ip := cnet.IP{v4[0].IP}
+
+			By("deleting the pool")
+			deleteAllPools()
+
+			By("reading the assignment attributes")
+			attrs, err := ic.GetAssignmentAttributes(context.Background(), ip)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs).To(Equal(map[string]string{"key": "value"}))
+
+			By("releasing the address")
+			unallocated, err := ic.ReleaseIPs(context.Background(), []cnet.IP{ip})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unallocated).To(BeEmpty())
+			_, err = ic.GetAssignmentAttributes(context.Background(), ip)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("IPAM GetUtilization", func() {
+		It("should report addresses borrowed from another host's block", func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPool("10.0.0.0/26", true)
+			applyPool("10.0.1.0/26", true)
+
+			By("Assigning addresses on host-A, which claims the only block in the first pool")
+			_, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4: 3, Hostname: "host-A", IPv4Pools: []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/26")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Assigning an address on host-B, which must borrow from host-A's block")
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4: 1, Hostname: "host-B", IPv4Pools: []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/26")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+
+			utils, err := ic.GetUtilization(context.Background(), GetUtilizationArgs{
+				Pools: []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/26")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(utils).To(HaveLen(1))
+			Expect(utils[0].Allocated).To(Equal(4))
+			Expect(utils[0].Borrowed).To(Equal(1))
+			Expect(utils[0].Free.Int64()).To(Equal(int64(60)))
+			Expect(utils[0].Blocks).To(HaveLen(1))
+			Expect(utils[0].Blocks[0].Host).To(Equal("host-A"))
+			Expect(utils[0].Hosts).To(Equal([]HostUtilization{
+				{Host: "host-A", Blocks: 1, Capacity: 64, Allocated: 4, Free: 60},
+				{Host: "host-B", Borrowed: 1},
+			}))
+		})
+	})
+
+	Describe("IPAM ListAllocations", func() {
+		It("should list the allocations matching the filter", func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPool("10.0.0.0/24", true)
+
+			handle := "handle-1"
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4: 1, Hostname: "host-A", HandleID: &handle,
+				Attrs: map[string]string{"namespace": "default", "pod": "pod-1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			_, _, err = ic.AutoAssign(context.Background(), AutoAssignArgs{
+				Num4: 2, Hostname: "host-B",
+				Attrs: map[string]string{"namespace": "kube-system"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			allocs, err := ic.ListAllocations(context.Background(), AllocationFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocs).To(HaveLen(3))
+
+			allocs, err = ic.ListAllocations(context.Background(), AllocationFilter{
+				Attrs: map[string]string{"namespace": "default"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocs).To(HaveLen(1))
+			Expect(allocs[0].IP.String()).To(Equal(v4[0].String()))
+			Expect(allocs[0].HandleID).To(Equal(handle))
+			Expect(allocs[0].Host).To(Equal("host-A"))
+			Expect(allocs[0].Attrs["pod"]).To(Equal("pod-1"))
+
+			allocs, err = ic.ListAllocations(context.Background(), AllocationFilter{Host: "host-B"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocs).To(HaveLen(2))
+
+			allocs, err = ic.ListAllocations(context.Background(), AllocationFilter{
+				Pools: []cnet.IPNet{cnet.MustParseNetwork("10.1.0.0/24")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocs).To(BeEmpty())
+		})
+	})
+
+	Describe("IPAM ReclaimEmptyBlocks", func() {
+		var rc *ipamClient
+		var fakeClock *clock.FakeClock
+		pools := []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/26")}
+
+		BeforeEach(func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPool("10.0.0.0/26", true)
+			fakeClock = clock.NewFakeClock(time.Now())
+			rc = NewIPAMClient(bc, ipPools, reservations).(*ipamClient)
+			rc.clock = fakeClock
+		})
+
+		// assignAndRelease assigns an address on the given host and then releases it,
+		// leaving the host's block empty.
+		assignAndRelease := func(host string) {
+			v4, _, err := rc.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: host, IPv4Pools: pools})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			unallocated, err := rc.ReleaseIPs(context.Background(), v4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unallocated).To(BeEmpty())
+		}
+
+		It("should not reclaim blocks when reclamation is disabled", func() {
+			assignAndRelease("host-A")
+			fakeClock.Step(24 * time.Hour)
+			reclaimed, err := rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+			Expect(getAffineBlocks(bc, "host-A")).To(HaveLen(1))
+		})
+
+		It("should reclaim an empty block once it has been idle for the configured period", func() {
+			assignAndRelease("host-A")
+
+			By("Enabling reclamation while the block exists")
+			err := rc.SetIPAMConfig(context.Background(), IPAMConfig{AutoAllocateBlocks: true, EmptyBlockIdlePeriod: 5 * time.Minute})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Marking the block as empty on the first pass")
+			reclaimed, err := rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+
+			By("Keeping the block until the idle period has passed")
+			fakeClock.Step(4 * time.Minute)
+			reclaimed, err = rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+			Expect(getAffineBlocks(bc, "host-A")).To(HaveLen(1))
+
+			By("Reclaiming the block after the idle period")
+			fakeClock.Step(time.Minute)
+			reclaimed, err = rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(Equal(pools))
+			Expect(getAffineBlocks(bc, "host-A")).To(BeEmpty())
+			_, err = bc.Get(context.Background(), model.BlockKey{CIDR: pools[0]}, "")
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+			By("Allowing another host to claim the block")
+			v4, _, err := rc.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: "host-B", IPv4Pools: pools})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+			Expect(getAffineBlocks(bc, "host-B")).To(Equal(pools))
+		})
+
+		It("should not reclaim a block that is reused during the idle period", func() {
+			assignAndRelease("host-A")
+			err := rc.SetIPAMConfig(context.Background(), IPAMConfig{AutoAllocateBlocks: true, EmptyBlockIdlePeriod: 5 * time.Minute})
+			Expect(err).NotTo(HaveOccurred())
+			reclaimed, err := rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+
+			By("Assigning from the block after it has been marked as empty")
+			v4, _, err := rc.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: "host-A", IPv4Pools: pools})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+
+			fakeClock.Step(time.Hour)
+			reclaimed, err = rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+			Expect(getAffineBlocks(bc, "host-A")).To(Equal(pools))
+
+			By("Restarting the idle period when the block is emptied again")
+			_, err = rc.ReleaseIPs(context.Background(), v4)
+			Expect(err).NotTo(HaveOccurred())
+			reclaimed, err = rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(BeEmpty())
+			fakeClock.Step(5 * time.Minute)
+			reclaimed, err = rc.ReclaimEmptyBlocks(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(Equal(pools))
+		})
+
+		It("should keep the affinity of a block that is modified while it is deleted", func() {
+			assignAndRelease("host-A")
+			obj, err := bc.Get(context.Background(), model.BlockKey{CIDR: pools[0]}, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Assigning from the block after it has been read")
+			v4, _, err := rc.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: "host-A", IPv4Pools: pools})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+
+			deleted, err := rc.blockReaderWriter.deleteEmptyBlock(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeFalse())
+			Expect(getAffineBlocks(bc, "host-A")).To(Equal(pools))
+			_, err = bc.Get(context.Background(), model.BlockKey{CIDR: pools[0]}, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not allow StrictAffinity to be changed while blocks exist", func() {
+			assignAndRelease("host-A")
+			err := rc.SetIPAMConfig(context.Background(), IPAMConfig{AutoAllocateBlocks: true, StrictAffinity: true})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("IPAM block limits and borrowing", func() {
+		pools := []cnet.IPNet{cnet.MustParseNetwork("10.0.0.0/25")}
+
+		// Create a pool with two blocks, and give host-B one of them.  host-A can
+		// then claim at most one block of its own.
+		BeforeEach(func() {
+			bc.Clean()
+			deleteAllPools()
+			applyPool("10.0.0.0/25", true)
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: "host-B", IPv4Pools: pools})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(1))
+		})
+
+		setConfig := func(maxBlocks int, borrowing BorrowingPolicy) {
+			err := ic.SetIPAMConfig(context.Background(), IPAMConfig{
+				AutoAllocateBlocks: true,
+				MaxBlocksPerHost:   maxBlocks,
+				AllowBorrowing:     borrowing,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		assign := func(num int) ([]cnet.IP, error) {
+			v4, _, err := ic.AutoAssign(context.Background(), AutoAssignArgs{Num4: num, Hostname: "host-A", IPv4Pools: pools})
+			return v4, err
+		}
+
+		It("should reject an invalid configuration", func() {
+			err := ic.SetIPAMConfig(context.Background(), IPAMConfig{AutoAllocateBlocks: true, MaxBlocksPerHost: -1})
+			Expect(err).To(HaveOccurred())
+			err = ic.SetIPAMConfig(context.Background(), IPAMConfig{AutoAllocateBlocks: true, AllowBorrowing: "sometimes"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should borrow when the host has reached its block limit and borrowing is always allowed", func() {
+			setConfig(1, BorrowingAlways)
+			v4, err := assign(65)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(65))
+			Expect(getAffineBlocks(bc, "host-A")).To(HaveLen(1))
+		})
+
+		It("should return an error when the host has reached its block limit and may not borrow", func() {
+			setConfig(1, BorrowingOnlyWhenPoolExhausted)
+			v4, err := assign(65)
+			Expect(err).To(BeAssignableToTypeOf(maxBlocksPerHostError{}))
+			Expect(err.Error()).To(ContainSubstring("host-A"))
+			Expect(v4).To(BeEmpty())
+			Expect(getAffineBlocks(bc, "host-A")).To(HaveLen(1))
+		})
+
+		It("should borrow once the pool is exhausted when borrowing is only allowed then", func() {
+			setConfig(0, BorrowingOnlyWhenPoolExhausted)
+			v4, err := assign(65)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(65))
+			Expect(getAffineBlocks(bc, "host-A")).To(HaveLen(1))
+		})
+
+		It("should never borrow when borrowing is not allowed", func() {
+			setConfig(0, BorrowingNever)
+			v4, err := assign(65)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v4).To(HaveLen(64))
+		})
+
+		It("should release a block claimed concurrently with another block for the host", func() {
+			setConfig(1, BorrowingOnlyWhenPoolExhausted)
+
+			// Claim another block for host-A just before host-A's own claim is made, as
+			// a concurrent request for host-A would.
+			concurrent := cnet.MustParseNetwork("10.1.0.0/26")
+			rc := &racingClient{Client: bc, race: func() {
+				affinity := "host:host-A"
+				b := newBlock(concurrent)
+				b.Affinity = &affinity
+				_, err := bc.Create(context.Background(), &model.KVPair{
+					Key:   model.BlockAffinityKey{Host: "host-A", CIDR: concurrent},
+					Value: model.BlockAffinityValue,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = bc.Create(context.Background(), &model.KVPair{
+					Key:   model.BlockKey{CIDR: concurrent},
+					Value: b.AllocationBlock,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}}
+			v4, _, err := NewIPAMClient(rc, ipPools, reservations).AutoAssign(context.Background(), AutoAssignArgs{Num4: 1, Hostname: "host-A", IPv4Pools: pools})
+			Expect(err).To(BeAssignableToTypeOf(maxBlocksPerHostError{}))
+			Expect(v4).To(BeEmpty())
+			Expect(getAffineBlocks(bc, "host-A")).To(Equal([]cnet.IPNet{concurrent}))
+		})
+	})
+
 	DescribeTable("AutoAssign: requested IPs vs returned IPs",
 		func(host string, cleanEnv bool, pool []string, usePool string, inv4, inv6, expv4, expv6 int, expError error) {
 			if cleanEnv {
@@ -606,6 +1099,22 @@ func assignIPutil(ic Interface, assignIP net.IP, host string) {
 	}
 }
 
+// racingClient is a backend client that calls the race function once, just before the
+// first block affinity is created.
+type racingClient struct {
+	bapi.Client
+	race func()
+}
+
+func (c *racingClient) Create(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	if _, ok := kvp.Key.(model.BlockAffinityKey); ok && c.race != nil {
+		race := c.race
+		c.race = nil
+		race()
+	}
+	return c.Client.Create(ctx, kvp)
+}
+
 // getAffineBlocks gets all the blocks affined to the host passed in.
 func getAffineBlocks(backend bapi.Client, host string) []cnet.IPNet {
 	opts := model.BlockAffinityListOptions{Host: host, IPVersion: 4}
@@ -629,10 +1138,19 @@ func getAffineBlocks(backend bapi.Client, host string) []cnet.IPNet {
 
 func deleteAllPools() {
 	log.Infof("Deleting all pools")
-	ipPools.pools = map[string]bool{}
+	ipPools.pools = map[string]pool{}
 }
 
 func applyPool(cidr string, enabled bool) {
-	log.Infof("Adding pool: %s, enabled: %v", cidr, enabled)
-	ipPools.pools[cidr] = enabled
+	applyPoolWithBlockSize(cidr, enabled, 0)
+}
+
+func applyPoolWithBlockSize(cidr string, enabled bool, blockSize int) {
+	log.Infof("Adding pool: %s, enabled: %v, block size: %d", cidr, enabled, blockSize)
+	ipPools.pools[cidr] = pool{enabled: enabled, blockSize: blockSize}
+}
+
+func applyPoolWithNodeSelector(cidr string, enabled bool, nodeSelector string) {
+	log.Infof("Adding pool: %s, enabled: %v, node selector: %s", cidr, enabled, nodeSelector)
+	ipPools.pools[cidr] = pool{enabled: enabled, nodeSelector: nodeSelector}
 }
@@ -15,6 +15,9 @@
 package ipam
 
 import (
+	"math/big"
+	"time"
+
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
@@ -34,6 +37,10 @@ type AssignIPArgs struct {
 	// will be allocated.  If not specified, this will default
 	// to the value provided by os.Hostname.
 	Hostname string
+
+	// If true, the IP address is assigned even if it is reserved by an
+	// IPReservation.
+	IgnoreReservations bool
 }
 
 // AutoAssignArgs defines the set of arguments for assigning one or more
@@ -79,4 +86,176 @@ type IPAMConfig struct {
 	// allocate blocks of IP address to hosts as needed to assign addresses.
 	// If false, then StrictAffinity must be true.  The default value is true.
 	AutoAllocateBlocks bool
+
+	// When EmptyBlockIdlePeriod is non-zero, ReclaimEmptyBlocks releases the affinity
+	// of blocks that have been empty for at least this long, so that they may be claimed
+	// by other hosts.  The period is stored with a granularity of one second.  The default
+	// value is zero, in which case empty blocks are never reclaimed.
+	EmptyBlockIdlePeriod time.Duration
+
+	// MaxBlocksPerHost is the maximum number of blocks of each IP version that may be
+	// affine to a single host.  Once a host reaches the limit it can no longer claim new
+	// blocks, and may only assign addresses from other hosts' blocks if AllowBorrowing
+	// permits it.  A block claimed concurrently with another claim for the same host
+	// that takes the host over the limit is released again.  The default value is zero,
+	// in which case there is no limit.
+	MaxBlocksPerHost int
+
+	// AllowBorrowing controls whether a host may assign addresses from blocks that are
+	// not affine to it.  Borrowing is never allowed when StrictAffinity is true.  The
+	// default value is BorrowingAlways.
+	AllowBorrowing BorrowingPolicy
+}
+
+// BorrowingPolicy controls when a host may assign addresses from blocks affine to other
+// hosts.
+type BorrowingPolicy string
+
+const (
+	// Borrow addresses whenever the host's own blocks cannot satisfy a request.
+	BorrowingAlways BorrowingPolicy = "Always"
+	// Only borrow addresses when there are no free blocks left in the pools.  A host
+	// that has reached MaxBlocksPerHost does not borrow.
+	BorrowingOnlyWhenPoolExhausted BorrowingPolicy = "OnlyWhenPoolExhausted"
+	// Never borrow addresses.
+	BorrowingNever BorrowingPolicy = "Never"
+)
+
+// AllocationFilter defines the set of criteria for listing IP address allocations.  An
+// allocation must match all of the specified criteria to be listed.
+type AllocationFilter struct {
+	// If specified, only allocations with all of these attribute keys and values are listed.
+	Attrs map[string]string
+
+	// If specified, only allocations within one of these CIDRs, such as the CIDRs of
+	// IP pools, are listed.
+	Pools []cnet.IPNet
+
+	// If specified, only allocations assigned for this host are listed.
+	Host string
+}
+
+// Allocation describes an assigned IP address.
+type Allocation struct {
+	// The assigned IP address.
+	IP cnet.IP
+
+	// The CIDR of the block containing the address.
+	Block cnet.IPNet
+
+	// The handle the address was assigned with, or empty if no handle was given.
+	HandleID string
+
+	// The host the address was assigned for.  Older versions of Calico did not record
+	// the host, so for their addresses this is the host with affinity to the block, or
+	// empty if the block has no affinity.
+	Host string
+
+	// The attributes the address was assigned with.
+	Attrs map[string]string
+}
+
+// DrainPoolReport describes the state of a pool that is being drained.
+type DrainPoolReport struct {
+	// The CIDR of the pool.
+	CIDR cnet.IPNet
+
+	// The allocations remaining in the pool, ordered by IP address.
+	Allocations []Allocation
+
+	// The number of remaining allocations for each host.  Addresses assigned by older
+	// versions of Calico are counted against the host with affinity to their block, or
+	// against the empty host if the block has no affinity.
+	AllocationsByHost map[string]int
+
+	// The number of remaining allocations for each handle.  Addresses assigned without
+	// a handle are counted against the empty handle.
+	AllocationsByHandle map[string]int
+
+	// The CIDRs of the empty blocks that were released by this call.
+	ReleasedBlocks []cnet.IPNet
+}
+
+// GetUtilizationArgs defines the set of arguments for requesting IP address utilization.
+type GetUtilizationArgs struct {
+	// If specified, the CIDRs of the pools for which to report utilization.  If not
+	// specified, this defaults to all pools.
+	Pools []cnet.IPNet
+}
+
+// PoolUtilization reports the utilization of an IP pool.
+type PoolUtilization struct {
+	// The name of the pool.
+	Name string
+
+	// The CIDR of the pool.
+	CIDR cnet.IPNet
+
+	// The number of addresses in the pool.
+	Capacity *big.Int
+
+	// The number of assigned addresses in the pool.
+	Allocated int
+
+	// The number of unassigned addresses in the pool.
+	Free *big.Int
+
+	// The number of addresses in the pool that are assigned for a host other than the
+	// host that has affinity to the block containing the address.  Addresses assigned by
+	// versions of Calico that did not record the host are assumed to be assigned for the
+	// affine host, so addresses borrowed before an upgrade are not counted.
+	Borrowed int
+
+	// The utilization of each of the allocated blocks in the pool, in block order.
+	Blocks []BlockUtilization
+
+	// The utilization by each host with an affine block in, or an address borrowed from,
+	// the pool, in host name order.
+	Hosts []HostUtilization
+}
+
+// BlockUtilization reports the utilization of an allocation block.
+type BlockUtilization struct {
+	// The CIDR of the block.
+	CIDR cnet.IPNet
+
+	// The host that has affinity to the block, or an empty string if the block is not
+	// affine to any host.
+	Host string
+
+	// The number of addresses in the block.
+	Capacity int
+
+	// The number of assigned addresses in the block.
+	Allocated int
+
+	// The number of unassigned addresses in the block.
+	Free int
+
+	// The number of addresses in the block that are assigned for a host other than the
+	// host that has affinity to the block.  As for PoolUtilization, this does not count
+	// addresses borrowed before an upgrade.
+	Borrowed int
+}
+
+// HostUtilization reports the utilization of the addresses in a pool by a host.
+type HostUtilization struct {
+	// The name of the host.
+	Host string
+
+	// The number of blocks in the pool that are affine to the host.
+	Blocks int
+
+	// The number of addresses in the blocks affine to the host.
+	Capacity int
+
+	// The number of assigned addresses in the blocks affine to the host.
+	Allocated int
+
+	// The number of unassigned addresses in the blocks affine to the host.
+	Free int
+
+	// The number of addresses assigned for the host in blocks affine to other hosts.  As
+	// for PoolUtilization, this does not count addresses borrowed before an upgrade.
+	Borrowed int
 }
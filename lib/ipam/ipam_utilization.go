@@ -0,0 +1,239 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// GetUtilization returns the utilization of the requested pools, or of all pools if none are
+// requested, broken down by block and by host.
+func (c ipamClient) GetUtilization(ctx context.Context, args GetUtilizationArgs) ([]PoolUtilization, error) {
+	allPools, err := c.pools.GetAllPools()
+	if err != nil {
+		log.Errorf("Error reading configured pools: %s", err)
+		return nil, err
+	}
+	pools := []apiv2.IPPool{}
+	for _, p := range allPools {
+		if isPoolInRequestedPools(p, args.Pools) {
+			pools = append(pools, p)
+		}
+	}
+
+	// Get the host affinity of each block from the block affinities.
+	affinities := map[string]string{}
+	affs, err := c.client.List(ctx, model.BlockAffinityListOptions{}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.Errorf("Error listing block affinities: %s", err)
+			return nil, err
+		}
+		affs = &model.KVPairList{}
+	}
+	for _, kvp := range affs.KVPairs {
+		k := kvp.Key.(model.BlockAffinityKey)
+		affinities[k.CIDR.String()] = k.Host
+	}
+
+	objs, err := c.client.List(ctx, model.BlockListOptions{}, "")
+	if err != nil {
+		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok {
+			log.Errorf("Error listing blocks: %s", err)
+			return nil, err
+		}
+		objs = &model.KVPairList{}
+	}
+	blocks := []allocationBlock{}
+	for _, kvp := range objs.KVPairs {
+		blocks = append(blocks, allocationBlock{kvp.Value.(*model.AllocationBlock)})
+	}
+
+	return computeUtilization(pools, blocks, affinities), nil
+}
+
+// computeUtilization calculates the utilization of each of the given pools from the given
+// blocks and the map of block CIDR to affine host.  Blocks that are not within any of the
+// pools are ignored.
+func computeUtilization(pools []apiv2.IPPool, blocks []allocationBlock, affinities map[string]string) []PoolUtilization {
+	utils := []PoolUtilization{}
+	for _, p := range pools {
+		_, cidr, err := cnet.ParseCIDR(p.Spec.CIDR)
+		if err != nil {
+			log.WithError(err).Errorf("IPPool %s is configured with an invalid CIDR", p.Name)
+			continue
+		}
+		ones, bits := cidr.Mask.Size()
+		utils = append(utils, PoolUtilization{
+			Name:     p.Name,
+			CIDR:     *cidr,
+			Capacity: big.NewInt(0).Lsh(big.NewInt(1), uint(bits-ones)),
+		})
+	}
+	hostsByPool := make([]map[string]*HostUtilization, len(utils))
+	for i := range hostsByPool {
+		hostsByPool[i] = map[string]*HostUtilization{}
+	}
+	getHost := func(i int, host string) *HostUtilization {
+		if _, ok := hostsByPool[i][host]; !ok {
+			hostsByPool[i][host] = &HostUtilization{Host: host}
+		}
+		return hostsByPool[i][host]
+	}
+
+	for _, b := range blocks {
+		i := -1
+		for j := range utils {
+			if utils[j].CIDR.Contains(b.CIDR.IP) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			log.Debugf("Block %s is not in any requested pool", b.CIDR)
+			continue
+		}
+
+		// Prefer the block affinity, but fall back to the affinity stored in the block.
+		host, ok := affinities[b.CIDR.String()]
+		if !ok && b.Affinity != nil {
+			host = strings.TrimPrefix(*b.Affinity, "host:")
+		}
+		bu := BlockUtilization{
+			CIDR:     b.CIDR,
+			Host:     host,
+			Capacity: b.numAddresses(),
+		}
+		for _, attrIdx := range b.Allocations {
+			if attrIdx == nil {
+				continue
+			}
+			bu.Allocated++
+			// Older versions of Calico did not record the host that an address was assigned
+			// for, so assume it was assigned for the affine host.
+			attrHost := b.Attributes[*attrIdx].Host
+			if attrHost == "" {
+				attrHost = host
+			}
+			if host != "" && attrHost != host {
+				bu.Borrowed++
+				getHost(i, attrHost).Borrowed++
+			}
+		}
+		bu.Free = bu.Capacity - bu.Allocated
+
+		pu := &utils[i]
+		pu.Allocated += bu.Allocated
+		pu.Borrowed += bu.Borrowed
+		pu.Blocks = append(pu.Blocks, bu)
+		if host != "" {
+			hu := getHost(i, host)
+			hu.Blocks++
+			hu.Capacity += bu.Capacity
+			hu.Allocated += bu.Allocated
+			hu.Free += bu.Free
+		}
+	}
+
+	for i := range utils {
+		pu := &utils[i]
+		pu.Free = big.NewInt(0).Sub(pu.Capacity, big.NewInt(int64(pu.Allocated)))
+		sort.Slice(pu.Blocks, func(x, y int) bool {
+			return ipToInt(cnet.IP{pu.Blocks[x].CIDR.IP}).Cmp(ipToInt(cnet.IP{pu.Blocks[y].CIDR.IP})) < 0
+		})
+		hosts := []string{}
+		for h := range hostsByPool[i] {
+			hosts = append(hosts, h)
+		}
+		sort.Strings(hosts)
+		for _, h := range hosts {
+			pu.Hosts = append(pu.Hosts, *hostsByPool[i][h])
+		}
+	}
+	return utils
+}
+
+var (
+	poolSizeDesc = prometheus.NewDesc(
+		"ipam_pool_size", "Total number of addresses in the IP pool.", []string{"pool"}, nil)
+	poolAllocatedDesc = prometheus.NewDesc(
+		"ipam_pool_allocated_ips", "Number of assigned addresses in the IP pool.", []string{"pool"}, nil)
+	poolBorrowedDesc = prometheus.NewDesc(
+		"ipam_pool_borrowed_ips", "Number of addresses in the IP pool assigned for a host without affinity to the block.", []string{"pool"}, nil)
+	hostBlocksDesc = prometheus.NewDesc(
+		"ipam_host_blocks", "Number of blocks in the IP pool affine to the host.", []string{"pool", "host"}, nil)
+	hostAllocatedDesc = prometheus.NewDesc(
+		"ipam_host_allocated_ips", "Number of assigned addresses in the blocks affine to the host.", []string{"pool", "host"}, nil)
+	hostFreeDesc = prometheus.NewDesc(
+		"ipam_host_free_ips", "Number of unassigned addresses in the blocks affine to the host.", []string{"pool", "host"}, nil)
+	hostBorrowedDesc = prometheus.NewDesc(
+		"ipam_host_borrowed_ips", "Number of addresses assigned for the host in blocks affine to other hosts.", []string{"pool", "host"}, nil)
+)
+
+// utilizationCollector implements prometheus.Collector, reporting the IPAM utilization
+// as gauges.
+type utilizationCollector struct {
+	ipam Interface
+}
+
+// NewUtilizationCollector returns a Prometheus collector that reports the utilization of
+// all pools, and of each host within each pool, as gauges.  The utilization is read from
+// the datastore each time the metrics are collected.
+func NewUtilizationCollector(ipam Interface) prometheus.Collector {
+	return utilizationCollector{ipam: ipam}
+}
+
+// Describe implements prometheus.Collector.
+func (u utilizationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolSizeDesc
+	ch <- poolAllocatedDesc
+	ch <- poolBorrowedDesc
+	ch <- hostBlocksDesc
+	ch <- hostAllocatedDesc
+	ch <- hostFreeDesc
+	ch <- hostBorrowedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (u utilizationCollector) Collect(ch chan<- prometheus.Metric) {
+	utils, err := u.ipam.GetUtilization(context.Background(), GetUtilizationArgs{})
+	if err != nil {
+		log.WithError(err).Error("Failed to get IPAM utilization")
+		return
+	}
+	for _, pu := range utils {
+		size, _ := new(big.Float).SetInt(pu.Capacity).Float64()
+		ch <- prometheus.MustNewConstMetric(poolSizeDesc, prometheus.GaugeValue, size, pu.Name)
+		ch <- prometheus.MustNewConstMetric(poolAllocatedDesc, prometheus.GaugeValue, float64(pu.Allocated), pu.Name)
+		ch <- prometheus.MustNewConstMetric(poolBorrowedDesc, prometheus.GaugeValue, float64(pu.Borrowed), pu.Name)
+		for _, hu := range pu.Hosts {
+			ch <- prometheus.MustNewConstMetric(hostBlocksDesc, prometheus.GaugeValue, float64(hu.Blocks), pu.Name, hu.Host)
+			ch <- prometheus.MustNewConstMetric(hostAllocatedDesc, prometheus.GaugeValue, float64(hu.Allocated), pu.Name, hu.Host)
+			ch <- prometheus.MustNewConstMetric(hostFreeDesc, prometheus.GaugeValue, float64(hu.Free), pu.Name, hu.Host)
+			ch <- prometheus.MustNewConstMetric(hostBorrowedDesc, prometheus.GaugeValue, float64(hu.Borrowed), pu.Name, hu.Host)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// utilizationIPAM is an IPAM client that returns fixed utilization data.
+type utilizationIPAM struct {
+	Interface
+	utils []PoolUtilization
+}
+
+func (u utilizationIPAM) GetUtilization(ctx context.Context, args GetUtilizationArgs) ([]PoolUtilization, error) {
+	return u.utils, nil
+}
+
+var _ = Describe("IPAM utilization", func() {
+	newPool := func(name, cidr string) apiv2.IPPool {
+		p := apiv2.NewIPPool()
+		p.Name = name
+		p.Spec.CIDR = cidr
+		return *p
+	}
+
+	newBlockWithAllocations := func(cidr string, affinity *string, allocs map[string]int) allocationBlock {
+		b := newBlock(cnet.MustParseCIDR(cidr))
+		b.Affinity = affinity
+		for host, num := range allocs {
+			_, err := b.autoAssign(num, nil, host, nil, false, nil, apiv2.AllocationStrategyRandomBlock)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return b
+	}
+
+	It("should record the host that addresses are assigned for", func() {
+		b := newBlockWithAllocations("10.0.0.0/30", nil, map[string]int{"host-a": 1})
+		Expect(b.assign(cnet.MustParseIP("10.0.0.3"), nil, nil, "host-b")).NotTo(HaveOccurred())
+		Expect(b.Attributes).To(HaveLen(2))
+		Expect(b.Attributes[0].Host).To(Equal("host-a"))
+		Expect(b.Attributes[1].Host).To(Equal("host-b"))
+	})
+
+	It("should treat addresses assigned without a host as assigned for the affine host", func() {
+		hostA := "host:host-a"
+		handle := "handle-1"
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/30"))
+		b.Affinity = &hostA
+
+		By("assigning an address as an older version of Calico would")
+		Expect(b.assign(cnet.MustParseIP("10.0.0.0"), &handle, nil, "")).NotTo(HaveOccurred())
+
+		By("assigning addresses with the same handle for the affine host and another host")
+		Expect(b.assign(cnet.MustParseIP("10.0.0.1"), &handle, nil, "host-a")).NotTo(HaveOccurred())
+		Expect(b.assign(cnet.MustParseIP("10.0.0.2"), &handle, nil, "host-b")).NotTo(HaveOccurred())
+		Expect(b.Attributes).To(HaveLen(2))
+		Expect(b.Attributes[0].Host).To(Equal(""))
+		Expect(b.Attributes[1].Host).To(Equal("host-b"))
+
+		utils := computeUtilization([]apiv2.IPPool{newPool("pool-1", "10.0.0.0/24")}, []allocationBlock{b}, nil)
+		Expect(utils).To(HaveLen(1))
+		Expect(utils[0].Borrowed).To(Equal(1))
+		Expect(utils[0].Hosts).To(Equal([]HostUtilization{
+			{Host: "host-a", Blocks: 1, Capacity: 4, Allocated: 3, Free: 1},
+			{Host: "host-b", Borrowed: 1},
+		}))
+	})
+
+	It("should calculate the utilization of each pool, block and host", func() {
+		hostB := "host:host-b"
+		pools := []apiv2.IPPool{newPool("pool-1", "10.0.0.0/24"), newPool("pool-2", "10.1.0.0/24")}
+		blocks := []allocationBlock{
+			newBlockWithAllocations("10.0.0.128/26", nil, map[string]int{"host-c": 1}),
+			newBlockWithAllocations("10.0.0.64/26", &hostB, map[string]int{"host-b": 1}),
+			newBlockWithAllocations("10.0.0.0/26", nil, map[string]int{"host-a": 3, "host-b": 2}),
+			newBlockWithAllocations("10.2.0.0/26", nil, map[string]int{"host-a": 1}),
+		}
+		affinities := map[string]string{"10.0.0.0/26": "host-a"}
+
+		utils := computeUtilization(pools, blocks, affinities)
+		Expect(utils).To(HaveLen(2))
+
+		pu := utils[0]
+		Expect(pu.Name).To(Equal("pool-1"))
+		Expect(pu.CIDR.String()).To(Equal("10.0.0.0/24"))
+		Expect(pu.Capacity).To(Equal(big.NewInt(256)))
+		Expect(pu.Allocated).To(Equal(7))
+		Expect(pu.Free).To(Equal(big.NewInt(249)))
+		Expect(pu.Borrowed).To(Equal(2))
+
+		Expect(pu.Blocks).To(HaveLen(3))
+		Expect(pu.Blocks[0].CIDR.String()).To(Equal("10.0.0.0/26"))
+		Expect(pu.Blocks[0].Host).To(Equal("host-a"))
+		Expect(pu.Blocks[0].Capacity).To(Equal(64))
+		Expect(pu.Blocks[0].Allocated).To(Equal(5))
+		Expect(pu.Blocks[0].Free).To(Equal(59))
+		Expect(pu.Blocks[0].Borrowed).To(Equal(2))
+		Expect(pu.Blocks[1].CIDR.String()).To(Equal("10.0.0.64/26"))
+		Expect(pu.Blocks[1].Host).To(Equal("host-b"))
+		Expect(pu.Blocks[1].Borrowed).To(Equal(0))
+		Expect(pu.Blocks[2].CIDR.String()).To(Equal("10.0.0.128/26"))
+		Expect(pu.Blocks[2].Host).To(Equal(""))
+		Expect(pu.Blocks[2].Allocated).To(Equal(1))
+
+		Expect(pu.Hosts).To(Equal([]HostUtilization{
+			{Host: "host-a", Blocks: 1, Capacity: 64, Allocated: 5, Free: 59},
+			{Host: "host-b", Blocks: 1, Capacity: 64, Allocated: 1, Free: 63, Borrowed: 2},
+		}))
+
+		pu = utils[1]
+		Expect(pu.Name).To(Equal("pool-2"))
+		Expect(pu.Allocated).To(Equal(0))
+		Expect(pu.Free).To(Equal(big.NewInt(256)))
+		Expect(pu.Blocks).To(BeEmpty())
+		Expect(pu.Hosts).To(BeEmpty())
+	})
+
+	It("should report the capacity of large IPv6 pools", func() {
+		utils := computeUtilization([]apiv2.IPPool{newPool("pool-v6", "fd00::/48")}, nil, nil)
+		Expect(utils).To(HaveLen(1))
+		Expect(utils[0].Capacity).To(Equal(big.NewInt(0).Lsh(big.NewInt(1), 80)))
+	})
+
+	It("should report the utilization as Prometheus gauges", func() {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(NewUtilizationCollector(utilizationIPAM{utils: []PoolUtilization{{
+			Name:      "pool-1",
+			Capacity:  big.NewInt(256),
+			Allocated: 7,
+			Free:      big.NewInt(249),
+			Borrowed:  2,
+			Hosts: []HostUtilization{
+				{Host: "host-a", Blocks: 1, Capacity: 64, Allocated: 5, Free: 59, Borrowed: 2},
+			},
+		}}}))
+
+		families, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		values := map[string]float64{}
+		for _, f := range families {
+			for _, m := range f.GetMetric() {
+				name := f.GetName()
+				for _, l := range m.GetLabel() {
+					name += "," + l.GetName() + "=" + l.GetValue()
+				}
+				values[name] = m.GetGauge().GetValue()
+			}
+		}
+		Expect(values).To(Equal(map[string]float64{
+			"ipam_pool_size,pool=pool-1":                      256,
+			"ipam_pool_allocated_ips,pool=pool-1":             7,
+			"ipam_pool_borrowed_ips,pool=pool-1":              2,
+			"ipam_host_blocks,host=host-a,pool=pool-1":        1,
+			"ipam_host_allocated_ips,host=host-a,pool=pool-1": 5,
+			"ipam_host_free_ips,host=host-a,pool=pool-1":      59,
+			"ipam_host_borrowed_ips,host=host-a,pool=pool-1":  2,
+		}))
+	})
+})
@@ -13,10 +13,12 @@
 // limitations under the License.
 package ipam
 
-import cnet "github.com/projectcalico/libcalico-go/lib/net"
+import apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 
-// Interface used to access the enabled IPPools.
+// Interface used to access the IPPools.
 type PoolAccessorInterface interface {
 	// Returns a list of enabled pools sorted in alphanumeric name order.
-	GetEnabledPools(ipVersion int) ([]cnet.IPNet, error)
+	GetEnabledPools(ipVersion int) ([]apiv2.IPPool, error)
+	// Returns a list of all pools sorted in alphanumeric name order.
+	GetAllPools() ([]apiv2.IPPool, error)
 }
@@ -22,31 +22,98 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
 var _ = Describe("Random Block Generator", func() {
 
 	DescribeTable("Test random block generator with different CIDRs",
-		func(cidr string) {
-			poolTest(cidr)
+		func(cidr string, blockSize int, expectedBlockSize int) {
+			poolTest(cidr, blockSize, expectedBlockSize)
 		},
 
-		Entry("IPv4 CIDR", "10.10.0.0/24"),
-		Entry("IPv6 CIDR", "fd80:24e2:f998:72d6::/120"),
+		Entry("IPv4 CIDR", "10.10.0.0/24", 0, 26),
+		Entry("IPv6 CIDR", "fd80:24e2:f998:72d6::/120", 0, 122),
+		Entry("IPv4 CIDR with block size 29", "10.10.0.0/24", 29, 29),
+		Entry("IPv4 CIDR with block size 32", "10.10.0.0/28", 32, 32),
+		Entry("IPv4 CIDR with block size 20", "10.0.0.0/16", 20, 20),
+		Entry("IPv6 CIDR with block size 116", "fd80:24e2:f998:72d6::/112", 116, 116),
+		Entry("IPv6 CIDR with block size 128", "fd80:24e2:f998:72d6::/124", 128, 128),
 	)
+
+	It("should not generate blocks for a pool smaller than the block size", func() {
+		pool := apiv2.IPPool{Spec: apiv2.IPPoolSpec{CIDR: "10.10.0.0/28"}}
+		blocks := randomBlockGenerator(pool, "testHost")
+		Expect(blocks()).To(BeNil())
+	})
+})
+
+var _ = Describe("Block sizes", func() {
+
+	It("should create a block with the number of addresses given by the CIDR", func() {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/28"))
+		Expect(b.numAddresses()).To(Equal(16))
+		Expect(b.Unallocated).To(HaveLen(16))
+		Expect(b.empty()).To(BeTrue())
+
+		ips, err := b.autoAssign(16, nil, "testHost", nil, false, nil, apiv2.AllocationStrategyRandomBlock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(16))
+		Expect(ips[15].String()).To(Equal("10.0.0.15"))
+		Expect(b.numFreeAddresses()).To(Equal(0))
+
+		unallocated, _, err := b.release([]cnet.IP{cnet.MustParseIP("10.0.0.15")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unallocated).To(BeEmpty())
+		Expect(b.numFreeAddresses()).To(Equal(1))
+	})
+
+	It("should continue to handle blocks with the default size", func() {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.64/26"))
+		Expect(b.numAddresses()).To(Equal(64))
+		Expect(b.assign(cnet.MustParseIP("10.0.0.127"), nil, nil, "testHost")).NotTo(HaveOccurred())
+		attrs, err := b.attributesForIP(cnet.MustParseIP("10.0.0.127"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attrs).To(BeNil())
+	})
+
+	DescribeTable("getBlockCIDRForAddress",
+		func(ip string, blockSize int, expected string) {
+			var pool *apiv2.IPPool
+			if blockSize != 0 {
+				pool = &apiv2.IPPool{Spec: apiv2.IPPoolSpec{BlockSize: blockSize}}
+			}
+			Expect(getBlockCIDRForAddress(cnet.MustParseIP(ip), pool).String()).To(Equal(expected))
+		},
+		Entry("IPv4 default block size", "10.0.0.100", 0, "10.0.0.64/26"),
+		Entry("IPv4 /29 block", "10.0.0.100", 29, "10.0.0.96/29"),
+		Entry("IPv4 /32 block", "10.0.0.100", 32, "10.0.0.100/32"),
+		Entry("IPv4 /20 block", "10.0.20.100", 20, "10.0.16.0/20"),
+		Entry("IPv6 default block size", "fd00::1:ff", 0, "fd00::1:c0/122"),
+		Entry("IPv6 /124 block", "fd00::1:ff", 124, "fd00::1:f0/124"),
+	)
+
+	It("should generate blocks of the pool block size within a CIDR", func() {
+		pool := &apiv2.IPPool{Spec: apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", BlockSize: 28}}
+		blocks := blockGenerator(pool, cnet.MustParseCIDR("10.0.0.0/26"))
+		cidrs := []string{}
+		for b := blocks(); b != nil; b = blocks() {
+			cidrs = append(cidrs, b.String())
+		}
+		Expect(cidrs).To(Equal([]string{"10.0.0.0/28", "10.0.0.16/28", "10.0.0.32/28", "10.0.0.48/28"}))
+	})
 })
 
-func poolTest(cidr string) {
+func poolTest(cidr string, blockSize int, expectedBlockSize int) {
 	_, subnet, err := net.ParseCIDR(cidr)
 	Expect(err).NotTo(HaveOccurred())
-	var pools []cnet.IPNet
-	pools = []cnet.IPNet{{*subnet}}
+	pools := []apiv2.IPPool{{Spec: apiv2.IPPoolSpec{CIDR: cidr, BlockSize: blockSize}}}
 	host := "testHost"
 
 	for _, pool := range pools {
 
-		ones, size := pool.Mask.Size()
+		ones, size := subnet.Mask.Size()
 		prefixLen := size - ones
 		numIP := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(prefixLen)), nil)
 		blocks := randomBlockGenerator(pool, host)
@@ -57,16 +124,18 @@ func poolTest(cidr string) {
 			blockCount.Add(blockCount, big.NewInt(1))
 			ip, sn, err := net.ParseCIDR(blk.String())
 			Expect(err).NotTo(HaveOccurred())
+			blockOnes, _ := sn.Mask.Size()
+			Expect(blockOnes).To(Equal(expectedBlockSize))
 
 			By(fmt.Sprintf("Getting block and checking IP is within block: %s\n", blk.String()))
 			for ip := ip.Mask(sn.Mask); sn.Contains(ip); increment(ip) {
-				Expect(pool.Contains(ip)).To(BeTrue())
+				Expect(subnet.Contains(ip)).To(BeTrue())
 			}
 		}
 
 		By(fmt.Sprintf("Checkig the block count has the correct number of blocka"))
 		numBlocks := new(big.Int)
-		numBlocks.Div(numIP, big.NewInt(blockSize))
+		numBlocks.Div(numIP, new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(size-expectedBlockSize)), nil))
 		Expect(blockCount).To(Equal(numBlocks))
 	}
 }
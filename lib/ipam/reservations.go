@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"math/big"
+
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// Interface used to access the IP reservations.
+type ReservationAccessorInterface interface {
+	// Returns the CIDRs of all reserved addresses.  Reserved individual IP addresses are
+	// returned as fully masked CIDRs.
+	GetReservedCIDRs(ctx context.Context) ([]cnet.IPNet, error)
+}
+
+// reservedAddresses is a set of reserved CIDRs.
+type reservedAddresses []cnet.IPNet
+
+// contains returns true if the given address is reserved.
+func (r reservedAddresses) contains(ip cnet.IP) bool {
+	for _, cidr := range r {
+		if cidr.Contains(ip.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinBlock returns the subset of the reservations that overlap the given block CIDR.
+func (r reservedAddresses) withinBlock(blockCIDR cnet.IPNet) reservedAddresses {
+	var overlapping reservedAddresses
+	for _, cidr := range r {
+		if cidr.IsNetOverlap(blockCIDR.IPNet) {
+			overlapping = append(overlapping, cidr)
+		}
+	}
+	return overlapping
+}
+
+// coversBlock returns true if every address in the given block is reserved.
+func (r reservedAddresses) coversBlock(blockCIDR cnet.IPNet) bool {
+	overlapping := r.withinBlock(blockCIDR)
+	if len(overlapping) == 0 {
+		return false
+	}
+	blockOnes, bits := blockCIDR.Mask.Size()
+	for _, cidr := range overlapping {
+		if ones, _ := cidr.Mask.Size(); ones <= blockOnes {
+			// This reservation contains the whole block.
+			return true
+		}
+	}
+
+	// Otherwise, check each address in the block.
+	num := 1 << uint(bits-blockOnes)
+	for o := 0; o < num; o++ {
+		if !overlapping.contains(incrementIP(cnet.IP{blockCIDR.IP}, big.NewInt(int64(o)))) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Reserved addresses", func() {
+	reserved := reservedAddresses{
+		cnet.MustParseCIDR("10.0.0.0/30"),
+		cnet.MustParseCIDR("10.0.0.9/32"),
+		cnet.MustParseCIDR("fd00::/126"),
+	}
+
+	It("should identify reserved addresses", func() {
+		Expect(reserved.contains(cnet.MustParseIP("10.0.0.3"))).To(BeTrue())
+		Expect(reserved.contains(cnet.MustParseIP("10.0.0.9"))).To(BeTrue())
+		Expect(reserved.contains(cnet.MustParseIP("fd00::2"))).To(BeTrue())
+		Expect(reserved.contains(cnet.MustParseIP("10.0.0.4"))).To(BeFalse())
+		Expect(reserved.contains(cnet.MustParseIP("fd00::4"))).To(BeFalse())
+	})
+
+	It("should only return the reservations that overlap a block", func() {
+		Expect(reserved.withinBlock(cnet.MustParseCIDR("10.0.0.8/29"))).To(Equal(reservedAddresses{
+			cnet.MustParseCIDR("10.0.0.9/32"),
+		}))
+		Expect(reserved.withinBlock(cnet.MustParseCIDR("10.0.1.0/29"))).To(BeEmpty())
+	})
+
+	It("should identify blocks in which every address is reserved", func() {
+		Expect(reserved.coversBlock(cnet.MustParseCIDR("10.0.0.0/30"))).To(BeTrue())
+		Expect(reserved.coversBlock(cnet.MustParseCIDR("10.0.0.0/31"))).To(BeTrue())
+		Expect(reserved.coversBlock(cnet.MustParseCIDR("10.0.0.0/29"))).To(BeFalse())
+		Expect(reserved.coversBlock(cnet.MustParseCIDR("10.0.1.0/30"))).To(BeFalse())
+
+		// A block covered by several smaller reservations.
+		split := reservedAddresses{
+			cnet.MustParseCIDR("10.0.0.0/31"),
+			cnet.MustParseCIDR("10.0.0.2/32"),
+			cnet.MustParseCIDR("10.0.0.3/32"),
+		}
+		Expect(split.coversBlock(cnet.MustParseCIDR("10.0.0.0/30"))).To(BeTrue())
+		Expect(split[:2].coversBlock(cnet.MustParseCIDR("10.0.0.0/30"))).To(BeFalse())
+	})
+
+	It("should skip reserved ordinals when auto-assigning from a block", func() {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/28"))
+		ips, err := b.autoAssign(16, nil, "testHost", nil, false, reserved, apiv2.AllocationStrategyRandomBlock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(11))
+		for _, ip := range ips {
+			Expect(reserved.contains(ip)).To(BeFalse())
+		}
+
+		// The reserved addresses remain unallocated and may still be assigned explicitly.
+		Expect(b.Unallocated).To(Equal([]int{0, 1, 2, 3, 9}))
+		Expect(b.assign(cnet.MustParseIP("10.0.0.9"), nil, nil, "testHost")).NotTo(HaveOccurred())
+		Expect(b.Unallocated).To(Equal([]int{0, 1, 2, 3}))
+	})
+
+	It("should return no addresses from a block that is fully reserved", func() {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/30"))
+		ips, err := b.autoAssign(1, nil, "testHost", nil, false, reserved, apiv2.AllocationStrategyRandomBlock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(BeEmpty())
+		Expect(b.numFreeAddresses()).To(Equal(4))
+	})
+})
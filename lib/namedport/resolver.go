@@ -0,0 +1,439 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package namedport resolves the named ports referenced by policy rules into the concrete
+// (IP, protocol, port) tuples of the endpoints which define those named ports.
+package namedport
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+type RuleDirection string
+
+const (
+	RuleDirectionInbound  RuleDirection = "inbound"
+	RuleDirectionOutbound RuleDirection = "outbound"
+)
+
+type PortField string
+
+const (
+	PortFieldSrcPorts    PortField = "src_ports"
+	PortFieldDstPorts    PortField = "dst_ports"
+	PortFieldNotSrcPorts PortField = "!src_ports"
+	PortFieldNotDstPorts PortField = "!dst_ports"
+)
+
+// RuleID identifies a single rule within a policy.
+type RuleID struct {
+	Policy    model.PolicyKey
+	Direction RuleDirection
+	Index     int
+}
+
+func (id RuleID) String() string {
+	return fmt.Sprintf("%s/%s/%d", id.Policy, id.Direction, id.Index)
+}
+
+// NamedPortSetID identifies a named port referenced by one of the port fields of a rule.
+type NamedPortSetID struct {
+	Rule     RuleID
+	Field    PortField
+	PortName string
+}
+
+func (id NamedPortSetID) String() string {
+	return fmt.Sprintf("%s/%s/%s", id.Rule, id.Field, id.PortName)
+}
+
+// Member is a single (IP, protocol, port) tuple that a named port resolves to.
+type Member struct {
+	IP       string
+	Protocol numorstring.Protocol
+	Port     uint16
+}
+
+func (m Member) String() string {
+	return fmt.Sprintf("%s,%s:%d", m.IP, m.Protocol, m.Port)
+}
+
+// Callbacks receives the incremental changes to the resolved named port sets.  A member is
+// added when the first endpoint providing it is matched, and removed when the last endpoint
+// providing it is no longer matched.
+type Callbacks interface {
+	OnMemberAdded(id NamedPortSetID, member Member)
+	OnMemberRemoved(id NamedPortSetID, member Member)
+}
+
+// Resolver tracks the policies, endpoints and profile labels emitted by the Felix syncer and
+// resolves the named ports in the policy rules.
+type Resolver struct {
+	callbacks Callbacks
+
+	// Endpoint and profile label data.
+	endpoints     map[model.Key]*endpointData
+	profileLabels map[string]map[string]string
+
+	// The named port sets, indexed by ID, by owning policy and by port name.
+	sets           map[NamedPortSetID]*setData
+	setsByPolicy   map[model.PolicyKey][]NamedPortSetID
+	setsByPortName map[string]map[NamedPortSetID]bool
+}
+
+type endpointData struct {
+	labels     map[string]string
+	profileIDs []string
+	ips        []string
+	ports      []model.EndpointPort
+}
+
+type setData struct {
+	selector selector.Selector
+	protocol *numorstring.Protocol
+
+	// The members contributed by each endpoint, and a reference count of each member across
+	// all endpoints.
+	endpointMembers map[model.Key][]Member
+	memberRefs      map[Member]int
+}
+
+// New creates a new Resolver which reports named port set changes to the supplied callbacks.
+func New(callbacks Callbacks) *Resolver {
+	return &Resolver{
+		callbacks:      callbacks,
+		endpoints:      map[model.Key]*endpointData{},
+		profileLabels:  map[string]map[string]string{},
+		sets:           map[NamedPortSetID]*setData{},
+		setsByPolicy:   map[model.PolicyKey][]NamedPortSetID{},
+		setsByPortName: map[string]map[NamedPortSetID]bool{},
+	}
+}
+
+// OnUpdates processes a set of updates from the Felix syncer.
+func (r *Resolver) OnUpdates(updates []api.Update) {
+	for _, u := range updates {
+		r.OnUpdate(u)
+	}
+}
+
+// OnUpdate processes a single update from the Felix syncer.  Updates for resource types that
+// are not relevant to named port resolution are ignored.
+func (r *Resolver) OnUpdate(u api.Update) {
+	switch k := u.Key.(type) {
+	case model.PolicyKey:
+		if p, ok := u.Value.(*model.Policy); ok && p != nil {
+			r.updatePolicy(k, p)
+		} else {
+			r.updatePolicy(k, nil)
+		}
+	case model.WorkloadEndpointKey:
+		var ep *endpointData
+		if wep, ok := u.Value.(*model.WorkloadEndpoint); ok && wep != nil {
+			ep = &endpointData{labels: wep.Labels, profileIDs: wep.ProfileIDs, ports: wep.Ports}
+			for _, n := range wep.IPv4Nets {
+				ep.ips = append(ep.ips, n.IP.String())
+			}
+			for _, n := range wep.IPv6Nets {
+				ep.ips = append(ep.ips, n.IP.String())
+			}
+		}
+		r.updateEndpoint(k, ep)
+	case model.HostEndpointKey:
+		var ep *endpointData
+		if hep, ok := u.Value.(*model.HostEndpoint); ok && hep != nil {
+			ep = &endpointData{labels: hep.Labels, profileIDs: hep.ProfileIDs, ports: hep.Ports}
+			for _, ip := range hep.ExpectedIPv4Addrs {
+				ep.ips = append(ep.ips, ip.String())
+			}
+			for _, ip := range hep.ExpectedIPv6Addrs {
+				ep.ips = append(ep.ips, ip.String())
+			}
+		}
+		r.updateEndpoint(k, ep)
+	case model.ProfileLabelsKey:
+		if labels, ok := u.Value.(map[string]string); ok {
+			r.updateProfileLabels(k.Name, labels)
+		} else {
+			r.updateProfileLabels(k.Name, nil)
+		}
+	}
+}
+
+// Members returns the current members of the specified named port set.
+func (r *Resolver) Members(id NamedPortSetID) []Member {
+	s, ok := r.sets[id]
+	if !ok {
+		return nil
+	}
+	members := make([]Member, 0, len(s.memberRefs))
+	for m := range s.memberRefs {
+		members = append(members, m)
+	}
+	return members
+}
+
+// updatePolicy updates the named port sets for the rules in a policy.  Sets that are
+// unchanged are left untouched, all others are removed or recalculated.
+func (r *Resolver) updatePolicy(key model.PolicyKey, policy *model.Policy) {
+	logCxt := log.WithField("policy", key)
+	newSets := map[NamedPortSetID]*setData{}
+	if policy != nil {
+		r.addRuleSets(newSets, key, RuleDirectionInbound, policy.InboundRules)
+		r.addRuleSets(newSets, key, RuleDirectionOutbound, policy.OutboundRules)
+	}
+
+	ids := make([]NamedPortSetID, 0, len(newSets))
+	for id := range newSets {
+		ids = append(ids, id)
+	}
+
+	// Remove any sets that are no longer required or whose match criteria have changed.
+	for _, id := range r.setsByPolicy[key] {
+		if n, ok := newSets[id]; ok && sameMatch(n, r.sets[id]) {
+			delete(newSets, id)
+			continue
+		}
+		logCxt.WithField("set", id).Debug("Removing named port set")
+		r.removeSet(id)
+	}
+
+	// Add any new sets and calculate their members from the current endpoints.
+	for id, s := range newSets {
+		logCxt.WithField("set", id).Debug("Adding named port set")
+		r.addSet(id, s)
+	}
+
+	// Store the IDs of the sets now owned by the policy.
+	if len(ids) == 0 {
+		delete(r.setsByPolicy, key)
+	} else {
+		r.setsByPolicy[key] = ids
+	}
+}
+
+// addRuleSets adds a named port set for each named port in the supplied rules.
+func (r *Resolver) addRuleSets(sets map[NamedPortSetID]*setData, key model.PolicyKey, dir RuleDirection, rules []model.Rule) {
+	for i, rule := range rules {
+		rid := RuleID{Policy: key, Direction: dir, Index: i}
+		fields := []struct {
+			field    PortField
+			ports    []numorstring.Port
+			selector string
+		}{
+			{PortFieldSrcPorts, rule.SrcPorts, rule.SrcSelector},
+			{PortFieldDstPorts, rule.DstPorts, rule.DstSelector},
+			{PortFieldNotSrcPorts, rule.NotSrcPorts, rule.SrcSelector},
+			{PortFieldNotDstPorts, rule.NotDstPorts, rule.DstSelector},
+		}
+		for _, f := range fields {
+			for _, p := range f.ports {
+				if p.PortName == "" {
+					continue
+				}
+				var sel selector.Selector
+				if f.selector != "" {
+					var err error
+					if sel, err = selector.Parse(f.selector); err != nil {
+						log.WithError(err).WithField("rule", rid).Warn("Unable to parse rule selector, ignoring named port")
+						continue
+					}
+				}
+				sets[NamedPortSetID{Rule: rid, Field: f.field, PortName: p.PortName}] = &setData{
+					selector: sel,
+					protocol: rule.Protocol,
+				}
+			}
+		}
+	}
+}
+
+// addSet adds a named port set and calculates its members from the current endpoints.
+func (r *Resolver) addSet(id NamedPortSetID, s *setData) {
+	s.endpointMembers = map[model.Key][]Member{}
+	s.memberRefs = map[Member]int{}
+	r.sets[id] = s
+	if r.setsByPortName[id.PortName] == nil {
+		r.setsByPortName[id.PortName] = map[NamedPortSetID]bool{}
+	}
+	r.setsByPortName[id.PortName][id] = true
+
+	for key, ep := range r.endpoints {
+		r.updateSetMembers(id, s, key, r.calculateMembers(s, id.PortName, ep))
+	}
+}
+
+// removeSet removes a named port set, reporting the removal of each of its members.
+func (r *Resolver) removeSet(id NamedPortSetID) {
+	s := r.sets[id]
+	for m := range s.memberRefs {
+		r.callbacks.OnMemberRemoved(id, m)
+	}
+	delete(r.sets, id)
+	delete(r.setsByPortName[id.PortName], id)
+	if len(r.setsByPortName[id.PortName]) == 0 {
+		delete(r.setsByPortName, id.PortName)
+	}
+}
+
+// updateEndpoint updates the members contributed by an endpoint to each named port set
+// referencing one of the endpoint's (old or new) named ports.
+func (r *Resolver) updateEndpoint(key model.Key, ep *endpointData) {
+	ids := r.setsForPorts(r.endpoints[key], ep)
+	if ep == nil {
+		delete(r.endpoints, key)
+	} else {
+		r.endpoints[key] = ep
+	}
+	for id := range ids {
+		s := r.sets[id]
+		r.updateSetMembers(id, s, key, r.calculateMembers(s, id.PortName, ep))
+	}
+}
+
+// updateProfileLabels updates the labels of a profile and recalculates the members
+// contributed by each endpoint that inherits those labels.
+func (r *Resolver) updateProfileLabels(name string, labels map[string]string) {
+	if labels == nil {
+		delete(r.profileLabels, name)
+	} else {
+		r.profileLabels[name] = labels
+	}
+	for key, ep := range r.endpoints {
+		for _, p := range ep.profileIDs {
+			if p == name {
+				r.updateEndpoint(key, ep)
+				break
+			}
+		}
+	}
+}
+
+// setsForPorts returns the IDs of the named port sets that reference a named port of either
+// of the supplied endpoints.
+func (r *Resolver) setsForPorts(eps ...*endpointData) map[NamedPortSetID]bool {
+	ids := map[NamedPortSetID]bool{}
+	for _, ep := range eps {
+		if ep == nil {
+			continue
+		}
+		for _, p := range ep.ports {
+			for id := range r.setsByPortName[p.Name] {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+// calculateMembers returns the members contributed by an endpoint to a named port set.
+func (r *Resolver) calculateMembers(s *setData, portName string, ep *endpointData) []Member {
+	if ep == nil {
+		return nil
+	}
+	if s.selector != nil && !s.selector.Evaluate(r.endpointLabels(ep)) {
+		return nil
+	}
+	var members []Member
+	for _, p := range ep.ports {
+		if p.Name != portName || (s.protocol != nil && !sameProtocol(*s.protocol, p.Protocol)) {
+			continue
+		}
+		for _, ip := range ep.ips {
+			members = append(members, Member{IP: ip, Protocol: p.Protocol, Port: p.Port})
+		}
+	}
+	return members
+}
+
+// updateSetMembers replaces the members contributed by an endpoint to a named port set, and
+// reports any members that are added to or removed from the set as a result.
+func (r *Resolver) updateSetMembers(id NamedPortSetID, s *setData, key model.Key, members []Member) {
+	old := s.endpointMembers[key]
+	if len(members) == 0 {
+		delete(s.endpointMembers, key)
+	} else {
+		s.endpointMembers[key] = members
+	}
+	for _, m := range members {
+		s.memberRefs[m]++
+		if s.memberRefs[m] == 1 {
+			r.callbacks.OnMemberAdded(id, m)
+		}
+	}
+	for _, m := range old {
+		s.memberRefs[m]--
+		if s.memberRefs[m] == 0 {
+			delete(s.memberRefs, m)
+			r.callbacks.OnMemberRemoved(id, m)
+		}
+	}
+}
+
+// endpointLabels returns the labels of an endpoint, including those inherited from its
+// profiles.  Labels on the endpoint take precedence over inherited labels, and labels from
+// earlier profiles take precedence over those from later profiles.
+func (r *Resolver) endpointLabels(ep *endpointData) map[string]string {
+	labels := map[string]string{}
+	for i := len(ep.profileIDs) - 1; i >= 0; i-- {
+		for k, v := range r.profileLabels[ep.profileIDs[i]] {
+			labels[k] = v
+		}
+	}
+	for k, v := range ep.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// sameMatch returns true if the two named port sets have the same match criteria.
+func sameMatch(a, b *setData) bool {
+	if (a.selector == nil) != (b.selector == nil) {
+		return false
+	}
+	if a.selector != nil && a.selector.UniqueID() != b.selector.UniqueID() {
+		return false
+	}
+	if (a.protocol == nil) != (b.protocol == nil) {
+		return false
+	}
+	return a.protocol == nil || sameProtocol(*a.protocol, *b.protocol)
+}
+
+// sameProtocol returns true if the two protocols are the same, regardless of whether they
+// are specified by name or number.
+func sameProtocol(a, b numorstring.Protocol) bool {
+	return protocolNumber(a) == protocolNumber(b)
+}
+
+func protocolNumber(p numorstring.Protocol) string {
+	if n, err := p.NumValue(); err == nil {
+		return fmt.Sprint(n)
+	}
+	switch strings.ToLower(p.StrVal) {
+	case "tcp":
+		return "6"
+	case "udp":
+		return "17"
+	}
+	return strings.ToLower(p.StrVal)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namedport_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/namedport"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+// recorder implements the namedport.Callbacks interface, tracking the current members of
+// each named port set.
+type recorder struct {
+	sets map[namedport.NamedPortSetID]map[namedport.Member]bool
+}
+
+func (r *recorder) OnMemberAdded(id namedport.NamedPortSetID, m namedport.Member) {
+	if r.sets[id] == nil {
+		r.sets[id] = map[namedport.Member]bool{}
+	}
+	Expect(r.sets[id]).NotTo(HaveKey(m))
+	r.sets[id][m] = true
+}
+
+func (r *recorder) OnMemberRemoved(id namedport.NamedPortSetID, m namedport.Member) {
+	Expect(r.sets[id]).To(HaveKey(m))
+	delete(r.sets[id], m)
+	if len(r.sets[id]) == 0 {
+		delete(r.sets, id)
+	}
+}
+
+var (
+	tcp = numorstring.ProtocolFromString("tcp")
+	udp = numorstring.ProtocolFromString("udp")
+
+	policyKey = model.PolicyKey{Tier: "default", Name: "policy1"}
+	wepKey1   = model.WorkloadEndpointKey{Hostname: "host1", OrchestratorID: "k8s", WorkloadID: "ns/pod1", EndpointID: "eth0"}
+	wepKey2   = model.WorkloadEndpointKey{Hostname: "host1", OrchestratorID: "k8s", WorkloadID: "ns/pod2", EndpointID: "eth0"}
+	hepKey1   = model.HostEndpointKey{Hostname: "host1", EndpointID: "eth0"}
+
+	dstHTTP = namedport.NamedPortSetID{
+		Rule:     namedport.RuleID{Policy: policyKey, Direction: namedport.RuleDirectionInbound, Index: 0},
+		Field:    namedport.PortFieldDstPorts,
+		PortName: "http",
+	}
+)
+
+func policyUpdate(rules ...model.Rule) api.Update {
+	return api.Update{
+		KVPair: model.KVPair{
+			Key:   policyKey,
+			Value: &model.Policy{Selector: "all()", InboundRules: rules},
+		},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func wepUpdate(key model.WorkloadEndpointKey, ip string, labels map[string]string, ports ...model.EndpointPort) api.Update {
+	return api.Update{
+		KVPair: model.KVPair{
+			Key: key,
+			Value: &model.WorkloadEndpoint{
+				IPv4Nets:   []net.IPNet{net.MustParseNetwork(ip + "/32")},
+				Labels:     labels,
+				ProfileIDs: []string{"profile1"},
+				Ports:      ports,
+			},
+		},
+		UpdateType: api.UpdateTypeKVNew,
+	}
+}
+
+func deleteUpdate(key model.Key) api.Update {
+	return api.Update{
+		KVPair:     model.KVPair{Key: key},
+		UpdateType: api.UpdateTypeKVDeleted,
+	}
+}
+
+var _ = Describe("Named port resolver", func() {
+	var r *recorder
+	var res *namedport.Resolver
+
+	httpPort := model.EndpointPort{Name: "http", Protocol: tcp, Port: 8080}
+	httpRule := model.Rule{
+		Action:      "allow",
+		Protocol:    &tcp,
+		DstSelector: "app == 'web'",
+		DstPorts:    []numorstring.Port{numorstring.NamedPort("http")},
+	}
+
+	BeforeEach(func() {
+		r = &recorder{sets: map[namedport.NamedPortSetID]map[namedport.Member]bool{}}
+		res = namedport.New(r)
+	})
+
+	It("should resolve a named port for endpoints added after the policy", func() {
+		res.OnUpdate(policyUpdate(httpRule))
+		Expect(r.sets).To(BeEmpty())
+
+		res.OnUpdates([]api.Update{
+			wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort),
+			wepUpdate(wepKey2, "10.0.0.2", map[string]string{"app": "db"}, httpPort),
+		})
+		Expect(r.sets).To(Equal(map[namedport.NamedPortSetID]map[namedport.Member]bool{
+			dstHTTP: {{IP: "10.0.0.1", Protocol: tcp, Port: 8080}: true},
+		}))
+		Expect(res.Members(dstHTTP)).To(ConsistOf(namedport.Member{IP: "10.0.0.1", Protocol: tcp, Port: 8080}))
+	})
+
+	It("should resolve a named port for endpoints added before the policy", func() {
+		res.OnUpdate(wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort))
+		Expect(r.sets).To(BeEmpty())
+
+		res.OnUpdate(policyUpdate(httpRule))
+		Expect(r.sets[dstHTTP]).To(Equal(map[namedport.Member]bool{
+			{IP: "10.0.0.1", Protocol: tcp, Port: 8080}: true,
+		}))
+	})
+
+	It("should update the members as endpoints change", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(httpRule),
+			wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort),
+		})
+
+		By("changing the port number")
+		res.OnUpdate(wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"},
+			model.EndpointPort{Name: "http", Protocol: tcp, Port: 80}))
+		Expect(r.sets[dstHTTP]).To(Equal(map[namedport.Member]bool{
+			{IP: "10.0.0.1", Protocol: tcp, Port: 80}: true,
+		}))
+
+		By("changing the labels so the endpoint no longer matches")
+		res.OnUpdate(wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "db"}, httpPort))
+		Expect(r.sets).To(BeEmpty())
+
+		By("changing the labels so the endpoint matches again")
+		res.OnUpdate(wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort))
+		Expect(r.sets).To(HaveLen(1))
+
+		By("deleting the endpoint")
+		res.OnUpdate(deleteUpdate(wepKey1))
+		Expect(r.sets).To(BeEmpty())
+		Expect(res.Members(dstHTTP)).To(BeEmpty())
+	})
+
+	It("should only include ports with a matching protocol", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(httpRule),
+			wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"},
+				model.EndpointPort{Name: "http", Protocol: udp, Port: 8080},
+				model.EndpointPort{Name: "http", Protocol: numorstring.ProtocolFromInt(6), Port: 8081},
+			),
+		})
+		Expect(r.sets[dstHTTP]).To(Equal(map[namedport.Member]bool{
+			{IP: "10.0.0.1", Protocol: numorstring.ProtocolFromInt(6), Port: 8081}: true,
+		}))
+	})
+
+	It("should match on labels inherited from profiles", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(httpRule),
+			wepUpdate(wepKey1, "10.0.0.1", nil, httpPort),
+		})
+		Expect(r.sets).To(BeEmpty())
+
+		res.OnUpdate(api.Update{
+			KVPair: model.KVPair{
+				Key:   model.ProfileLabelsKey{ProfileKey: model.ProfileKey{Name: "profile1"}},
+				Value: map[string]string{"app": "web"},
+			},
+			UpdateType: api.UpdateTypeKVNew,
+		})
+		Expect(r.sets).To(HaveLen(1))
+
+		res.OnUpdate(deleteUpdate(model.ProfileLabelsKey{ProfileKey: model.ProfileKey{Name: "profile1"}}))
+		Expect(r.sets).To(BeEmpty())
+	})
+
+	It("should resolve named ports on host endpoints", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(model.Rule{
+				Action:      "allow",
+				SrcPorts:    []numorstring.Port{numorstring.NamedPort("ssh")},
+				NotDstPorts: []numorstring.Port{numorstring.NamedPort("ssh")},
+			}),
+			{
+				KVPair: model.KVPair{
+					Key: hepKey1,
+					Value: &model.HostEndpoint{
+						ExpectedIPv4Addrs: []net.IP{net.MustParseIP("10.0.0.10")},
+						ExpectedIPv6Addrs: []net.IP{net.MustParseIP("fd00::10")},
+						Ports:             []model.EndpointPort{{Name: "ssh", Protocol: tcp, Port: 22}},
+					},
+				},
+				UpdateType: api.UpdateTypeKVNew,
+			},
+		})
+		rid := namedport.RuleID{Policy: policyKey, Direction: namedport.RuleDirectionInbound, Index: 0}
+		expected := map[namedport.Member]bool{
+			{IP: "10.0.0.10", Protocol: tcp, Port: 22}: true,
+			{IP: "fd00::10", Protocol: tcp, Port: 22}:  true,
+		}
+		Expect(r.sets).To(Equal(map[namedport.NamedPortSetID]map[namedport.Member]bool{
+			{Rule: rid, Field: namedport.PortFieldSrcPorts, PortName: "ssh"}:    expected,
+			{Rule: rid, Field: namedport.PortFieldNotDstPorts, PortName: "ssh"}: expected,
+		}))
+	})
+
+	It("should count members provided by more than one endpoint", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(httpRule),
+			wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort),
+			wepUpdate(wepKey2, "10.0.0.1", map[string]string{"app": "web"}, httpPort),
+		})
+		Expect(r.sets[dstHTTP]).To(HaveLen(1))
+
+		res.OnUpdate(deleteUpdate(wepKey1))
+		Expect(r.sets[dstHTTP]).To(HaveLen(1))
+
+		res.OnUpdate(deleteUpdate(wepKey2))
+		Expect(r.sets).To(BeEmpty())
+	})
+
+	It("should update the sets as the policy changes", func() {
+		res.OnUpdates([]api.Update{
+			policyUpdate(httpRule),
+			wepUpdate(wepKey1, "10.0.0.1", map[string]string{"app": "web"}, httpPort),
+			wepUpdate(wepKey2, "10.0.0.2", map[string]string{"app": "db"}, httpPort),
+		})
+		Expect(r.sets[dstHTTP]).To(HaveLen(1))
+
+		By("changing the rule selector")
+		rule := httpRule
+		rule.DstSelector = "has(app)"
+		res.OnUpdate(policyUpdate(rule))
+		Expect(r.sets[dstHTTP]).To(HaveLen(2))
+
+		By("re-sending the same policy")
+		res.OnUpdate(policyUpdate(rule))
+		Expect(r.sets[dstHTTP]).To(HaveLen(2))
+
+		By("deleting the policy")
+		res.OnUpdate(deleteUpdate(policyKey))
+		Expect(r.sets).To(BeEmpty())
+		Expect(res.Members(dstHTTP)).To(BeNil())
+	})
+})
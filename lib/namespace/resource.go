@@ -16,9 +16,17 @@ package namespace
 
 import apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 
+const (
+	// The Kubernetes resource kinds that are read directly from the Kubernetes API when
+	// using the Kubernetes datastore.
+	KindKubernetesService   = "KubernetesService"
+	KindKubernetesEndpoints = "KubernetesEndpoints"
+)
+
 func IsNamespaced(kind string) bool {
 	switch kind {
-	case apiv2.KindWorkloadEndpoint, apiv2.KindNetworkPolicy:
+	case apiv2.KindWorkloadEndpoint, apiv2.KindNetworkPolicy, apiv2.KindStagedNetworkPolicy, apiv2.KindNetworkSet,
+		KindKubernetesService, KindKubernetesEndpoints:
 		return true
 	default:
 		return false
@@ -21,4 +21,9 @@ type DeleteOptions struct {
 	// - if set to non zero, then the result is at least as fresh as given rv.
 	// +optional
 	ResourceVersion string
+
+	// When set, deletes a resource even though it is still in use, for example an
+	// IPPool that still has addresses allocated from it.
+	// +optional
+	Force bool
 }
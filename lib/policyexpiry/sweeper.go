@@ -0,0 +1,209 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policyexpiry provides a sweeper that removes expired policies and rules from the
+// datastore.
+package policyexpiry
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// Sweeper deletes GlobalNetworkPolicy and NetworkPolicy resources that have expired, and removes
+// expired rules from the policies that have not.
+//
+// Felix only checks whether a policy or rule has expired when it processes an update to the
+// policy, so a Sweeper must be running for expiry to take effect.  Deleting or updating the
+// policy at its expiry time triggers that processing, and also keeps the datastore tidy.
+type Sweeper struct {
+	client   clientv2.Interface
+	interval time.Duration
+	clock    clock.Clock
+}
+
+// NewSweeper creates a new Sweeper that sweeps the datastore at the specified interval.
+func NewSweeper(client clientv2.Interface, interval time.Duration) *Sweeper {
+	return NewSweeperWithClock(client, interval, clock.RealClock{})
+}
+
+// NewSweeperWithClock creates a new Sweeper that sweeps the datastore at the specified interval,
+// using the supplied clock to determine the sweep times and whether a policy or rule has expired.
+func NewSweeperWithClock(client clientv2.Interface, interval time.Duration, clk clock.Clock) *Sweeper {
+	return &Sweeper{
+		client:   client,
+		interval: interval,
+		clock:    clk,
+	}
+}
+
+// Run sweeps the datastore immediately, and then again at the earlier of the configured interval
+// and the next time a policy or rule is due to expire, until the context is cancelled.  Errors
+// are logged and the sweep is retried at the next interval.
+func (s *Sweeper) Run(ctx context.Context) {
+	for {
+		next, err := s.sweep(ctx)
+		if err != nil {
+			log.WithError(err).Warning("Failed to sweep expired policies")
+		}
+		wait := s.interval
+		if !next.IsZero() {
+			if untilNext := next.Sub(s.clock.Now()); untilNext < wait {
+				wait = untilNext
+			}
+		}
+		select {
+		case <-ctx.Done():
+			log.Info("Policy expiry sweeper exiting")
+			return
+		case <-s.clock.After(wait):
+		}
+	}
+}
+
+// Sweep performs a single sweep of the datastore.  The sweep continues if an individual policy
+// cannot be updated or deleted, and returns the first error encountered.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	_, err := s.sweep(ctx)
+	return err
+}
+
+// sweep performs a single sweep of the datastore, returning the next time that one of the
+// remaining policies or rules is due to expire, or the zero time if none are.
+//
+// Only the expired rules are removed from a policy - the policy types are left unchanged, so a
+// policy whose rules have all expired continues to isolate the endpoints it selects.  A policy is
+// only deleted when the policy itself has expired.
+func (s *Sweeper) sweep(ctx context.Context) (time.Time, error) {
+	now := s.clock.Now()
+	var next time.Time
+	var firstErr error
+	recordErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	recordExpiry := func(policyExpiresAt *metav1.Time, ingress, egress []apiv2.Rule) {
+		for _, expiresAt := range append(ruleExpiries(ingress, egress), policyExpiresAt) {
+			if expiresAt != nil && (next.IsZero() || expiresAt.Time.Before(next)) {
+				next = expiresAt.Time
+			}
+		}
+	}
+
+	gnps, err := s.client.GlobalNetworkPolicies().List(ctx, options.ListOptions{})
+	if err != nil {
+		return next, err
+	}
+	for i := range gnps.Items {
+		gnp := &gnps.Items[i]
+		logCxt := log.WithField("GlobalNetworkPolicy", gnp.Name)
+		if isExpired(gnp.Spec.ExpiresAt, now) {
+			logCxt.Info("Deleting expired policy")
+			_, err = s.client.GlobalNetworkPolicies().Delete(ctx, gnp.Name, options.DeleteOptions{ResourceVersion: gnp.ResourceVersion})
+		} else {
+			removed := removeExpiredRules(&gnp.Spec.IngressRules, &gnp.Spec.EgressRules, now)
+			recordExpiry(gnp.Spec.ExpiresAt, gnp.Spec.IngressRules, gnp.Spec.EgressRules)
+			if !removed {
+				continue
+			}
+			logCxt.Info("Removing expired rules from policy")
+			_, err = s.client.GlobalNetworkPolicies().Update(ctx, gnp, options.SetOptions{})
+		}
+		if err != nil && !isIgnorable(err) {
+			logCxt.WithError(err).Warning("Failed to sweep policy")
+			recordErr(err)
+		}
+	}
+
+	nps, err := s.client.NetworkPolicies().List(ctx, options.ListOptions{})
+	if err != nil {
+		return next, err
+	}
+	for i := range nps.Items {
+		np := &nps.Items[i]
+		logCxt := log.WithFields(log.Fields{"NetworkPolicy": np.Name, "Namespace": np.Namespace})
+		if isExpired(np.Spec.ExpiresAt, now) {
+			logCxt.Info("Deleting expired policy")
+			_, err = s.client.NetworkPolicies().Delete(ctx, np.Namespace, np.Name, options.DeleteOptions{ResourceVersion: np.ResourceVersion})
+		} else {
+			removed := removeExpiredRules(&np.Spec.IngressRules, &np.Spec.EgressRules, now)
+			recordExpiry(np.Spec.ExpiresAt, np.Spec.IngressRules, np.Spec.EgressRules)
+			if !removed {
+				continue
+			}
+			logCxt.Info("Removing expired rules from policy")
+			_, err = s.client.NetworkPolicies().Update(ctx, np, options.SetOptions{})
+		}
+		if err != nil && !isIgnorable(err) {
+			logCxt.WithError(err).Warning("Failed to sweep policy")
+			recordErr(err)
+		}
+	}
+
+	return next, firstErr
+}
+
+// isExpired returns true if an expiry time is set and the expiry time has been reached.
+func isExpired(expiresAt *metav1.Time, now time.Time) bool {
+	return expiresAt != nil && !now.Before(expiresAt.Time)
+}
+
+// ruleExpiries returns the expiry times of the supplied rules.
+func ruleExpiries(ingress, egress []apiv2.Rule) []*metav1.Time {
+	var expiries []*metav1.Time
+	for _, rules := range [][]apiv2.Rule{ingress, egress} {
+		for _, r := range rules {
+			expiries = append(expiries, r.ExpiresAt)
+		}
+	}
+	return expiries
+}
+
+// removeExpiredRules removes the expired rules from the supplied ingress and egress rules.
+// Returns whether any rules were removed.
+func removeExpiredRules(ingress, egress *[]apiv2.Rule, now time.Time) bool {
+	removed := false
+	for _, rules := range []*[]apiv2.Rule{ingress, egress} {
+		var filtered []apiv2.Rule
+		for _, r := range *rules {
+			if isExpired(r.ExpiresAt, now) {
+				removed = true
+				continue
+			}
+			filtered = append(filtered, r)
+		}
+		*rules = filtered
+	}
+	return removed
+}
+
+// isIgnorable returns true if the error indicates that the policy was deleted or modified since
+// it was listed.  The policy will be checked again on the next sweep.
+func isIgnorable(err error) bool {
+	switch err.(type) {
+	case cerrors.ErrorResourceDoesNotExist, cerrors.ErrorResourceUpdateConflict:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2017 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyexpiry_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/clientv2"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/policyexpiry"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+var _ = testutils.E2eDatastoreDescribe("Policy expiry sweeper tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt1 := metav1.NewTime(now.Add(time.Minute))
+	expiresAt2 := metav1.NewTime(now.Add(time.Hour))
+	var c clientv2.Interface
+	var clk *clock.FakeClock
+
+	BeforeEach(func() {
+		var err error
+		c, err = clientv2.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		clk = clock.NewFakeClock(now)
+
+		By("Creating policies with expiring rules and expiring policies")
+		_, err = c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "gnp-1"},
+			Spec: apiv2.GlobalNetworkPolicySpec{
+				Selector: "all()",
+				IngressRules: []apiv2.Rule{
+					{Action: apiv2.Allow, ExpiresAt: &expiresAt1},
+					{Action: apiv2.Deny},
+				},
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "gnp-2"},
+			Spec: apiv2.GlobalNetworkPolicySpec{
+				Selector:  "all()",
+				ExpiresAt: &expiresAt2,
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.NetworkPolicies().Create(ctx, &apiv2.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "np-1", Namespace: "namespace-1"},
+			Spec: apiv2.NetworkPolicySpec{
+				Selector:  "all()",
+				ExpiresAt: &expiresAt1,
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove expired rules and delete expired policies", func() {
+		s := policyexpiry.NewSweeperWithClock(c, time.Minute, clk)
+
+		By("Sweeping before anything has expired")
+		Expect(s.Sweep(ctx)).NotTo(HaveOccurred())
+		gnp, err := c.GlobalNetworkPolicies().Get(ctx, "gnp-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.IngressRules).To(HaveLen(2))
+		_, err = c.NetworkPolicies().Get(ctx, "namespace-1", "np-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Sweeping after the first expiry time")
+		clk.Step(time.Minute)
+		Expect(s.Sweep(ctx)).NotTo(HaveOccurred())
+		gnp, err = c.GlobalNetworkPolicies().Get(ctx, "gnp-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.IngressRules).To(Equal([]apiv2.Rule{{Action: apiv2.Deny}}))
+		_, err = c.GlobalNetworkPolicies().Get(ctx, "gnp-2", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.NetworkPolicies().Get(ctx, "namespace-1", "np-1", options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		By("Sweeping after the second expiry time")
+		clk.Step(time.Hour)
+		Expect(s.Sweep(ctx)).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Get(ctx, "gnp-2", options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+		gnps, err := c.GlobalNetworkPolicies().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnps.Items).To(HaveLen(1))
+	})
+
+	It("should sweep at the configured interval when running", func() {
+		s := policyexpiry.NewSweeperWithClock(c, time.Hour, clk)
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.Run(runCtx)
+		}()
+
+		By("Waiting for the initial sweep to complete")
+		Eventually(clk.HasWaiters).Should(BeTrue())
+		_, err := c.NetworkPolicies().Get(ctx, "namespace-1", "np-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Advancing the clock to the next sweep")
+		clk.Step(time.Hour)
+		Eventually(func() error {
+			_, err := c.NetworkPolicies().Get(ctx, "namespace-1", "np-1", options.GetOptions{})
+			return err
+		}).Should(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should keep the policy and its types when the last rule in a direction expires", func() {
+		s := policyexpiry.NewSweeperWithClock(c, time.Hour, clk)
+
+		By("Creating a policy whose only ingress rule expires before its only egress rule")
+		_, err := c.GlobalNetworkPolicies().Create(ctx, &apiv2.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "gnp-3"},
+			Spec: apiv2.GlobalNetworkPolicySpec{
+				Selector:     "all()",
+				IngressRules: []apiv2.Rule{{Action: apiv2.Allow, ExpiresAt: &expiresAt1}},
+				EgressRules:  []apiv2.Rule{{Action: apiv2.Allow, ExpiresAt: &expiresAt2}},
+			},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		types := []apiv2.PolicyType{apiv2.PolicyTypeIngress, apiv2.PolicyTypeEgress}
+
+		By("Sweeping after the ingress rule has expired")
+		clk.Step(time.Minute)
+		Expect(s.Sweep(ctx)).NotTo(HaveOccurred())
+		gnp, err := c.GlobalNetworkPolicies().Get(ctx, "gnp-3", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.IngressRules).To(BeEmpty())
+		Expect(gnp.Spec.EgressRules).To(HaveLen(1))
+		Expect(gnp.Spec.Types).To(Equal(types))
+
+		By("Sweeping after the egress rule has expired")
+		clk.Step(time.Hour)
+		Expect(s.Sweep(ctx)).NotTo(HaveOccurred())
+		gnp, err = c.GlobalNetworkPolicies().Get(ctx, "gnp-3", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gnp.Spec.IngressRules).To(BeEmpty())
+		Expect(gnp.Spec.EgressRules).To(BeEmpty())
+		Expect(gnp.Spec.Types).To(Equal(types))
+	})
+
+	It("should sweep at the next expiry time if that is before the interval", func() {
+		s := policyexpiry.NewSweeperWithClock(c, 24*time.Hour, clk)
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.Run(runCtx)
+		}()
+
+		By("Waiting for the initial sweep to complete")
+		Eventually(clk.HasWaiters).Should(BeTrue())
+
+		By("Advancing the clock to the first expiry time")
+		clk.Step(time.Minute)
+		Eventually(func() error {
+			_, err := c.NetworkPolicies().Get(ctx, "namespace-1", "np-1", options.GetOptions{})
+			return err
+		}).Should(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+		Eventually(func() int {
+			gnp, err := c.GlobalNetworkPolicies().Get(ctx, "gnp-1", options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			return len(gnp.Spec.IngressRules)
+		}).Should(Equal(1))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
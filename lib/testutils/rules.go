@@ -24,6 +24,7 @@ var ipv4 = 4
 var ipv6 = 6
 var strProtocol1 = numorstring.ProtocolFromString("icmp")
 var strProtocol2 = numorstring.ProtocolFromString("udp")
+var strProtocol3 = numorstring.ProtocolFromString("tcp")
 var numProtocol1 = numorstring.ProtocolFromInt(240)
 
 var portRange, singlePort, namedPort numorstring.Port
@@ -78,6 +79,21 @@ var InRule2 = apiv2.Rule{
 	},
 }
 
+var InRuleHTTP = apiv2.Rule{
+	Action:   "allow",
+	Protocol: &strProtocol3,
+	Source: apiv2.EntityRule{
+		Selector: "label1 == 'value1'",
+	},
+	HTTP: &apiv2.HTTPMatch{
+		Methods: []string{"GET", "PUT"},
+		Paths: []apiv2.HTTPPath{
+			{Exact: "/foo"},
+			{Prefix: "/bar/"},
+		},
+	},
+}
+
 var EgressRule1 = apiv2.Rule{
 	Action:    "pass",
 	IPVersion: &ipv4,
@@ -18,9 +18,11 @@ import (
 	"net"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/errors"
 	calinet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
@@ -45,6 +47,19 @@ var (
 	backendActionRegex  = regexp.MustCompile("^(allow|deny|log|next-tier|)$")
 	protocolRegex       = regexp.MustCompile("^(tcp|udp|icmp|icmpv6|sctp|udplite)$")
 	ipipModeRegex       = regexp.MustCompile("^(always|cross-subnet|)$")
+	vxlanModeRegex      = regexp.MustCompile("^(Always|CrossSubnet|Never)$")
+	httpMethodRegex     = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+.^_|~-]+$")
+	httpPathRegex       = regexp.MustCompile(`^/[^\s?#]*$`)
+	logPrefixRegex      = regexp.MustCompile(`^[a-zA-Z0-9_.:/ -]{1,29}$`)
+	logRateUnitRegex    = regexp.MustCompile("^(Second|Minute)$")
+	backendLogUnitRegex = regexp.MustCompile("^(second|minute)$")
+	bgpFilterOpRegex    = regexp.MustCompile("^(Equal|NotEqual|In|NotIn)$")
+	bgpFilterActRegex   = regexp.MustCompile("^(Accept|Reject)$")
+	bgpSessionRegex     = regexp.MustCompile("^(Idle|Connect|Active|OpenSent|OpenConfirm|Established)$")
+	dsSyncStatusRegex   = regexp.MustCompile("^(WaitForDatastore|ResyncInProgress|InSync)$")
+	stagedActionRegex   = regexp.MustCompile("^(Set|Delete)$")
+	allocStrategyRegex  = regexp.MustCompile("^(RandomBlock|SequentialBlock|LRUAddress)$")
+	communityRegex      = regexp.MustCompile(`^(\d+):(\d+)(?::(\d+))?$`)
 	reasonString        = "Reason: "
 	poolSmallIPv4       = "IP pool size is too small (min /26) for use with Calico IPAM"
 	poolSmallIPv6       = "IP pool size is too small (min /122) for use with Calico IPAM"
@@ -52,6 +67,14 @@ var (
 	overlapsV4LinkLocal = "IP pool range overlaps with IPv4 Link Local range 169.254.0.0/16"
 	overlapsV6LinkLocal = "IP pool range overlaps with IPv6 Link Local range fe80::/10"
 	protocolPortsMsg    = "rules that specify ports must set protocol to TCP or UDP"
+	protocolHTTPMsg     = "rules that specify HTTP match criteria must set protocol to TCP (or leave it unset)"
+	logOptionsActionMsg = "rules that specify log options must use the Log action"
+	logBurstMsg         = "log burst may only be specified with a log rate limit"
+	ipipAndVXLANMsg     = "IPIP and VXLAN encapsulation cannot both be enabled on an IP pool"
+	peerNodeMsg         = "node and node selector cannot both be specified"
+	peerSelectorMsg     = "peer IP and AS number cannot be specified with a peer selector"
+	peerIPMsg           = "peer IP must be specified when a peer selector is not specified"
+	prefixCommunityMsg  = "community must be a standard or large community value, or the name of a community"
 
 	ipv4LinkLocalNet = net.IPNet{
 		IP:   net.ParseIP("169.254.0.0"),
@@ -101,7 +124,21 @@ func init() {
 	registerFieldValidator("scopeglobalornode", validateScopeGlobalOrNode)
 	registerFieldValidator("ipversion", validateIPVersion)
 	registerFieldValidator("ipipmode", validateIPIPMode)
+	registerFieldValidator("vxlanmode", validateVXLANMode)
 	registerFieldValidator("policytype", validatePolicyType)
+	registerFieldValidator("httpmethod", validateHTTPMethod)
+	registerFieldValidator("httppath", validateHTTPPath)
+	registerFieldValidator("logprefix", validateLogPrefix)
+	registerFieldValidator("lograteunit", validateLogRateUnit)
+	registerFieldValidator("backendlograteunit", validateBackendLogRateUnit)
+	registerFieldValidator("bgpcommunity", validateBGPCommunity)
+	registerFieldValidator("bgpfiltermatchoperator", validateBGPFilterMatchOperator)
+	registerFieldValidator("bgpfilteraction", validateBGPFilterAction)
+	registerFieldValidator("bgpsessionstate", validateBGPSessionState)
+	registerFieldValidator("datastoresyncstatus", validateDatastoreSyncStatus)
+	registerFieldValidator("stagedaction", validateStagedAction)
+	registerFieldValidator("cidrorip", validateCIDROrIP)
+	registerFieldValidator("allocationstrategy", validateAllocationStrategy)
 
 	// Register struct validators.
 	// Shared types.
@@ -120,8 +157,17 @@ func init() {
 	registerStructValidator(validateBGPPeerMeta, api.BGPPeerMetadata{})
 	registerStructValidator(validatePolicySpec, api.PolicySpec{})
 
+	// v2 API types.
+	registerStructValidator(validateRuleV2, apiv2.Rule{})
+	registerStructValidator(validateHTTPPathV2, apiv2.HTTPPath{})
+	registerStructValidator(validateLogOptionsV2, apiv2.LogOptions{})
+	registerStructValidator(validateIPPoolSpecV2, apiv2.IPPoolSpec{})
+	registerStructValidator(validateBGPPeerSpecV2, apiv2.BGPPeerSpec{})
+	registerStructValidator(validateBGPConfigurationSpecV2, apiv2.BGPConfigurationSpec{})
+
 	// Backend model types.
 	registerStructValidator(validateBackendRule, model.Rule{})
+	registerStructValidator(validateBackendHTTPPath, model.HTTPPath{})
 	registerStructValidator(validateBackendEndpointPort, model.EndpointPort{})
 }
 
@@ -191,6 +237,83 @@ func validateIPIPMode(v *validator.Validate, topStruct reflect.Value, currentStr
 	return ipipModeRegex.MatchString(s)
 }
 
+func validateVXLANMode(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate VXLAN mode: %s", s)
+	return vxlanModeRegex.MatchString(s)
+}
+
+func validateBGPCommunity(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate BGP community: %s", s)
+	return isValidBGPCommunity(s)
+}
+
+func validateBGPFilterMatchOperator(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate BGP filter match operator: %s", s)
+	return bgpFilterOpRegex.MatchString(s)
+}
+
+func validateBGPFilterAction(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate BGP filter action: %s", s)
+	return bgpFilterActRegex.MatchString(s)
+}
+
+func validateBGPSessionState(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate BGP session state: %s", s)
+	return bgpSessionRegex.MatchString(s)
+}
+
+func validateDatastoreSyncStatus(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate datastore sync status: %s", s)
+	return dsSyncStatusRegex.MatchString(s)
+}
+
+func validateStagedAction(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate staged action: %s", s)
+	return stagedActionRegex.MatchString(s)
+}
+
+func validateAllocationStrategy(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate allocation strategy: %s", s)
+	return allocStrategyRegex.MatchString(s)
+}
+
+func validateCIDROrIP(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate CIDR or IP: %s", s)
+	_, _, err := calinet.ParseCIDROrIP(s)
+	return err == nil
+}
+
+// isValidBGPCommunity returns true if the value is a standard community "aa:nn", where each
+// part is a 16-bit value, or a large community "aa:nn:mm", where each part is a 32-bit value.
+func isValidBGPCommunity(s string) bool {
+	m := communityRegex.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	bits := 16
+	if m[3] != "" {
+		bits = 32
+	}
+	for _, p := range m[1:] {
+		if p == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(p, 10, bits); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func validateSelector(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
 	s := field.String()
 	log.Debugf("Validate selector: %s", s)
@@ -239,6 +362,36 @@ func validatePolicyType(v *validator.Validate, topStruct reflect.Value, currentS
 	return false
 }
 
+func validateHTTPMethod(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate HTTP method: %s", s)
+	return httpMethodRegex.MatchString(s)
+}
+
+func validateHTTPPath(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate HTTP path: %s", s)
+	return httpPathRegex.MatchString(s)
+}
+
+func validateLogPrefix(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate log prefix: %s", s)
+	return logPrefixRegex.MatchString(s)
+}
+
+func validateLogRateUnit(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate log rate limit unit: %s", s)
+	return logRateUnitRegex.MatchString(s)
+}
+
+func validateBackendLogRateUnit(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	s := field.String()
+	log.Debugf("Validate backend log rate limit unit: %s", s)
+	return backendLogUnitRegex.MatchString(s)
+}
+
 func validateProtocol(v *validator.Validate, structLevel *validator.StructLevel) {
 	p := structLevel.CurrentStruct.Interface().(numorstring.Protocol)
 	log.Debugf("Validate protocol: %v %s %d", p.Type, p.StrVal, p.NumVal)
@@ -500,6 +653,131 @@ func validateBackendRule(v *validator.Validate, structLevel *validator.StructLev
 				"NotDstPorts", "", reason(protocolPortsMsg))
 		}
 	}
+
+	// HTTP match criteria are only valid for TCP traffic.
+	if rule.HTTPMatch != nil && rule.Protocol != nil && !isProtocolTCP(*rule.Protocol) {
+		structLevel.ReportError(reflect.ValueOf(rule.HTTPMatch),
+			"HTTPMatch", "", reason(protocolHTTPMsg))
+	}
+
+	// Log options are only valid for the log action.
+	if rule.Action != "log" {
+		if rule.LogPrefix != "" {
+			structLevel.ReportError(reflect.ValueOf(rule.LogPrefix),
+				"LogPrefix", "", reason(logOptionsActionMsg))
+		}
+		if rule.LogRateLimit != nil {
+			structLevel.ReportError(reflect.ValueOf(rule.LogRateLimit),
+				"LogRateLimit", "", reason(logOptionsActionMsg))
+		}
+	}
+}
+
+func validateRuleV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	rule := structLevel.CurrentStruct.Interface().(apiv2.Rule)
+
+	// HTTP match criteria are only valid for TCP traffic.
+	if rule.HTTP != nil && rule.Protocol != nil && !isProtocolTCP(*rule.Protocol) {
+		structLevel.ReportError(reflect.ValueOf(rule.HTTP),
+			"HTTP", "", reason(protocolHTTPMsg))
+	}
+
+	// Log options are only valid for the Log action.
+	if rule.LogOptions != nil && strings.ToLower(string(rule.Action)) != "log" {
+		structLevel.ReportError(reflect.ValueOf(rule.LogOptions),
+			"LogOptions", "", reason(logOptionsActionMsg))
+	}
+}
+
+func validateLogOptionsV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	o := structLevel.CurrentStruct.Interface().(apiv2.LogOptions)
+
+	// A burst is only meaningful with a rate limit.
+	if o.Burst != nil && o.RateLimit == nil {
+		structLevel.ReportError(reflect.ValueOf(o.Burst),
+			"Burst", "", reason(logBurstMsg))
+	}
+}
+
+func validateIPPoolSpecV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	spec := structLevel.CurrentStruct.Interface().(apiv2.IPPoolSpec)
+
+	// IPIP and VXLAN are alternative encapsulations, at most one may be enabled.
+	ipipEnabled := spec.IPIPMode != "" && spec.IPIPMode != apiv2.IPIPModeNever
+	vxlanEnabled := spec.VXLANMode != "" && spec.VXLANMode != apiv2.VXLANModeNever
+	if ipipEnabled && vxlanEnabled {
+		structLevel.ReportError(reflect.ValueOf(spec.VXLANMode),
+			"VXLANMode", "", reason(ipipAndVXLANMsg))
+	}
+}
+
+func validateBGPPeerSpecV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	spec := structLevel.CurrentStruct.Interface().(apiv2.BGPPeerSpec)
+
+	if spec.Node != "" && spec.NodeSelector != "" {
+		structLevel.ReportError(reflect.ValueOf(spec.NodeSelector),
+			"NodeSelector", "", reason(peerNodeMsg))
+	}
+
+	// The peers are either specified explicitly by IP, or selected from the Calico nodes.
+	if spec.PeerSelector != "" {
+		if spec.PeerIP != "" || spec.ASNumber != 0 {
+			structLevel.ReportError(reflect.ValueOf(spec.PeerSelector),
+				"PeerSelector", "", reason(peerSelectorMsg))
+		}
+	} else if spec.PeerIP == "" {
+		structLevel.ReportError(reflect.ValueOf(spec.PeerIP),
+			"PeerIP", "", reason(peerIPMsg))
+	}
+}
+
+func validateBGPConfigurationSpecV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	spec := structLevel.CurrentStruct.Interface().(apiv2.BGPConfigurationSpec)
+
+	// The communities of a prefix advertisement are either community values, or the names
+	// of the communities.
+	names := map[string]bool{}
+	for _, c := range spec.Communities {
+		names[c.Name] = true
+	}
+	for _, pa := range spec.PrefixAdvertisements {
+		for _, c := range pa.Communities {
+			if !names[c] && !isValidBGPCommunity(c) {
+				structLevel.ReportError(reflect.ValueOf(c),
+					"PrefixAdvertisements.Communities", "", reason(prefixCommunityMsg))
+			}
+		}
+	}
+}
+
+func validateHTTPPathV2(v *validator.Validate, structLevel *validator.StructLevel) {
+	p := structLevel.CurrentStruct.Interface().(apiv2.HTTPPath)
+	validateHTTPPathExactOrPrefix(structLevel, p.Exact, p.Prefix)
+}
+
+func validateBackendHTTPPath(v *validator.Validate, structLevel *validator.StructLevel) {
+	p := structLevel.CurrentStruct.Interface().(model.HTTPPath)
+	validateHTTPPathExactOrPrefix(structLevel, p.Exact, p.Prefix)
+}
+
+// validateHTTPPathExactOrPrefix checks that exactly one of the exact or prefix
+// path fields is specified.
+func validateHTTPPathExactOrPrefix(structLevel *validator.StructLevel, exact, prefix string) {
+	if exact == "" && prefix == "" {
+		structLevel.ReportError(reflect.ValueOf(exact),
+			"Exact/Prefix", "", reason("one of exact or prefix must be specified"))
+	} else if exact != "" && prefix != "" {
+		structLevel.ReportError(reflect.ValueOf(exact),
+			"Exact/Prefix", "", reason("only one of exact and prefix may be specified"))
+	}
+}
+
+// isProtocolTCP returns true if the numerical or string version of the protocol indicates TCP.
+func isProtocolTCP(p numorstring.Protocol) bool {
+	if num, err := p.NumValue(); err == nil {
+		return num == 6
+	}
+	return strings.ToLower(p.StrVal) == "tcp"
 }
 
 func validateNodeSpec(v *validator.Validate, structLevel *validator.StructLevel) {
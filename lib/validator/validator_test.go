@@ -20,6 +20,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	apiv2 "github.com/projectcalico/libcalico-go/lib/apis/v2"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
@@ -1118,6 +1119,337 @@ func init() {
 				ApplyOnForward: true,
 				Types:          []api.PolicyType{api.PolicyTypeIngress, api.PolicyTypeEgress},
 			}, false),
+
+		// (API v2) HTTP match criteria.
+		Entry("should accept HTTP methods and paths",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP: &apiv2.HTTPMatch{
+					Methods: []string{"GET", "PUT"},
+					Paths:   []apiv2.HTTPPath{{Exact: "/foo"}, {Prefix: "/bar/"}},
+				},
+			}, true),
+		Entry("should accept HTTP match with TCP protocol",
+			apiv2.Rule{
+				Action:   "allow",
+				Protocol: &protoTCP,
+				HTTP:     &apiv2.HTTPMatch{Methods: []string{"GET"}},
+			}, true),
+		Entry("should accept HTTP match with numeric TCP protocol",
+			apiv2.Rule{
+				Action:   "allow",
+				Protocol: protocolFromInt(6),
+				HTTP:     &apiv2.HTTPMatch{Methods: []string{"GET"}},
+			}, true),
+		Entry("should reject HTTP match with UDP protocol",
+			apiv2.Rule{
+				Action:   "allow",
+				Protocol: &protoUDP,
+				HTTP:     &apiv2.HTTPMatch{Methods: []string{"GET"}},
+			}, false),
+		Entry("should reject an invalid HTTP method",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP:   &apiv2.HTTPMatch{Methods: []string{"GET PUT"}},
+			}, false),
+		Entry("should reject an HTTP path with both exact and prefix",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP:   &apiv2.HTTPMatch{Paths: []apiv2.HTTPPath{{Exact: "/foo", Prefix: "/foo"}}},
+			}, false),
+		Entry("should reject an HTTP path with neither exact nor prefix",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP:   &apiv2.HTTPMatch{Paths: []apiv2.HTTPPath{{}}},
+			}, false),
+		Entry("should reject a relative HTTP path",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP:   &apiv2.HTTPMatch{Paths: []apiv2.HTTPPath{{Exact: "foo"}}},
+			}, false),
+		Entry("should reject an HTTP path with a query",
+			apiv2.Rule{
+				Action: "allow",
+				HTTP:   &apiv2.HTTPMatch{Paths: []apiv2.HTTPPath{{Prefix: "/foo?bar=baz"}}},
+			}, false),
+		Entry("should accept HTTP match (m)",
+			model.Rule{
+				HTTPMatch: &model.HTTPMatch{
+					Methods: []string{"GET"},
+					Paths:   []model.HTTPPath{{Exact: "/foo"}, {Prefix: "/bar"}},
+				},
+			}, true),
+		Entry("should reject HTTP match with UDP protocol (m)",
+			model.Rule{
+				Protocol:  &protoUDP,
+				HTTPMatch: &model.HTTPMatch{Methods: []string{"GET"}},
+			}, false),
+		Entry("should reject an HTTP path with both exact and prefix (m)",
+			model.Rule{
+				HTTPMatch: &model.HTTPMatch{Paths: []model.HTTPPath{{Exact: "/foo", Prefix: "/foo"}}},
+			}, false),
+
+		// (API v2) Log options.
+		Entry("should accept log options with the log action",
+			apiv2.Rule{
+				Action: "log",
+				LogOptions: &apiv2.LogOptions{
+					Prefix:    "calico-drop: ",
+					RateLimit: &apiv2.LogRateLimit{Rate: 10, Unit: apiv2.LogRateLimitUnitMinute},
+					Burst:     uint32Ptr(5),
+				},
+			}, true),
+		Entry("should reject log options with the allow action",
+			apiv2.Rule{
+				Action:     "allow",
+				LogOptions: &apiv2.LogOptions{Prefix: "foo"},
+			}, false),
+		Entry("should reject a log prefix that is too long",
+			apiv2.Rule{
+				Action:     "log",
+				LogOptions: &apiv2.LogOptions{Prefix: "012345678901234567890123456789"},
+			}, false),
+		Entry("should reject a log prefix with quotes",
+			apiv2.Rule{
+				Action:     "log",
+				LogOptions: &apiv2.LogOptions{Prefix: "foo\"bar"},
+			}, false),
+		Entry("should reject a zero log rate",
+			apiv2.Rule{
+				Action: "log",
+				LogOptions: &apiv2.LogOptions{
+					RateLimit: &apiv2.LogRateLimit{Rate: 0, Unit: apiv2.LogRateLimitUnitSecond},
+				},
+			}, false),
+		Entry("should reject an invalid log rate unit",
+			apiv2.Rule{
+				Action: "log",
+				LogOptions: &apiv2.LogOptions{
+					RateLimit: &apiv2.LogRateLimit{Rate: 10, Unit: "Hour"},
+				},
+			}, false),
+		Entry("should reject a log burst without a rate limit",
+			apiv2.Rule{
+				Action:     "log",
+				LogOptions: &apiv2.LogOptions{Burst: uint32Ptr(5)},
+			}, false),
+		Entry("should accept a log rate limit (m)",
+			model.Rule{
+				Action:       "log",
+				LogPrefix:    "foo",
+				LogRateLimit: &model.LogRateLimit{Rate: 10, Unit: "second", Burst: 5},
+			}, true),
+		Entry("should reject a log rate limit with the deny action (m)",
+			model.Rule{
+				Action:       "deny",
+				LogRateLimit: &model.LogRateLimit{Rate: 10, Unit: "second"},
+			}, false),
+		Entry("should reject an invalid log rate unit (m)",
+			model.Rule{
+				Action:       "log",
+				LogRateLimit: &model.LogRateLimit{Rate: 10, Unit: "Second"},
+			}, false),
+
+		// (API v2) Service account match criteria.
+		Entry("should accept service account names and selector",
+			apiv2.Rule{
+				Action: "allow",
+				Source: apiv2.EntityRule{
+					ServiceAccounts: &apiv2.ServiceAccountMatch{
+						Names:    []string{"sa1", "sa-2"},
+						Selector: "role == 'db'",
+					},
+				},
+			}, true),
+		Entry("should reject an invalid service account name",
+			apiv2.Rule{
+				Action: "allow",
+				Source: apiv2.EntityRule{
+					ServiceAccounts: &apiv2.ServiceAccountMatch{Names: []string{"sa 1"}},
+				},
+			}, false),
+		Entry("should reject an invalid service account selector",
+			apiv2.Rule{
+				Action: "allow",
+				Destination: apiv2.EntityRule{
+					ServiceAccounts: &apiv2.ServiceAccountMatch{Selector: "role == "},
+				},
+			}, false),
+
+		// (API) IPPoolSpec
+		Entry("should accept an IP pool with a node selector",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", NodeSelector: "rack == 'rack1'"}, true),
+		Entry("should reject an IP pool with an invalid node selector",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", NodeSelector: "rack == "}, false),
+		Entry("should accept an IP pool with a sequential block allocation strategy",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", AllocationStrategy: apiv2.AllocationStrategySequentialBlock}, true),
+		Entry("should accept an IP pool with an LRU address allocation strategy",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", AllocationStrategy: apiv2.AllocationStrategyLRUAddress}, true),
+		Entry("should reject an IP pool with an invalid allocation strategy",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", AllocationStrategy: "random-block"}, false),
+		Entry("should accept an IP pool with VXLAN enabled",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", VXLANMode: apiv2.VXLANModeAlways}, true),
+		Entry("should accept an IP pool with VXLAN cross subnet",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", VXLANMode: apiv2.VXLANModeCrossSubnet}, true),
+		Entry("should reject an IP pool with an invalid VXLAN mode",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", VXLANMode: "always"}, false),
+		Entry("should reject an IP pool with both IPIP and VXLAN enabled",
+			apiv2.IPPoolSpec{CIDR: "10.0.0.0/24", VXLANMode: apiv2.VXLANModeAlways, IPIPMode: apiv2.IPIPModeCrossSubnet}, false),
+		Entry("should accept a node with a VXLAN tunnel address",
+			apiv2.NodeSpec{IPv4VXLANTunnelAddr: "10.0.0.1"}, true),
+		Entry("should reject a node with an invalid VXLAN tunnel address",
+			apiv2.NodeSpec{IPv4VXLANTunnelAddr: "aa::1"}, false),
+
+		// (API) BGPConfigurationSpec
+		Entry("should accept BGP configuration with service cluster and external IPs",
+			apiv2.BGPConfigurationSpec{
+				ServiceClusterIPs:  []apiv2.ServiceClusterIPBlock{{CIDR: "10.96.0.0/12"}, {CIDR: "fd00:96::/108"}},
+				ServiceExternalIPs: []apiv2.ServiceExternalIPBlock{{CIDR: "172.16.10.0/24"}},
+			}, true),
+		Entry("should reject BGP configuration with an invalid service cluster IP CIDR",
+			apiv2.BGPConfigurationSpec{
+				ServiceClusterIPs: []apiv2.ServiceClusterIPBlock{{CIDR: "10.96.0.0/33"}},
+			}, false),
+		Entry("should reject BGP configuration with an empty service external IP CIDR",
+			apiv2.BGPConfigurationSpec{
+				ServiceExternalIPs: []apiv2.ServiceExternalIPBlock{{}},
+			}, false),
+
+		Entry("should accept BGP configuration with communities and prefix advertisements",
+			apiv2.BGPConfigurationSpec{
+				Communities: []apiv2.Community{
+					{Name: "standard", Value: "65000:100"},
+					{Name: "large", Value: "4200000000:1:2"},
+				},
+				PrefixAdvertisements: []apiv2.PrefixAdvertisement{
+					{CIDR: "192.168.0.0/16", Communities: []string{"standard", "large", "65535:65535"}},
+				},
+			}, true),
+		Entry("should reject a standard community with an out of range value",
+			apiv2.BGPConfigurationSpec{
+				Communities: []apiv2.Community{{Name: "bad", Value: "65536:100"}},
+			}, false),
+		Entry("should reject a large community with an out of range value",
+			apiv2.BGPConfigurationSpec{
+				Communities: []apiv2.Community{{Name: "bad", Value: "1:2:4294967296"}},
+			}, false),
+		Entry("should reject a malformed community value",
+			apiv2.BGPConfigurationSpec{
+				Communities: []apiv2.Community{{Name: "bad", Value: "65000"}},
+			}, false),
+		Entry("should reject a prefix advertisement with an unknown community name",
+			apiv2.BGPConfigurationSpec{
+				PrefixAdvertisements: []apiv2.PrefixAdvertisement{
+					{CIDR: "192.168.0.0/16", Communities: []string{"unknown"}},
+				},
+			}, false),
+
+		// (API) BGPFilterSpec
+		Entry("should accept a BGP filter with valid rules",
+			apiv2.BGPFilterSpec{
+				Export: []apiv2.BGPFilterRule{{CIDR: "10.0.0.0/8", MatchOperator: "In", Action: "Accept"}},
+				Import: []apiv2.BGPFilterRule{{CIDR: "fd00::/64", MatchOperator: "NotEqual", Action: "Reject"}},
+			}, true),
+		Entry("should reject a BGP filter rule with an invalid match operator",
+			apiv2.BGPFilterSpec{
+				Export: []apiv2.BGPFilterRule{{CIDR: "10.0.0.0/8", MatchOperator: "Contains", Action: "Accept"}},
+			}, false),
+		Entry("should reject a BGP filter rule with an invalid action",
+			apiv2.BGPFilterSpec{
+				Import: []apiv2.BGPFilterRule{{CIDR: "10.0.0.0/8", MatchOperator: "In", Action: "Allow"}},
+			}, false),
+		Entry("should reject a BGP filter rule with an invalid CIDR",
+			apiv2.BGPFilterSpec{
+				Import: []apiv2.BGPFilterRule{{CIDR: "10.0.0.0/33", MatchOperator: "In", Action: "Accept"}},
+			}, false),
+
+		// (API) StagedGlobalNetworkPolicySpec and StagedNetworkPolicySpec
+		Entry("should accept a staged global network policy with no staged action",
+			apiv2.StagedGlobalNetworkPolicySpec{Selector: "has(foo)"}, true),
+		Entry("should accept a staged global network policy with a Delete staged action",
+			apiv2.StagedGlobalNetworkPolicySpec{StagedAction: apiv2.StagedActionDelete}, true),
+		Entry("should reject a staged global network policy with an invalid staged action",
+			apiv2.StagedGlobalNetworkPolicySpec{StagedAction: "Enforce"}, false),
+		Entry("should reject a staged global network policy with an invalid selector",
+			apiv2.StagedGlobalNetworkPolicySpec{Selector: "has(foo"}, false),
+		Entry("should accept a staged network policy with a Set staged action",
+			apiv2.StagedNetworkPolicySpec{StagedAction: apiv2.StagedActionSet, Tier: "tier1"}, true),
+		Entry("should reject a staged network policy with an invalid staged action",
+			apiv2.StagedNetworkPolicySpec{StagedAction: "set"}, false),
+
+		// (API) NodeStatusSpec
+		Entry("should accept a node status with BGP peer states",
+			apiv2.NodeStatusSpec{
+				DatastoreSyncStatus: apiv2.DatastoreSyncStatusInSync,
+				BGPPeers: []apiv2.BGPPeerStatus{
+					{PeerIP: "1.2.3.4", State: apiv2.BGPSessionStateEstablished},
+					{PeerIP: "aa::ff", State: apiv2.BGPSessionStateIdle},
+				},
+			}, true),
+		Entry("should accept a node status with no datastore sync status",
+			apiv2.NodeStatusSpec{}, true),
+		Entry("should reject a node status with an invalid datastore sync status",
+			apiv2.NodeStatusSpec{DatastoreSyncStatus: "Synced"}, false),
+		Entry("should reject a node status with an invalid BGP session state",
+			apiv2.NodeStatusSpec{
+				BGPPeers: []apiv2.BGPPeerStatus{{PeerIP: "1.2.3.4", State: "Up"}},
+			}, false),
+		Entry("should reject a node status with an invalid BGP peer IP",
+			apiv2.NodeStatusSpec{
+				BGPPeers: []apiv2.BGPPeerStatus{{PeerIP: "1.2.3", State: apiv2.BGPSessionStateActive}},
+			}, false),
+
+		// (API) IPReservationSpec
+		Entry("should accept an IP reservation with CIDRs and IPs",
+			apiv2.IPReservationSpec{ReservedCIDRs: []string{"10.0.0.0/24", "10.1.0.1", "fd00::/120", "fd00:1::1"}}, true),
+		Entry("should accept an IP reservation with no CIDRs",
+			apiv2.IPReservationSpec{}, true),
+		Entry("should reject an IP reservation with an invalid CIDR",
+			apiv2.IPReservationSpec{ReservedCIDRs: []string{"10.0.0.0/33"}}, false),
+		Entry("should reject an IP reservation with an invalid IP",
+			apiv2.IPReservationSpec{ReservedCIDRs: []string{"10.0.0"}}, false),
+
+		// (API) BGPPeerSpec
+		Entry("should accept a BGP peer with filters",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Filters: []string{"filter-1", "filter-2"}}, true),
+		Entry("should reject a BGP peer with an invalid filter name",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Filters: []string{"bad/name"}}, false),
+		Entry("should accept a BGP peer with a node and peer IP",
+			apiv2.BGPPeerSpec{Node: "node1", PeerIP: "1.2.3.4", ASNumber: 64512}, true),
+		Entry("should accept a BGP peer with a node selector and peer IP",
+			apiv2.BGPPeerSpec{NodeSelector: "rack == 'rack1'", PeerIP: "1.2.3.4", ASNumber: 64512}, true),
+		Entry("should accept a BGP peer with a node selector and peer selector",
+			apiv2.BGPPeerSpec{NodeSelector: "!has(rr)", PeerSelector: "has(rr)"}, true),
+		Entry("should reject a BGP peer with a node and node selector",
+			apiv2.BGPPeerSpec{Node: "node1", NodeSelector: "all()", PeerIP: "1.2.3.4"}, false),
+		Entry("should reject a BGP peer with a peer selector and peer IP",
+			apiv2.BGPPeerSpec{PeerSelector: "has(rr)", PeerIP: "1.2.3.4"}, false),
+		Entry("should reject a BGP peer with a peer selector and AS number",
+			apiv2.BGPPeerSpec{PeerSelector: "has(rr)", ASNumber: 64512}, false),
+		Entry("should reject a BGP peer with no peer IP or peer selector",
+			apiv2.BGPPeerSpec{Node: "node1"}, false),
+		Entry("should reject a BGP peer with an invalid peer selector",
+			apiv2.BGPPeerSpec{PeerSelector: "has(rr"}, false),
+		Entry("should accept a BGP peer with a password secret key reference",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Password: &apiv2.BGPPassword{
+				SecretKeyRef: &apiv2.SecretKeySelector{Name: "bgp-secrets", Key: "peer1"},
+			}}, true),
+		Entry("should reject a BGP peer with a password but no secret key reference",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Password: &apiv2.BGPPassword{}}, false),
+		Entry("should reject a BGP peer with a password secret key reference with no key",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Password: &apiv2.BGPPassword{
+				SecretKeyRef: &apiv2.SecretKeySelector{Name: "bgp-secrets"},
+			}}, false),
+		Entry("should reject a BGP peer with a password secret key reference with an invalid name",
+			apiv2.BGPPeerSpec{PeerIP: "1.2.3.4", ASNumber: 64512, Password: &apiv2.BGPPassword{
+				SecretKeyRef: &apiv2.SecretKeySelector{Name: "bad/name", Key: "peer1"},
+			}}, false),
+
+		// (API) NodeBGPSpec
+		Entry("should accept a node with a route reflector cluster ID",
+			apiv2.NodeBGPSpec{IPv4Address: "1.2.3.4", RouteReflectorClusterID: "255.0.0.1"}, true),
+		Entry("should reject a node with an invalid route reflector cluster ID",
+			apiv2.NodeBGPSpec{IPv4Address: "1.2.3.4", RouteReflectorClusterID: "abcd"}, false),
 	)
 }
 
@@ -1130,3 +1462,7 @@ func protocolFromInt(i uint8) *numorstring.Protocol {
 	p := numorstring.ProtocolFromInt(i)
 	return &p
 }
+
+func uint32Ptr(i uint32) *uint32 {
+	return &i
+}